@@ -0,0 +1,20 @@
+package factory
+
+import (
+	"fmt"
+	"strings"
+
+	"skeleton/app/models"
+)
+
+// Register the definitions of the application models here.
+// Each definition returns valid defaults; unique columns use the sequence number n.
+func init() {
+	Define(func(f *Faker, n int) models.User {
+		first, last := f.FirstName(), f.LastName()
+		return models.User{
+			Name:  first + " " + last,
+			Email: strings.ToLower(fmt.Sprintf("%s.%s.%d@example.com", first, last, n)),
+		}
+	})
+}
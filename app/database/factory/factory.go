@@ -0,0 +1,179 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoDB is returned by Create when the context carries no database.
+var ErrNoDB = errors.New("factory: no database in context, use factory.WithDB")
+
+// DefaultSeed seeds the fake data of every factory unless Seed is called.
+const DefaultSeed uint64 = 1
+
+// Definition builds the default attributes of the n-th model of its type (n starts at 1).
+type Definition[T any] func(f *Faker, n int) T
+
+// definition is a registered Definition with the sequence of its model type.
+type definition struct {
+	build    any
+	sequence int
+}
+
+var (
+	mu          sync.Mutex
+	definitions = map[reflect.Type]*definition{}
+)
+
+// Define registers the definition used by New for models of type T.
+func Define[T any](build Definition[T]) {
+	mu.Lock()
+	defer mu.Unlock()
+	definitions[reflect.TypeFor[T]()] = &definition{build: build}
+}
+
+// ResetSequences restarts the sequence of every model type at 1.
+// Tests call it to get the same data whatever ran before them.
+func ResetSequences() {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, d := range definitions {
+		d.sequence = 0
+	}
+}
+
+// Factory builds and persists models of type T from their definition.
+// Its methods return a modified copy, so a configured factory can be reused.
+type Factory[T any] struct {
+	typ    reflect.Type
+	build  Definition[T]
+	count  int
+	seed   uint64
+	states []func(model *T, i int)
+}
+
+// New returns a factory for T. It panics when no definition is registered for T.
+func New[T any]() *Factory[T] {
+	typ := reflect.TypeFor[T]()
+
+	mu.Lock()
+	d, ok := definitions[typ]
+	mu.Unlock()
+	if !ok {
+		panic(fmt.Sprintf("factory: no definition registered for %s", typ))
+	}
+
+	return &Factory[T]{typ: typ, build: d.build.(Definition[T]), count: 1, seed: DefaultSeed}
+}
+
+// Count sets how many models Make and Create build.
+func (f *Factory[T]) Count(n int) *Factory[T] {
+	clone := f.clone()
+	clone.count = n
+	return clone
+}
+
+// Seed changes the seed of the fake data.
+func (f *Factory[T]) Seed(seed uint64) *Factory[T] {
+	clone := f.clone()
+	clone.seed = seed
+	return clone
+}
+
+// State overrides attributes of every model after its definition.
+func (f *Factory[T]) State(state func(model *T)) *Factory[T] {
+	return f.with(func(model *T, i int) { state(model) })
+}
+
+// Sequence applies the states in turn: the first to the first model, the second to the
+// second, and so on, starting over when they run out.
+func (f *Factory[T]) Sequence(states ...func(model *T)) *Factory[T] {
+	if len(states) == 0 {
+		return f
+	}
+	return f.with(func(model *T, i int) { states[i%len(states)](model) })
+}
+
+// Make builds the models without saving them.
+func (f *Factory[T]) Make() []*T {
+	models := make([]*T, 0, f.count)
+	for i := 0; i < f.count; i++ {
+		n := f.next()
+		model := f.build(NewFaker(f.seed, uint64(n)), n)
+		for _, state := range f.states {
+			state(&model, i)
+		}
+		models = append(models, &model)
+	}
+	return models
+}
+
+// MakeOne builds a single model without saving it.
+func (f *Factory[T]) MakeOne() *T {
+	return f.Count(1).Make()[0]
+}
+
+// Create builds the models and inserts them with the database of ctx (see WithDB).
+func (f *Factory[T]) Create(ctx context.Context) ([]*T, error) {
+	db := DB(ctx)
+	if db == nil {
+		return nil, ErrNoDB
+	}
+
+	models := f.Make()
+	if len(models) == 0 {
+		return models, nil
+	}
+	if err := db.WithContext(ctx).Create(&models).Error; err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// CreateOne builds and inserts a single model.
+func (f *Factory[T]) CreateOne(ctx context.Context) (*T, error) {
+	models, err := f.Count(1).Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return models[0], nil
+}
+
+func (f *Factory[T]) with(state func(model *T, i int)) *Factory[T] {
+	clone := f.clone()
+	clone.states = append(clone.states, state)
+	return clone
+}
+
+func (f *Factory[T]) clone() *Factory[T] {
+	clone := *f
+	clone.states = append([]func(model *T, i int){}, f.states...)
+	return &clone
+}
+
+// next returns the next sequence number of the model type.
+func (f *Factory[T]) next() int {
+	mu.Lock()
+	defer mu.Unlock()
+	d := definitions[f.typ]
+	d.sequence++
+	return d.sequence
+}
+
+type dbKey struct{}
+
+// WithDB returns a copy of ctx whose factories create models in db.
+func WithDB(ctx context.Context, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, dbKey{}, db)
+}
+
+// DB returns the database set with WithDB, or nil.
+func DB(ctx context.Context) *gorm.DB {
+	db, _ := ctx.Value(dbKey{}).(*gorm.DB)
+	return db
+}
@@ -0,0 +1,108 @@
+package factory
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Amira", "Ben", "Budi", "Carla", "Chen", "Dewi", "Diego", "Elena",
+		"Farah", "Grace", "Hana", "Ivan", "Jonas", "Kemal", "Lina", "Mateo", "Nadia", "Omar",
+		"Priya", "Rafael", "Sari", "Tomas", "Yuki", "Zara",
+	}
+	lastNames = []string{
+		"Anderson", "Brown", "Costa", "Dubois", "Evans", "Fischer", "Garcia", "Hakim", "Ito", "Johnson",
+		"Kowalski", "Lestari", "Martin", "Nguyen", "Okafor", "Petrov", "Quinn", "Rossi", "Santoso", "Tanaka",
+		"Ueda", "Virtanen", "Wijaya", "Yilmaz", "Zhang",
+	}
+	words = []string{
+		"alpha", "amber", "anchor", "atlas", "bright", "cedar", "cloud", "coral", "delta", "ember",
+		"falcon", "forest", "harbor", "island", "jade", "lumen", "maple", "meadow", "nova", "orbit",
+		"pixel", "quartz", "river", "solar", "summit", "tide", "violet", "willow", "zenith",
+	}
+)
+
+// Faker generates fake data. The same seed and stream always produce the same values.
+type Faker struct {
+	rand *rand.Rand
+}
+
+// NewFaker creates a faker; factories use one stream per sequence number.
+func NewFaker(seed, stream uint64) *Faker {
+	return &Faker{rand: rand.New(rand.NewPCG(seed, stream))}
+}
+
+// Int returns an int in [min, max].
+func (f *Faker) Int(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + f.rand.IntN(max-min+1)
+}
+
+// Float returns a float64 in [min, max).
+func (f *Faker) Float(min, max float64) float64 {
+	return min + f.rand.Float64()*(max-min)
+}
+
+// Bool returns true or false.
+func (f *Faker) Bool() bool {
+	return f.rand.IntN(2) == 1
+}
+
+// FirstName returns a first name.
+func (f *Faker) FirstName() string {
+	return Pick(f, firstNames...)
+}
+
+// LastName returns a last name.
+func (f *Faker) LastName() string {
+	return Pick(f, lastNames...)
+}
+
+// Name returns a full name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Username returns a lowercase username such as "ada.lestari".
+func (f *Faker) Username() string {
+	return strings.ToLower(f.FirstName() + "." + f.LastName())
+}
+
+// Email returns an address on example.com; add the sequence number when it must be unique.
+func (f *Faker) Email() string {
+	return f.Username() + "@example.com"
+}
+
+// Word returns a word.
+func (f *Faker) Word() string {
+	return Pick(f, words...)
+}
+
+// Words returns n words separated by spaces.
+func (f *Faker) Words(n int) string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = f.Word()
+	}
+	return strings.Join(out, " ")
+}
+
+// Sentence returns a capitalized sentence of 4 to 10 words.
+func (f *Faker) Sentence() string {
+	sentence := f.Words(f.Int(4, 10))
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+// Slug returns a slug such as "amber-harbor-42".
+func (f *Faker) Slug() string {
+	return fmt.Sprintf("%s-%s-%d", f.Word(), f.Word(), f.Int(1, 99))
+}
+
+// Pick returns one of the items.
+func Pick[T any](f *Faker, items ...T) T {
+	return items[f.rand.IntN(len(items))]
+}
@@ -0,0 +1,16 @@
+package seeders
+
+// LoadAll returns the registry of all seeders
+// Add new seeders here; they run in the order they are registered
+func LoadAll() *Registry {
+	registry := NewRegistry()
+
+	// To add a new seeder:
+	// 1. Create a type implementing Seeder in this package
+	// 2. Register it here, after the seeders whose data it needs
+	registry.Register(
+		UserSeeder{},
+	)
+
+	return registry
+}
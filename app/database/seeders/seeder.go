@@ -0,0 +1,69 @@
+package seeders
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"skeleton/app/database/factory"
+
+	"gorm.io/gorm"
+)
+
+// Seeder populates the database with data for development and tests.
+type Seeder interface {
+	// Name identifies the seeder for migrate seed -class
+	Name() string
+	// Run inserts the data; factories created from ctx use db
+	Run(ctx context.Context, db *gorm.DB) error
+}
+
+// Registry holds the seeders in the order they run.
+type Registry struct {
+	seeders []Seeder
+}
+
+// NewRegistry creates a new seeder registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds seeders, to run after the ones already registered.
+func (r *Registry) Register(seeders ...Seeder) {
+	r.seeders = append(r.seeders, seeders...)
+}
+
+// Seeders returns the named seeders in registration order, or all of them when no name is given.
+func (r *Registry) Seeders(names ...string) ([]Seeder, error) {
+	if len(names) == 0 {
+		return r.seeders, nil
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var selected []Seeder
+	for _, seeder := range r.seeders {
+		if wanted[seeder.Name()] {
+			selected = append(selected, seeder)
+			delete(wanted, seeder.Name())
+		}
+	}
+	if len(wanted) > 0 {
+		unknown := make([]string, 0, len(wanted))
+		for name := range wanted {
+			unknown = append(unknown, name)
+		}
+		return nil, fmt.Errorf("unknown seeder: %s", strings.Join(unknown, ", "))
+	}
+	return selected, nil
+}
+
+// Run runs a seeder in a transaction, so a failing seeder leaves no partial data.
+func Run(ctx context.Context, db *gorm.DB, seeder Seeder) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return seeder.Run(factory.WithDB(ctx, tx), tx)
+	})
+}
@@ -0,0 +1,120 @@
+package seeders
+
+import (
+	"context"
+	"io/fs"
+	"sort"
+	"testing"
+
+	"skeleton/app/database/factory"
+	"skeleton/app/models"
+	"skeleton/database/migrations"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// migratedDB opens an in-memory SQLite database with the SQLite migrations applied.
+func migratedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to :memory: is a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	files, err := fs.Glob(migrations.FS, "sqlite/*.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		sql, err := fs.ReadFile(migrations.FS, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Exec(string(sql)).Error; err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+	}
+	return db
+}
+
+func TestSeeders(t *testing.T) {
+	tests := []struct {
+		name      string
+		classes   []string
+		wantUsers int64
+		wantErr   bool
+	}{
+		{name: "all seeders", wantUsers: 51},
+		{name: "one seeder", classes: []string{"UserSeeder"}, wantUsers: 51},
+		{name: "unknown seeder", classes: []string{"PostSeeder"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory.ResetSequences()
+			db := migratedDB(t)
+			ctx := context.Background()
+
+			seeders, err := LoadAll().Seeders(tt.classes...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error for an unknown seeder")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Running twice must not duplicate the data
+			for i := 0; i < 2; i++ {
+				for _, seeder := range seeders {
+					if err := Run(ctx, db, seeder); err != nil {
+						t.Fatalf("%s: %v", seeder.Name(), err)
+					}
+				}
+			}
+
+			var count int64
+			if err := db.Model(&models.User{}).Count(&count).Error; err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.wantUsers {
+				t.Errorf("got %d users, want %d", count, tt.wantUsers)
+			}
+			var admin models.User
+			if err := db.Where("email = ?", "admin@example.com").First(&admin).Error; err != nil {
+				t.Errorf("admin user: %v", err)
+			}
+		})
+	}
+}
+
+func TestUserFactoryCreate(t *testing.T) {
+	factory.ResetSequences()
+	db := migratedDB(t)
+	ctx := factory.WithDB(context.Background(), db)
+
+	users, err := factory.New[models.User]().Count(3).Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range users {
+		if user.ID == 0 || user.Name == "" || user.Email == "" {
+			t.Errorf("incomplete user: %+v", user)
+		}
+	}
+
+	if _, err := factory.New[models.User]().Create(context.Background()); err != factory.ErrNoDB {
+		t.Errorf("got %v without a database, want ErrNoDB", err)
+	}
+}
@@ -0,0 +1,40 @@
+package seeders
+
+import (
+	"context"
+
+	"skeleton/app/database/factory"
+	"skeleton/app/models"
+
+	"gorm.io/gorm"
+)
+
+// UserSeeder creates an admin user and 50 demo users.
+type UserSeeder struct{}
+
+// Name returns the seeder name.
+func (UserSeeder) Name() string {
+	return "UserSeeder"
+}
+
+// Run creates the users, unless the users table already has rows.
+func (UserSeeder) Run(ctx context.Context, db *gorm.DB) error {
+	var count int64
+	if err := db.WithContext(ctx).Model(&models.User{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	admin := factory.New[models.User]().State(func(user *models.User) {
+		user.Name = "Admin"
+		user.Email = "admin@example.com"
+	})
+	if _, err := admin.CreateOne(ctx); err != nil {
+		return err
+	}
+
+	_, err := factory.New[models.User]().Count(50).Create(ctx)
+	return err
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+
+	"skeleton/app/support/httpcache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CacheController handles response cache administration.
+type CacheController struct {
+	store *httpcache.Store
+}
+
+// NewCacheController creates a new cache controller.
+func NewCacheController(store *httpcache.Store) *CacheController {
+	return &CacheController{store: store}
+}
+
+// Purge handles DELETE /api/v1/internal/cache/responses
+// It removes the cached responses of the given route patterns and tags,
+// e.g. ?route=/api/v1/status&tag=users
+func (c *CacheController) Purge(ctx *gin.Context) {
+	routes := ctx.QueryArray("route")
+	tags := ctx.QueryArray("tag")
+	if len(routes) == 0 && len(tags) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "At least one route or tag is required"})
+		return
+	}
+
+	for _, route := range routes {
+		if err := c.store.PurgeRoute(ctx.Request.Context(), route); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if len(tags) > 0 {
+		if err := c.store.PurgeTags(ctx.Request.Context(), tags...); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"purged": gin.H{"routes": routes, "tags": tags}})
+}
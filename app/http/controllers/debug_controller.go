@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+
+	"skeleton/app/support/container"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"github.com/gin-gonic/gin"
+)
+
+// DebugController exposes runtime diagnostics for the /debug route group.
+type DebugController struct {
+	app     foundation.Application
+	started time.Time
+}
+
+// NewDebugController creates a new debug controller.
+func NewDebugController(app foundation.Application) *DebugController {
+	return &DebugController{app: app, started: time.Now()}
+}
+
+// Runtime handles GET /debug/runtime
+func (c *DebugController) Runtime(ctx *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var lastGC *time.Time
+	if mem.LastGC > 0 {
+		t := time.Unix(0, int64(mem.LastGC))
+		lastGC = &t
+	}
+
+	hostname, _ := os.Hostname()
+	ctx.JSON(http.StatusOK, gin.H{
+		"hostname":   hostname,
+		"pid":        os.Getpid(),
+		"uptime":     time.Since(c.started).Round(time.Second).String(),
+		"go_version": runtime.Version(),
+		"goroutines": runtime.NumGoroutine(),
+		"num_cpu":    runtime.NumCPU(),
+		"gomaxprocs": runtime.GOMAXPROCS(0),
+		"memory": gin.H{
+			"heap_alloc":    mem.HeapAlloc,
+			"heap_inuse":    mem.HeapInuse,
+			"heap_idle":     mem.HeapIdle,
+			"heap_released": mem.HeapReleased,
+			"heap_objects":  mem.HeapObjects,
+			"stack_inuse":   mem.StackInuse,
+			"sys":           mem.Sys,
+			"total_alloc":   mem.TotalAlloc,
+			"mallocs":       mem.Mallocs,
+			"frees":         mem.Frees,
+		},
+		"gc": gin.H{
+			"num_gc":          mem.NumGC,
+			"num_forced_gc":   mem.NumForcedGC,
+			"pause_total":     time.Duration(mem.PauseTotalNs).String(),
+			"last_pause":      time.Duration(mem.PauseNs[(mem.NumGC+255)%256]).String(),
+			"last_gc":         lastGC,
+			"next_gc":         mem.NextGC,
+			"gc_cpu_fraction": mem.GCCPUFraction,
+		},
+	})
+}
+
+// Goroutines handles GET /debug/goroutines
+// It writes a full stack dump of all goroutines as plain text.
+func (c *DebugController) Goroutines(ctx *gin.Context) {
+	ctx.Header("Content-Type", "text/plain; charset=utf-8")
+	ctx.Status(http.StatusOK)
+	if err := pprof.Lookup("goroutine").WriteTo(ctx.Writer, 2); err != nil {
+		_ = ctx.Error(err)
+	}
+}
+
+// GC handles POST /debug/gc
+// It forces a garbage collection and returns memory to the OS.
+func (c *DebugController) GC(ctx *gin.Context) {
+	start := time.Now()
+	debug.FreeOSMemory()
+	ctx.JSON(http.StatusOK, gin.H{"duration": time.Since(start).String()})
+}
+
+// Build handles GET /debug/build
+func (c *DebugController) Build(ctx *gin.Context) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Build info not available"})
+		return
+	}
+
+	settings := make(map[string]string, len(info.Settings))
+	for _, setting := range info.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	deps := make([]string, 0, len(info.Deps))
+	for _, dep := range info.Deps {
+		deps = append(deps, dep.Path+" "+dep.Version)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"go_version": info.GoVersion,
+		"path":       info.Path,
+		"main":       info.Main.Path + " " + info.Main.Version,
+		"settings":   settings,
+		"deps":       deps,
+	})
+}
+
+// Bindings handles GET /debug/bindings
+// It lists the container bindings as they were registered, with the type of those already
+// resolved. Nothing is resolved, so listing does not build lazy singletons.
+func (c *DebugController) Bindings(ctx *gin.Context) {
+	bindings := container.Resolve(c.app)
+	if bindings == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Bindings are not recorded"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"bindings": bindings.Bindings()})
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"skeleton/app/services"
+	"skeleton/app/support/httpcache"
 
 	"github.com/donnigundala/dg-core/contracts/foundation"
 	"github.com/donnigundala/dg-core/validation"
@@ -9,7 +10,8 @@ import (
 
 // Controllers holds all application controllers.
 type Controllers struct {
-	User *UserController
+	User  *UserController
+	Cache *CacheController
 }
 
 // Initialize creates and wires all controllers with their dependencies.
@@ -30,6 +32,7 @@ func Initialize(app foundation.Application) *Controllers {
 
 	// Create and return all controllers
 	return &Controllers{
-		User: NewUserController(userService, validator),
+		User:  NewUserController(userService, validator),
+		Cache: NewCacheController(httpcache.MustResolve(app)),
 	}
 }
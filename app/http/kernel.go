@@ -1,8 +1,14 @@
 package http
 
 import (
+	"skeleton/app/http/middleware"
 	"skeleton/app/http/routes"
+	"skeleton/app/support/container"
+	appHealth "skeleton/app/support/health"
+	"skeleton/app/support/logging"
+	"skeleton/app/support/metrics"
 
+	"github.com/donnigundala/dg-core/config"
 	"github.com/donnigundala/dg-core/foundation"
 	coreHTTP "github.com/donnigundala/dg-core/http"
 	"github.com/donnigundala/dg-core/http/health"
@@ -12,8 +18,10 @@ import (
 
 // NewKernel configures the HTTP kernel (router, middleware, routes).
 func NewKernel(app *foundation.Application) *gin.Engine {
+	bindings := container.MustResolve(app)
+
 	// Register validator (needed by controllers)
-	app.Singleton("validator", func() (interface{}, error) {
+	bindings.Singleton("validator", func() (interface{}, error) {
 		return validation.NewValidator(), nil
 	})
 
@@ -21,32 +29,106 @@ func NewKernel(app *foundation.Application) *gin.Engine {
 	router := coreHTTP.NewRouter()
 
 	// Register router instance in container
-	app.Instance("router", router)
+	bindings.Instance("router", router)
 
 	// Setup health checks
-	setupHealthChecks(router)
+	setupHealthChecks(router, appHealth.MustResolve(app))
+
+	// Setup metrics endpoint
+	m := metrics.MustResolve(app)
+	setupMetrics(router, m)
 
 	// Apply global middleware
 	router.Use(globalMiddleware()...)
+	router.Use(middleware.Metrics(m))
 
 	// Register application routes
 	routes.Register(app, router)
 
+	// Setup diagnostics endpoints (opt-in)
+	setupDebug(app, router)
+
 	return router
 }
 
-func setupHealthChecks(router *gin.Engine) {
-	healthManager := health.NewManager()
-	healthManager.AddCheck(health.AlwaysHealthy("app"))
+// NewDebugRouter creates the router for the internal diagnostics listener
+// (server.debug.listen_addr). It returns nil when the routes are not guarded.
+func NewDebugRouter(app *foundation.Application, cfg routes.DebugConfig) *gin.Engine {
+	router := coreHTTP.NewRouter()
+	router.Use(coreHTTP.RecoveryWithDefault())
+
+	if !routes.RegisterDebug(app, router, cfg) {
+		logging.Component("http").Warn("Debug endpoints disabled: configure server.debug.allowed_ips or server.debug.scope")
+		return nil
+	}
+	return router
+}
 
+func setupHealthChecks(router *gin.Engine, registry *appHealth.Registry) {
+	// Checks are registered by the infrastructure providers when they boot
 	router.GET("/health/live", health.LivenessHandler())
-	router.GET("/health/ready", healthManager.ReadinessHandler())
-	router.GET("/health", healthManager.HealthHandler())
+	router.GET("/health/ready", registry.ReadinessHandler())
+	router.GET("/health", registry.HealthHandler())
+}
+
+func setupMetrics(router *gin.Engine, m *metrics.Metrics) {
+	// Served by a dedicated listener instead when listen_addr is set
+	if !m.Enabled() || m.Config().ListenAddr != "" {
+		return
+	}
+
+	// The public router only serves metrics unguarded outside production
+	if !m.Config().Guarded() && config.GetString("app.env") == "production" {
+		logging.Component("http").Warn("Metrics endpoint disabled in production: configure metrics.listen_addr, metrics.allowed_ips or metrics.token")
+		return
+	}
+
+	router.GET(m.Config().Path, metricsHandlers(m)...)
+}
+
+// NewMetricsRouter creates the router for the dedicated metrics listener
+// (metrics.listen_addr), applying the same guards as the main router.
+func NewMetricsRouter(m *metrics.Metrics) *gin.Engine {
+	router := coreHTTP.NewRouter()
+	router.Use(coreHTTP.RecoveryWithDefault())
+	router.GET(m.Config().Path, metricsHandlers(m)...)
+	return router
+}
+
+// metricsHandlers returns the configured guards followed by the exposition handler.
+func metricsHandlers(m *metrics.Metrics) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	if len(m.Config().AllowedIPs) > 0 {
+		handlers = append(handlers, middleware.IPAllowList(m.Config().AllowedIPs))
+	}
+	if m.Config().Token != "" {
+		handlers = append(handlers, middleware.BearerToken(m.Config().Token))
+	}
+	return append(handlers, gin.WrapH(m.Handler()))
+}
+
+func setupDebug(app *foundation.Application, router *gin.Engine) {
+	cfg, err := routes.LoadDebugConfig()
+	if err != nil {
+		logging.Component("http").Warn("Failed to load debug config, debug endpoints disabled", "error", err)
+		return
+	}
+
+	// Served by a dedicated listener instead when listen_addr is set
+	if !cfg.Enabled || cfg.ListenAddr != "" {
+		return
+	}
+
+	if !routes.RegisterDebug(app, router, cfg) {
+		logging.Component("http").Warn("Debug endpoints disabled: configure server.debug.allowed_ips or server.debug.scope")
+	}
 }
 
 func globalMiddleware() []gin.HandlerFunc {
 	return []gin.HandlerFunc{
 		coreHTTP.RequestIDWithDefault(),          // Request tracing (must be first)
+		middleware.Tracing(),                     // Distributed tracing (no-op when disabled)
+		middleware.ContextLogger(),               // Request-scoped logger for services and jobs
 		coreHTTP.LoggerWithDefault(),             // Logging with request ID
 		coreHTTP.RecoveryWithDefault(),           // Panic recovery
 		coreHTTP.CORSWithDefault(),               // CORS headers
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"skeleton/app/support/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetricsHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const allowed, other = "10.0.0.5:4000", "203.0.113.5:4000"
+
+	tests := []struct {
+		name       string
+		config     metrics.Config
+		remoteAddr string
+		token      string
+		wantStatus int
+	}{
+		{name: "open", remoteAddr: other, wantStatus: http.StatusOK},
+		{name: "allowed ip", config: metrics.Config{AllowedIPs: []string{"10.0.0.0/8"}}, remoteAddr: allowed, wantStatus: http.StatusOK},
+		{name: "other ip", config: metrics.Config{AllowedIPs: []string{"10.0.0.0/8"}}, remoteAddr: other, wantStatus: http.StatusForbidden},
+		{name: "token", config: metrics.Config{Token: "secret"}, remoteAddr: other, token: "secret", wantStatus: http.StatusOK},
+		{name: "no token", config: metrics.Config{Token: "secret"}, remoteAddr: other, wantStatus: http.StatusUnauthorized},
+		// With both guards a scraper needs the allowed ip and the token
+		{name: "allowed ip and token", config: metrics.Config{AllowedIPs: []string{"10.0.0.0/8"}, Token: "secret"}, remoteAddr: allowed, token: "secret", wantStatus: http.StatusOK},
+		{name: "allowed ip without token", config: metrics.Config{AllowedIPs: []string{"10.0.0.0/8"}, Token: "secret"}, remoteAddr: allowed, wantStatus: http.StatusUnauthorized},
+		{name: "token from another ip", config: metrics.Config{AllowedIPs: []string{"10.0.0.0/8"}, Token: "secret"}, remoteAddr: other, token: "secret", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Enabled = true
+			m := metrics.New(tt.config)
+			router := gin.New()
+			router.GET(m.Config().Path, metricsHandlers(m)...)
+
+			req := httptest.NewRequest(http.MethodGet, m.Config().Path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"skeleton/app/models"
+	"skeleton/app/services"
+	"skeleton/app/support/tenancy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAPIKeyService authenticates the keys of a map
+type fakeAPIKeyService struct {
+	services.APIKeyService
+	keys map[string]*models.APIKey
+}
+
+func (s *fakeAPIKeyService) Authenticate(ctx context.Context, plainKey string) (*models.APIKey, error) {
+	key, ok := s.keys[plainKey]
+	if !ok {
+		return nil, services.ErrInvalidAPIKey
+	}
+	return key, nil
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	acme, beta := uint(1), uint(2)
+	service := &fakeAPIKeyService{keys: map[string]*models.APIKey{
+		"sk_shared_secret": {ID: 1, Scopes: "users:read"},
+		"sk_admin_secret":  {ID: 2, Scopes: models.ScopeAll},
+		"sk_acme_secret":   {ID: 3, Scopes: "users:read", TenantID: &acme},
+	}}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		// tenant is the tenant the request was resolved to, if any
+		tenant        *uint
+		scopes        []string
+		nested        []string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{name: "no key", wantStatus: http.StatusUnauthorized},
+		{name: "X-API-Key header", headers: map[string]string{"X-API-Key": "sk_shared_secret"}, wantStatus: http.StatusOK, wantPrincipal: "key:1"},
+		{name: "bearer token", headers: map[string]string{"Authorization": "Bearer sk_shared_secret"}, wantStatus: http.StatusOK, wantPrincipal: "key:1"},
+		// Other bearer tokens are left to the other authentication middleware
+		{name: "bearer token of another kind", headers: map[string]string{"Authorization": "Bearer eyJ.a.b"}, wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", headers: map[string]string{"X-API-Key": "sk_unknown_secret"}, wantStatus: http.StatusUnauthorized},
+		{name: "granted scope", headers: map[string]string{"X-API-Key": "sk_shared_secret"}, scopes: []string{"users:read"}, wantStatus: http.StatusOK, wantPrincipal: "key:1"},
+		{name: "missing scope", headers: map[string]string{"X-API-Key": "sk_shared_secret"}, scopes: []string{"users:read", "users:write"}, wantStatus: http.StatusForbidden},
+		{name: "every scope", headers: map[string]string{"X-API-Key": "sk_admin_secret"}, scopes: []string{"users:write"}, wantStatus: http.StatusOK, wantPrincipal: "key:2"},
+		{name: "missing nested scope", headers: map[string]string{"X-API-Key": "sk_shared_secret"}, nested: []string{"users:write"}, wantStatus: http.StatusForbidden},
+		{name: "tenant key in its tenant", headers: map[string]string{"X-API-Key": "sk_acme_secret"}, tenant: &acme, wantStatus: http.StatusOK, wantPrincipal: "key:3"},
+		{name: "tenant key in another tenant", headers: map[string]string{"X-API-Key": "sk_acme_secret"}, tenant: &beta, wantStatus: http.StatusForbidden},
+		{name: "tenant key without a tenant", headers: map[string]string{"X-API-Key": "sk_acme_secret"}, wantStatus: http.StatusForbidden},
+		{name: "shared key in a tenant", headers: map[string]string{"X-API-Key": "sk_shared_secret"}, tenant: &acme, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var principal string
+			router := gin.New()
+			router.GET("/internal",
+				func(c *gin.Context) {
+					if tt.tenant != nil {
+						ctx := tenancy.WithTenant(c.Request.Context(), &models.Tenant{ID: *tt.tenant})
+						c.Request = c.Request.WithContext(ctx)
+					}
+				},
+				APIKeyAuth(service, tt.scopes...),
+				RequireScopes(tt.nested...),
+				func(c *gin.Context) {
+					principal = c.GetString(ContextUserID)
+					c.Status(http.StatusOK)
+				},
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/internal", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if principal != tt.wantPrincipal {
+				t.Errorf("got principal %q, want %q", principal, tt.wantPrincipal)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("a 401 without WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestRequireScopesWithoutKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/internal", RequireScopes("users:read"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "token", authorization: "Bearer scrape-secret", wantStatus: http.StatusOK},
+		{name: "scheme in another case", authorization: "bearer scrape-secret", wantStatus: http.StatusOK},
+		{name: "no header", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "token prefix", authorization: "Bearer scrape", wantStatus: http.StatusUnauthorized},
+		{name: "other scheme", authorization: "Basic scrape-secret", wantStatus: http.StatusUnauthorized},
+		{name: "no scheme", authorization: "scrape-secret", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/metrics", BearerToken("scrape-secret"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("a 401 without WWW-Authenticate")
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"skeleton/app/support/idempotency"
+	"skeleton/app/support/tenancy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency replays the stored response for requests that repeat an Idempotency-Key.
+//
+// Keys are scoped by route and principal, so it must run after the authentication
+// middleware of the route, which records the principal; keys of unauthenticated requests
+// are scoped by client IP instead. A retry while the first request is still
+// running gets 409 Conflict; reusing a key with a different request body gets 422.
+// Server errors (5xx) release the key so the client can retry.
+func Idempotency(store *idempotency.Store) gin.HandlerFunc {
+	config := store.Config()
+	if !config.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(config.Header)
+		if key == "" || !isUnsafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > config.MaxKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		scopedKey := idempotencyScope(c, key)
+		requestHash := hashRequest(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		existing, owned, err := store.Begin(ctx, scopedKey, requestHash)
+		if err != nil {
+			// Store unavailable: process the request without idempotency guarantees.
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+
+		if !owned {
+			replayIdempotent(c, existing, requestHash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// The client may be gone by now, as on the flaky networks retries come from; the
+		// outcome must still be stored, or the retry runs the handler again
+		ctx = context.WithoutCancel(ctx)
+		if recorder.Status() >= http.StatusInternalServerError {
+			if err := store.Release(ctx, scopedKey); err != nil {
+				_ = c.Error(err)
+			}
+			return
+		}
+
+		if err := store.Complete(ctx, scopedKey, &idempotency.Record{
+			RequestHash: requestHash,
+			StatusCode:  recorder.Status(),
+			Header:      recorder.Header().Clone(),
+			Body:        recorder.body.Bytes(),
+			CreatedAt:   time.Now(),
+		}); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// replayIdempotent answers a request whose key has already been claimed.
+func replayIdempotent(c *gin.Context, record *idempotency.Record, requestHash string) {
+	if record.RequestHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Idempotency key was already used with a different request",
+		})
+		return
+	}
+
+	if record.Status != idempotency.StatusCompleted {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "A request with this idempotency key is already in progress",
+		})
+		return
+	}
+
+	// Headers set by earlier middleware on this request (request ID, rate limits) take precedence.
+	header := c.Writer.Header()
+	for name, values := range record.Header {
+		if _, exists := header[name]; !exists {
+			header[name] = values
+		}
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Status(record.StatusCode)
+	_, _ = c.Writer.Write(record.Body)
+	c.Abort()
+}
+
+// idempotencyScope scopes the client key by tenant, route and principal so keys
+// cannot collide across tenants, endpoints or users. Anonymous clients are told apart by
+// IP, so one never gets the stored response of another.
+func idempotencyScope(c *gin.Context, key string) string {
+	principal := "ip:" + c.ClientIP()
+	if authenticated, ok := c.Get(ContextUserID); ok {
+		principal = fmt.Sprint(authenticated)
+	} else if apiKey := apiKeyFromRequest(c); apiKey != "" {
+		principal = "key:" + fingerprint(apiKey)
+	}
+
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+
+	return tenancy.CacheKey(c.Request.Context(), c.Request.Method+":"+route+":"+principal+":"+key)
+}
+
+// hashRequest fingerprints the request so key reuse with a different payload is detected.
+func hashRequest(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(uri))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder captures the response body while writing it through to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
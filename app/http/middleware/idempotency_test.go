@@ -0,0 +1,266 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"skeleton/app/support/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryCache is a cache store keeping JSON values, like the dg-cache drivers
+// Like a network store, it refuses calls on a cancelled context
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: map[string][]byte{}}
+}
+
+func (s *memoryCache) GetAs(ctx context.Context, key string, dest interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.values[key]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *memoryCache) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = data
+	return nil
+}
+
+func (s *memoryCache) Forget(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// idempotentRequest is a request of an idempotency test and the response it expects
+type idempotentRequest struct {
+	key  string
+	body string
+	ip   string
+	// cancel makes the client go away while the handler runs
+	cancel       bool
+	wantStatus   int
+	wantReplayed bool
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		// status is the status of the handler
+		status    int
+		requests  []idempotentRequest
+		wantCalls int
+	}{
+		{
+			name:   "retry is replayed",
+			status: http.StatusCreated,
+			requests: []idempotentRequest{
+				{key: "k", body: `{"name":"a"}`, wantStatus: http.StatusCreated},
+				{key: "k", body: `{"name":"a"}`, wantStatus: http.StatusCreated, wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "key reused with another body",
+			status: http.StatusCreated,
+			requests: []idempotentRequest{
+				{key: "k", body: `{"name":"a"}`, wantStatus: http.StatusCreated},
+				{key: "k", body: `{"name":"b"}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "anonymous clients do not share keys",
+			status: http.StatusCreated,
+			requests: []idempotentRequest{
+				{key: "k", body: `{"name":"a"}`, ip: "192.0.2.1", wantStatus: http.StatusCreated},
+				{key: "k", body: `{"name":"a"}`, ip: "192.0.2.2", wantStatus: http.StatusCreated},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "server error releases the key",
+			status: http.StatusInternalServerError,
+			requests: []idempotentRequest{
+				{key: "k", body: `{}`, wantStatus: http.StatusInternalServerError},
+				{key: "k", body: `{}`, wantStatus: http.StatusInternalServerError},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:   "response is stored after the client went away",
+			status: http.StatusCreated,
+			requests: []idempotentRequest{
+				{key: "k", body: `{}`, cancel: true, wantStatus: http.StatusCreated},
+				{key: "k", body: `{}`, wantStatus: http.StatusCreated, wantReplayed: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "requests without a key",
+			status: http.StatusCreated,
+			requests: []idempotentRequest{
+				{body: `{}`, wantStatus: http.StatusCreated},
+				{body: `{}`, wantStatus: http.StatusCreated},
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := idempotency.NewStore(idempotency.Config{Enabled: true}, newMemoryCache())
+			calls := 0
+			var cancel context.CancelFunc
+			router := gin.New()
+			router.POST("/users", Idempotency(store), func(c *gin.Context) {
+				calls++
+				if cancel != nil {
+					cancel()
+				}
+				c.JSON(tt.status, gin.H{"call": calls})
+			})
+
+			var first string
+			for i, r := range tt.requests {
+				ctx, cancelRequest := context.WithCancel(context.Background())
+				cancel = nil
+				if r.cancel {
+					cancel = cancelRequest
+				}
+				req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(r.body)).WithContext(ctx)
+				if r.key != "" {
+					req.Header.Set("Idempotency-Key", r.key)
+				}
+				if r.ip != "" {
+					req.RemoteAddr = r.ip + ":1234"
+				}
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				cancelRequest()
+
+				if rec.Code != r.wantStatus {
+					t.Fatalf("request %d: got status %d, want %d: %s", i, rec.Code, r.wantStatus, rec.Body)
+				}
+				if replayed := rec.Header().Get("Idempotent-Replayed") == "true"; replayed != r.wantReplayed {
+					t.Errorf("request %d: got replayed %v, want %v", i, replayed, r.wantReplayed)
+				}
+				if i == 0 {
+					first = rec.Body.String()
+				} else if r.wantReplayed && rec.Body.String() != first {
+					t.Errorf("request %d: got body %s, want the stored %s", i, rec.Body, first)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d handler calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := idempotency.NewStore(idempotency.Config{Enabled: true}, newMemoryCache())
+	started, release := make(chan struct{}), make(chan struct{})
+	router := gin.New()
+	router.POST("/users", Idempotency(store), func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+		req.Header.Set("Idempotency-Key", "k")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan int)
+	go func() { done <- send().Code }()
+	<-started
+
+	if rec := send(); rec.Code != http.StatusConflict {
+		t.Errorf("got status %d while the first request runs, want %d", rec.Code, http.StatusConflict)
+	}
+	close(release)
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("got status %d for the first request, want %d", code, http.StatusCreated)
+	}
+	if rec := send(); rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("got status %d replayed %q after it completed, want the replay", rec.Code, rec.Header().Get("Idempotent-Replayed"))
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		user   interface{}
+		want   string
+	}{
+		{name: "anonymous", want: "POST:/users:ip:192.0.2.1:k"},
+		{name: "authenticated", user: "key:7", want: "POST:/users:key:7:k"},
+		{name: "unverified API key", header: map[string]string{"X-API-Key": "secret"}, want: "POST:/users:key:" + fingerprint("secret") + ":k"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			router := gin.New()
+			router.POST("/users", func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set(ContextUserID, tt.user)
+				}
+				got = idempotencyScope(c, "k")
+			})
+			req := httptest.NewRequest(http.MethodPost, "/users", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			for name, value := range tt.header {
+				req.Header.Set(name, value)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPAllowList rejects requests whose remote address is not in one of the
+// listed IPs or CIDR ranges. It uses the TCP peer address rather than
+// forwarded headers, so it cannot be bypassed by spoofing X-Forwarded-For.
+func IPAllowList(entries []string) gin.HandlerFunc {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				panic("invalid IP in allow list: " + entry)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			panic("invalid CIDR in allow list: " + entry)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return func(c *gin.Context) {
+		if !ipAllowed(c.Request.RemoteAddr, prefixes) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ipAllowed reports whether the host part of a remote address is in one of the prefixes.
+func ipAllowed(remoteAddr string, prefixes []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIPAllowList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "listed ip", remoteAddr: "192.0.2.10:5000", wantStatus: http.StatusOK},
+		{name: "ip in a listed range", remoteAddr: "10.1.2.3:5000", wantStatus: http.StatusOK},
+		{name: "ipv6 in a listed range", remoteAddr: "[fd00::1]:5000", wantStatus: http.StatusOK},
+		{name: "ipv4-mapped ipv6", remoteAddr: "[::ffff:10.1.2.3]:5000", wantStatus: http.StatusOK},
+		{name: "address without a port", remoteAddr: "192.0.2.10", wantStatus: http.StatusOK},
+		{name: "other ip", remoteAddr: "203.0.113.5:5000", wantStatus: http.StatusForbidden},
+		// Forwarded headers are set by the client, so they are ignored
+		{name: "spoofed forwarded header", remoteAddr: "203.0.113.5:5000", headers: map[string]string{"X-Forwarded-For": "10.1.2.3", "X-Real-IP": "10.1.2.3"}, wantStatus: http.StatusForbidden},
+		{name: "invalid address", remoteAddr: "not-an-ip", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/metrics", IPAllowList([]string{"192.0.2.10", " 10.0.0.0/8", "fd00::/8"}), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIPAllowListInvalidEntry(t *testing.T) {
+	for _, entry := range []string{"10.0.0.300", "10.0.0.0/33", "localhost"} {
+		t.Run(entry, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("an allow list with %q did not panic", entry)
+				}
+			}()
+			IPAllowList([]string{entry})
+		})
+	}
+}
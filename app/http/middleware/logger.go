@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"skeleton/app/support/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDHeader is the header set by coreHTTP.RequestIDWithDefault.
+const requestIDHeader = "X-Request-ID"
+
+// ContextLogger stores a request-scoped logger in the request context, tagged
+// with the request ID and route template. Services, repositories and job
+// dispatches retrieve it with logging.FromContext(ctx).
+//
+// The base logger is the "http" component logger. It must run after RequestID
+// (for the ID) and Tracing (for the trace ID).
+func ContextLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := c.Request.Context()
+		args := []any{"method", c.Request.Method, "route", route}
+		if id := requestID(c); id != "" {
+			ctx = logging.WithRequestID(ctx, id)
+			args = append(args, "request_id", id)
+		}
+
+		c.Request = c.Request.WithContext(logging.WithContext(ctx, logging.Component("http").With(args...)))
+		c.Next()
+	}
+}
+
+// SetAuthenticatedUser records who the request is authenticated as, a principal such
+// as user:42 or key:7 for an API key client: it sets ContextUserID (used by rate
+// limiting and idempotency) and adds the principal to the context logger.
+// Authentication middleware calls it once the principal is known.
+func SetAuthenticatedUser(c *gin.Context, principal string) {
+	c.Set(ContextUserID, principal)
+	c.Request = c.Request.WithContext(logging.With(c.Request.Context(), "principal", principal))
+}
+
+// requestID returns the ID assigned by the request ID middleware, or the one sent by the client.
+func requestID(c *gin.Context) string {
+	if id := c.Writer.Header().Get(requestIDHeader); id != "" {
+		return id
+	}
+	return c.GetHeader(requestIDHeader)
+}
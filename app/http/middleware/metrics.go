@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"time"
+
+	"skeleton/app/support/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request count and latency per route template
+// (e.g. /api/v1/users/:id) so path parameters don't explode label cardinality.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	if !m.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	path := m.Config().Path
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == path {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
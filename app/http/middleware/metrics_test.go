@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"skeleton/app/support/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.New(metrics.Config{Enabled: true})
+	router := gin.New()
+	router.Use(Metrics(m))
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET(m.Config().Path, gin.WrapH(m.Handler()))
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/3", "/nope/1", "/nope/2", m.Config().Path} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, m.Config().Path, nil))
+	body, _ := io.ReadAll(rec.Body)
+	exposition := string(body)
+
+	// Requests are labelled with their route template, and unknown paths share one label
+	for _, want := range []string{
+		`app_http_requests_total{method="GET",route="/users/:id",status="200"} 3`,
+		`app_http_requests_total{method="GET",route="unmatched",status="404"} 2`,
+	} {
+		if !strings.Contains(exposition, want) {
+			t.Errorf("the exposition lacks %s", want)
+		}
+	}
+	for _, unwanted := range []string{`route="/users/1"`, `route="/nope/1"`, `route="/metrics"`} {
+		if strings.Contains(exposition, unwanted) {
+			t.Errorf("the exposition has %s", unwanted)
+		}
+	}
+}
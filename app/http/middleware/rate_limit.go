@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"skeleton/app/models"
+	"skeleton/app/support/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextUserID is the gin context key holding the authenticated principal, e.g. key:7.
+// Authentication middleware sets it with SetAuthenticatedUser; rate limiting and other
+// middleware read it.
+const ContextUserID = "user_id"
+
+// RateLimit throttles requests using the named limiter from config/ratelimit.yaml.
+// It sets RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset on every response
+// and Retry-After when the request is rejected.
+//
+// Store errors fail open: the request is let through and the error is attached to the context.
+func RateLimit(manager *ratelimit.Manager, name string) gin.HandlerFunc {
+	if !manager.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	limiter, err := manager.Limiter(name)
+	if err != nil {
+		panic("failed to resolve rate limiter: " + err.Error())
+	}
+
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(c.Request.Context(), rateLimitIdentity(c, limiter.KeyBy()))
+		if err != nil {
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+
+		if !result.Allowed {
+			c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitIdentity builds the limiter key for the request.
+// User and API key limiters fall back to the client IP for anonymous requests.
+func rateLimitIdentity(c *gin.Context, keyBy ratelimit.KeyBy) string {
+	switch keyBy {
+	case ratelimit.KeyByUser:
+		if principal, ok := c.Get(ContextUserID); ok {
+			return fmt.Sprint(principal)
+		}
+	case ratelimit.KeyByAPIKey:
+		if key, ok := c.Get(ContextAPIKey); ok {
+			return fmt.Sprintf("key:%d", key.(*models.APIKey).ID)
+		}
+		if key := apiKeyFromRequest(c); key != "" {
+			return "key:" + fingerprint(key)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// fingerprint returns a short hash of a secret so raw keys are never used in storage keys.
+func fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ceilSeconds formats a duration as whole seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"skeleton/app/support/httpcache"
+	"skeleton/app/support/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextCacheTags is the gin context key holding the tags of a cached response.
+const ContextCacheTags = "response_cache_tags"
+
+// unstoredHeaders are response headers specific to a single request, never replayed from the cache.
+var unstoredHeaders = []string{"Age", "Date", "Set-Cookie", "X-Cache", "X-Request-Id"}
+
+// ResponseCache serves GET responses from the response cache.
+//
+// Entries are keyed by path, normalized query and the request headers named in Vary.
+// Handlers control caching with Cache-Control: no-store, no-cache and private skip the cache,
+// s-maxage or max-age set the TTL, otherwise ttl (or the configured default when 0) applies.
+// Only 200 responses without cookies are stored. Requests carrying credentials bypass the cache.
+// Responses get an X-Cache header (HIT, MISS or BYPASS), hits an Age header, and all of them
+// a Vary header naming the configured headers, so shared caches downstream keep variants apart.
+func ResponseCache(store *httpcache.Store, ttl time.Duration) gin.HandlerFunc {
+	config := store.Config()
+	if !config.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if ttl <= 0 {
+		ttl = config.TTL
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		addVary(c.Writer.Header(), config.Vary)
+		c.Writer = &varyWriter{ResponseWriter: c.Writer, vary: config.Vary}
+
+		requestDirectives := parseCacheControl(c.GetHeader("Cache-Control"))
+		if requestDirectives.has("no-store") || hasCredentials(c.Request) {
+			c.Header("X-Cache", "BYPASS")
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		if !requestDirectives.has("no-cache") {
+			if entry, ok := store.Get(ctx, c.Request); ok {
+				replayCached(c, entry)
+				return
+			}
+		}
+
+		c.Header("X-Cache", "MISS")
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		entryTTL, ok := cacheableTTL(recorder, ttl, config.MaxBodySize)
+		if !ok {
+			return
+		}
+
+		header := recorder.Header().Clone()
+		for _, name := range unstoredHeaders {
+			header.Del(name)
+		}
+
+		route := c.FullPath()
+		tags := c.GetStringSlice(ContextCacheTags)
+		entry := &httpcache.Entry{
+			StatusCode: recorder.Status(),
+			Header:     header,
+			Body:       recorder.body.Bytes(),
+			StoredAt:   time.Now(),
+		}
+		if err := store.Put(ctx, c.Request, route, entry, varyHeaders(recorder.Header()), entryTTL, tags...); err != nil {
+			logging.FromContext(ctx).Warn("Failed to cache response", "route", route, "error", err)
+		}
+	}
+}
+
+// SetCacheTags tags the cached response of the current request, so it can be purged by tag.
+func SetCacheTags(c *gin.Context, tags ...string) {
+	c.Set(ContextCacheTags, append(c.GetStringSlice(ContextCacheTags), tags...))
+}
+
+// replayCached answers a request from a stored response.
+func replayCached(c *gin.Context, entry *httpcache.Entry) {
+	// Headers set by earlier middleware on this request (request ID, rate limits) take precedence.
+	header := c.Writer.Header()
+	for name, values := range entry.Header {
+		if _, exists := header[name]; !exists {
+			header[name] = values
+		}
+	}
+	addVary(header, varyHeaders(entry.Header))
+
+	age := time.Since(entry.StoredAt)
+	if age < 0 {
+		age = 0
+	}
+	c.Header("Age", strconv.Itoa(int(age.Seconds())))
+	c.Header("X-Cache", "HIT")
+	c.Status(entry.StatusCode)
+	_, _ = c.Writer.Write(entry.Body)
+	c.Abort()
+}
+
+// varyWriter adds the configured headers to the Vary header as the response is written,
+// so a handler replacing the Vary header does not drop them.
+type varyWriter struct {
+	gin.ResponseWriter
+	vary []string
+}
+
+func (w *varyWriter) WriteHeaderNow() {
+	addVary(w.Header(), w.vary)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *varyWriter) Write(data []byte) (int, error) {
+	addVary(w.Header(), w.vary)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *varyWriter) WriteString(s string) (int, error) {
+	addVary(w.Header(), w.vary)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// cacheableTTL decides whether a response is stored, and for how long.
+func cacheableTTL(recorder *responseRecorder, fallback time.Duration, maxBodySize int) (time.Duration, bool) {
+	header := recorder.Header()
+	if recorder.Status() != http.StatusOK || recorder.body.Len() > maxBodySize || header.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+	for _, name := range varyHeaders(header) {
+		if name == "*" {
+			return 0, false
+		}
+	}
+
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	if directives.has("no-store") || directives.has("no-cache") || directives.has("private") {
+		return 0, false
+	}
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if value, ok := directives[name]; ok {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds <= 0 {
+				return 0, false
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return fallback, true
+}
+
+// cacheControl holds parsed Cache-Control directives, lowercased.
+type cacheControl map[string]string
+
+func (d cacheControl) has(name string) bool {
+	_, ok := d[name]
+	return ok
+}
+
+func parseCacheControl(value string) cacheControl {
+	directives := make(cacheControl)
+	for _, part := range strings.Split(value, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+	return directives
+}
+
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// addVary adds the names missing from the Vary header, unless it already varies on everything.
+func addVary(header http.Header, names []string) {
+	present := make(map[string]bool)
+	for _, name := range varyHeaders(header) {
+		present[http.CanonicalHeaderKey(name)] = true
+	}
+	if present["*"] {
+		return
+	}
+	for _, name := range names {
+		if name = http.CanonicalHeaderKey(name); !present[name] {
+			header.Add("Vary", name)
+			present[name] = true
+		}
+	}
+}
+
+// hasCredentials reports whether the response may be personalized for the caller.
+func hasCredentials(r *http.Request) bool {
+	return r.Header.Get("Authorization") != "" || r.Header.Get("X-API-Key") != "" || r.Header.Get("Cookie") != ""
+}
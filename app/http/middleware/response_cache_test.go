@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"skeleton/app/support/cache"
+	"skeleton/app/support/httpcache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedRequest is a request of a response cache test and the response it expects
+type cachedRequest struct {
+	method    string
+	url       string
+	headers   map[string]string
+	wantCache string
+}
+
+func TestResponseCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		// respond sets the response of the handler, a 200 by default
+		respond     func(c *gin.Context)
+		requests    []cachedRequest
+		wantCalls   int
+		maxBodySize int
+	}{
+		{
+			name:      "response is stored",
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "HIT"}, {wantCache: "HIT"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "other methods pass through",
+			requests:  []cachedRequest{{method: http.MethodPost}, {method: http.MethodPost}},
+			wantCalls: 2,
+		},
+		{
+			name: "requests with credentials bypass the cache",
+			requests: []cachedRequest{
+				{wantCache: "MISS"},
+				{headers: map[string]string{"Authorization": "Bearer token"}, wantCache: "BYPASS"},
+				{headers: map[string]string{"X-API-Key": "sk_a_b"}, wantCache: "BYPASS"},
+				{headers: map[string]string{"Cookie": "session=1"}, wantCache: "BYPASS"},
+			},
+			wantCalls: 4,
+		},
+		{
+			name:      "request no-store",
+			requests:  []cachedRequest{{headers: map[string]string{"Cache-Control": "no-store"}, wantCache: "BYPASS"}, {wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "request no-cache revalidates",
+			requests:  []cachedRequest{{wantCache: "MISS"}, {headers: map[string]string{"Cache-Control": "no-cache"}, wantCache: "MISS"}, {wantCache: "HIT"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "error responses",
+			respond:   func(c *gin.Context) { c.String(http.StatusNotFound, "missing") },
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+		{
+			name: "responses setting cookies",
+			respond: func(c *gin.Context) {
+				c.SetCookie("session", "1", 60, "/", "", false, true)
+				c.String(http.StatusOK, "users")
+			},
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "private responses",
+			respond:   respondWith("Cache-Control", "private, max-age=60"),
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "no-store responses",
+			respond:   respondWith("Cache-Control", "no-store"),
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "zero max-age",
+			respond:   respondWith("Cache-Control", "max-age=0"),
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "public max-age",
+			respond:   respondWith("Cache-Control", "public, max-age=60"),
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "HIT"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "vary on everything",
+			respond:   respondWith("Vary", "*"),
+			requests:  []cachedRequest{{wantCache: "MISS"}, {wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+		{
+			name:        "bodies over the limit",
+			maxBodySize: 3,
+			requests:    []cachedRequest{{wantCache: "MISS"}, {wantCache: "MISS"}},
+			wantCalls:   2,
+		},
+		{
+			name: "configured vary header",
+			requests: []cachedRequest{
+				{headers: map[string]string{"Accept-Language": "en"}, wantCache: "MISS"},
+				{headers: map[string]string{"Accept-Language": "fr"}, wantCache: "MISS"},
+				{headers: map[string]string{"Accept-Language": "en"}, wantCache: "HIT"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:    "response vary header",
+			respond: respondWith("Vary", "Accept"),
+			requests: []cachedRequest{
+				{headers: map[string]string{"Accept": "application/json"}, wantCache: "MISS"},
+				{headers: map[string]string{"Accept": "text/html"}, wantCache: "MISS"},
+				{headers: map[string]string{"Accept": "application/json"}, wantCache: "HIT"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:      "query parameters in another order",
+			requests:  []cachedRequest{{url: "/users?a=1&b=2", wantCache: "MISS"}, {url: "/users?b=2&a=1", wantCache: "HIT"}, {url: "/users?a=2&b=2", wantCache: "MISS"}},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := httpcache.NewStore(httpcache.Config{Enabled: true, Vary: []string{"Accept-Language"}, MaxBodySize: tt.maxBodySize},
+				newMemoryCache(), cache.NewTagger(cache.NewMemoryTagIndex()))
+			respond := tt.respond
+			if respond == nil {
+				respond = func(c *gin.Context) { c.String(http.StatusOK, "users") }
+			}
+			calls := 0
+			handler := func(c *gin.Context) {
+				calls++
+				respond(c)
+			}
+			router := gin.New()
+			router.GET("/users", ResponseCache(store, 0), handler)
+			router.POST("/users", ResponseCache(store, 0), handler)
+
+			for i, r := range tt.requests {
+				rec := sendCached(router, r)
+				if got := rec.Header().Get("X-Cache"); got != r.wantCache {
+					t.Errorf("request %d: got X-Cache %q, want %q", i, got, r.wantCache)
+				}
+				vary := strings.Join(rec.Header().Values("Vary"), ", ")
+				if r.method == "" && vary != "*" && !strings.Contains(vary, "Accept-Language") {
+					t.Errorf("request %d: got Vary %q, want the configured headers", i, vary)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("got %d handler calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestResponseCacheReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := httpcache.NewStore(httpcache.Config{Enabled: true}, newMemoryCache(), cache.NewTagger(cache.NewMemoryTagIndex()))
+	request := 0
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		// Set on every request, like the request ID middleware
+		request++
+		c.Header("X-Request-Id", strings.Repeat("r", request))
+	})
+	router.GET("/users", ResponseCache(store, 0), func(c *gin.Context) {
+		c.Header("X-Total", "2")
+		c.JSON(http.StatusOK, gin.H{"users": 2})
+	})
+
+	first := sendCached(router, cachedRequest{})
+	second := sendCached(router, cachedRequest{})
+	if second.Header().Get("X-Cache") != "HIT" {
+		t.Fatalf("got X-Cache %q, want HIT", second.Header().Get("X-Cache"))
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("got %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("X-Total") != "2" || second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("the headers of the response were not replayed: %v", second.Header())
+	}
+	if second.Header().Get("X-Request-Id") != "rr" {
+		t.Errorf("got X-Request-Id %q, want the one of this request", second.Header().Get("X-Request-Id"))
+	}
+	if second.Header().Get("Age") == "" {
+		t.Error("a hit without an Age header")
+	}
+}
+
+func TestResponseCachePurge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := httpcache.NewStore(httpcache.Config{Enabled: true}, newMemoryCache(), cache.NewTagger(cache.NewMemoryTagIndex()))
+	router := gin.New()
+	router.GET("/users/:id", ResponseCache(store, time.Minute), func(c *gin.Context) {
+		SetCacheTags(c, "user:"+c.Param("id"))
+		c.String(http.StatusOK, c.Param("id"))
+	})
+
+	cached := func(url string) bool {
+		return sendCached(router, cachedRequest{url: url}).Header().Get("X-Cache") == "HIT"
+	}
+	sendCached(router, cachedRequest{url: "/users/1"})
+	sendCached(router, cachedRequest{url: "/users/2"})
+
+	ctx := httptest.NewRequest(http.MethodGet, "/", nil).Context()
+	if err := store.PurgeTags(ctx, "user:1"); err != nil {
+		t.Fatal(err)
+	}
+	if cached("/users/1") || !cached("/users/2") {
+		t.Error("purging a tag did not purge exactly its responses")
+	}
+
+	if err := store.PurgeRoute(ctx, "/users/:id"); err != nil {
+		t.Fatal(err)
+	}
+	if cached("/users/1") || cached("/users/2") {
+		t.Error("purging the route kept its responses")
+	}
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := httpcache.NewStore(httpcache.Config{}, newMemoryCache(), cache.NewTagger(cache.NewMemoryTagIndex()))
+	router := gin.New()
+	router.GET("/users", ResponseCache(store, 0), func(c *gin.Context) { c.String(http.StatusOK, "users") })
+
+	for i := 0; i < 2; i++ {
+		if rec := sendCached(router, cachedRequest{}); rec.Header().Get("X-Cache") != "" {
+			t.Errorf("got X-Cache %q from a disabled cache", rec.Header().Get("X-Cache"))
+		}
+	}
+}
+
+// respondWith returns a handler answering 200 with a response header set
+func respondWith(name, value string) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.Header(name, value)
+		c.String(http.StatusOK, "users")
+	}
+}
+
+// sendCached sends a request of a response cache test, a GET of /users by default
+func sendCached(router *gin.Engine, r cachedRequest) *httptest.ResponseRecorder {
+	method, url := r.method, r.url
+	if method == "" {
+		method = http.MethodGet
+	}
+	if url == "" {
+		url = "/users"
+	}
+	req := httptest.NewRequest(method, url, nil)
+	for name, value := range r.headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"skeleton/app/support/logging"
+	"skeleton/app/support/tenancy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextTenant is the gin context key holding the resolved *models.Tenant.
+const ContextTenant = "tenant"
+
+// Tenant resolves the tenant of the request (subdomain, header or JWT claim, see config/tenancy.yaml)
+// and scopes the request context to it, so repositories use the tenant schema and cache keys,
+// idempotency keys and dispatched jobs are tenant-scoped.
+//
+// Unknown tenants get 404 and inactive ones 403. Requests without a tenant get 400 when
+// tenancy.required is set, and otherwise run against the shared schema.
+func Tenant(registry *tenancy.Registry) gin.HandlerFunc {
+	if registry == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	config := registry.Config()
+
+	return func(c *gin.Context) {
+		tenant, err := registry.Resolve(c.Request)
+		switch {
+		case errors.Is(err, tenancy.ErrNoTenant):
+			if config.Required {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Tenant is required"})
+				return
+			}
+			c.Next()
+			return
+
+		case errors.Is(err, tenancy.ErrTenantNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+			return
+
+		case errors.Is(err, tenancy.ErrTenantInactive):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Tenant is inactive"})
+			return
+
+		case errors.Is(err, tenancy.ErrTenantMismatch):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Tenant does not match the token"})
+			return
+
+		case errors.Is(err, tenancy.ErrInvalidToken):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid tenant token"})
+			return
+
+		case err != nil:
+			_ = c.Error(err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Tenant registry unavailable"})
+			return
+		}
+
+		ctx := tenancy.WithTenant(c.Request.Context(), tenant)
+		ctx = logging.With(ctx, "tenant", tenant.Slug)
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(ContextTenant, tenant)
+
+		c.Next()
+	}
+}
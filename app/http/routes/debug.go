@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"net/http/pprof"
+
+	"skeleton/app/http/controllers"
+	"skeleton/app/http/middleware"
+	"skeleton/app/services"
+
+	"github.com/donnigundala/dg-core/config"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"github.com/gin-gonic/gin"
+)
+
+// DebugConfig represents the diagnostics endpoint configuration (server.debug in server.yaml).
+type DebugConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ListenAddr serves /debug on a separate internal listener instead of the main router
+	ListenAddr string `mapstructure:"listen_addr"`
+	// AllowedIPs restricts access to these IPs or CIDR ranges
+	AllowedIPs []string `mapstructure:"allowed_ips"`
+	// Scope requires an API key granting this scope
+	Scope string `mapstructure:"scope"`
+}
+
+// LoadDebugConfig loads the debug section of server.yaml.
+func LoadDebugConfig() (DebugConfig, error) {
+	var server struct {
+		Debug DebugConfig `mapstructure:"debug"`
+	}
+	err := config.Inject("server", &server)
+	return server.Debug, err
+}
+
+// RegisterDebug registers the /debug routes: net/http/pprof profiles and runtime diagnostics.
+// Access requires the IP allow list and/or the API key scope; with neither configured
+// the routes are not registered at all.
+func RegisterDebug(app foundation.Application, router gin.IRouter, cfg DebugConfig) bool {
+	var guards []gin.HandlerFunc
+	if len(cfg.AllowedIPs) > 0 {
+		guards = append(guards, middleware.IPAllowList(cfg.AllowedIPs))
+	}
+	if cfg.Scope != "" {
+		guards = append(guards, middleware.APIKeyAuth(services.MustResolveAPIKeyService(app), cfg.Scope))
+	}
+	if len(guards) == 0 {
+		return false
+	}
+
+	ctrl := controllers.NewDebugController(app)
+
+	// net/http/pprof expects to be served under /debug/pprof/
+	debug := router.Group("/debug", guards...)
+	{
+		debug.GET("/runtime", ctrl.Runtime)
+		debug.GET("/goroutines", ctrl.Goroutines)
+		debug.POST("/gc", ctrl.GC)
+		debug.GET("/build", ctrl.Build)
+		debug.GET("/bindings", ctrl.Bindings)
+
+		debug.GET("/pprof/", gin.WrapF(pprof.Index))
+		debug.GET("/pprof/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/pprof/profile", gin.WrapF(pprof.Profile))
+		debug.GET("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.POST("/pprof/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/pprof/trace", gin.WrapF(pprof.Trace))
+		debug.GET("/pprof/:name", func(c *gin.Context) {
+			pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	return true
+}
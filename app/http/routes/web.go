@@ -2,6 +2,12 @@ package routes
 
 import (
 	"skeleton/app/http/controllers"
+	"skeleton/app/http/middleware"
+	"skeleton/app/services"
+	"skeleton/app/support/httpcache"
+	"skeleton/app/support/idempotency"
+	"skeleton/app/support/ratelimit"
+	"skeleton/app/support/tenancy"
 
 	"github.com/donnigundala/dg-core/contracts/foundation"
 	"github.com/gin-gonic/gin"
@@ -11,9 +17,13 @@ import (
 func Register(app foundation.Application, router *gin.Engine) {
 	// Initialize all controllers once
 	ctrl := controllers.Initialize(app)
+	limiter := ratelimit.MustResolve(app)
+	idempotencyStore := idempotency.MustResolve(app)
+	apiKeyService := services.MustResolveAPIKeyService(app)
+	responseCache := httpcache.MustResolve(app)
 
 	// Welcome route
-	router.GET("/", func(c *gin.Context) {
+	router.GET("/", middleware.ResponseCache(responseCache, 0), func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"message": "Welcome to Skeleton V2!",
 			"version": "2.0.0",
@@ -22,9 +32,16 @@ func Register(app foundation.Application, router *gin.Engine) {
 	})
 
 	// API group
-	api := router.Group("/api/v1")
+	v1 := router.Group("/api/v1",
+		middleware.Tenant(tenancy.Resolve(app)), // Scopes the request to the tenant, when tenancy is enabled
+		middleware.RateLimit(limiter, "api"),
+	)
+
+	// Idempotency replays retried POST/PUT/PATCH/DELETE requests. It runs after any
+	// authentication of its group, so keys are scoped to the authenticated principal.
+	api := v1.Group("", middleware.Idempotency(idempotencyStore))
 	{
-		api.GET("/status", func(c *gin.Context) {
+		api.GET("/status", middleware.ResponseCache(responseCache, 0), func(c *gin.Context) {
 			c.JSON(200, gin.H{
 				"status": "online",
 				"app":    "skeleton-v2",
@@ -32,10 +49,22 @@ func Register(app foundation.Application, router *gin.Engine) {
 		})
 
 		// User routes - clean and direct
-		api.POST("/users", ctrl.User.Create)
+		api.POST("/users", middleware.RateLimit(limiter, "user-create"), ctrl.User.Create)
 		api.GET("/users", ctrl.User.List)
 		api.GET("/users/:id", ctrl.User.Get)
 		api.PUT("/users/:id", ctrl.User.Update)
 		api.DELETE("/users/:id", ctrl.User.Delete)
 	}
+
+	// Internal routes for machine clients (API key + scope required), each with its own quota
+	internal := v1.Group("/internal",
+		middleware.APIKeyAuth(apiKeyService),
+		middleware.RateLimit(limiter, "internal"),
+		middleware.Idempotency(idempotencyStore),
+	)
+	{
+		internal.GET("/users", middleware.RequireScopes("users:read"), ctrl.User.List)
+		internal.GET("/users/:id", middleware.RequireScopes("users:read"), ctrl.User.Get)
+		internal.DELETE("/cache/responses", middleware.RequireScopes("cache:purge"), ctrl.Cache.Purge)
+	}
 }
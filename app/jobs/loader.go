@@ -4,6 +4,7 @@ import (
 	"log/slog"
 
 	"skeleton/app/support/scheduler"
+	"skeleton/app/support/worker"
 )
 
 // LoadAll loads all available scheduled jobs
@@ -21,3 +22,14 @@ func LoadAll(logger *slog.Logger) *scheduler.Registry {
 
 	return registry
 }
+
+// LoadQueueHandlers loads all queue job handlers
+// Add new handlers here so dispatched jobs get processed
+func LoadQueueHandlers(logger *slog.Logger) *worker.Registry {
+	registry := worker.NewRegistry(logger)
+
+	// Register all queue handlers here
+	registry.Register(NewSendWelcomeEmailHandler())
+
+	return registry
+}
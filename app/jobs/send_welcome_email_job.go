@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+
+	"skeleton/app/support/logging"
+	"skeleton/app/support/worker"
+)
+
+// SendWelcomeEmailHandler sends the welcome email for newly created users
+type SendWelcomeEmailHandler struct {
+	worker.BaseHandler
+}
+
+// NewSendWelcomeEmailHandler creates a new welcome email handler
+func NewSendWelcomeEmailHandler() *SendWelcomeEmailHandler {
+	return &SendWelcomeEmailHandler{
+		BaseHandler: worker.NewBaseHandler("send-welcome-email"),
+	}
+}
+
+// Handle executes the job logic
+func (h *SendWelcomeEmailHandler) Handle(ctx context.Context, payload worker.Payload) error {
+	userID, err := payload.Uint("user_id")
+	if err != nil {
+		return err
+	}
+
+	// Plug in the mail integration here
+	// The context logger carries the job name and the originating request ID
+	logging.FromContext(ctx).Info("Welcome email sent", "user_id", userID)
+	return nil
+}
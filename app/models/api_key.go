@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ScopeAll grants access to every scope.
+const ScopeAll = "*"
+
+// APIKey represents an API key issued to a machine client.
+// Only a hash of the secret is stored; the plain key is shown once on creation.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;uniqueIndex;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;not null" json:"-"`
+	Scopes     string     `gorm:"size:255;not null;default:''" json:"scopes"`
+	TenantID   *uint      `json:"tenant_id"` // Nil for keys of the shared schema
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the APIKey model.
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the granted scopes.
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// HasScope reports whether the key grants the given scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.ScopeList() {
+		if granted == scope || granted == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsTenant reports whether the key may act for the tenant; nil means the shared schema.
+// Tenant keys are bound to their tenant, and keys without a tenant never reach tenant data.
+func (k *APIKey) AllowsTenant(tenantID *uint) bool {
+	if k.TenantID == nil || tenantID == nil {
+		return k.TenantID == nil && tenantID == nil
+	}
+	return *k.TenantID == *tenantID
+}
+
+// IsActive reports whether the key is neither revoked nor expired at the given time.
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
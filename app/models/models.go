@@ -0,0 +1,11 @@
+package models
+
+// All returns every model whose table is created by the SQL migrations.
+// Add new models here so migrate diff checks them against the database.
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&APIKey{},
+		&Tenant{},
+	}
+}
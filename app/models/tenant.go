@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Tenant represents a tenant of the application, whose data lives in its own PostgreSQL schema.
+type Tenant struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Slug      string    `gorm:"size:63;uniqueIndex;not null" json:"slug"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Domain    *string   `gorm:"size:255;uniqueIndex" json:"domain,omitempty"`
+	Schema    string    `gorm:"size:63;not null" json:"schema"`
+	Active    bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Tenant model.
+func (Tenant) TableName() string {
+	return "tenants"
+}
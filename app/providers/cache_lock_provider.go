@@ -0,0 +1,35 @@
+package providers
+
+import (
+	appCache "skeleton/app/support/cache"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// CacheLockServiceProvider registers the distributed lock used to coalesce cache loads across instances.
+type CacheLockServiceProvider struct{}
+
+// NewCacheLockServiceProvider creates a new CacheLockServiceProvider.
+func NewCacheLockServiceProvider() *CacheLockServiceProvider {
+	return &CacheLockServiceProvider{}
+}
+
+// Register binds the locker into the container when Redis is configured.
+func (p *CacheLockServiceProvider) Register(app foundation.Application) error {
+	options, ok := redisOptions()
+	if !ok {
+		return nil
+	}
+
+	app.Singleton("cacheLocker", func() (interface{}, error) {
+		return appCache.NewRedisLocker(goredis.NewClient(options), "cache:"), nil
+	})
+	return nil
+}
+
+// Boot boots the service provider.
+func (p *CacheLockServiceProvider) Boot(app foundation.Application) error {
+	// Nothing to boot for cache locks
+	return nil
+}
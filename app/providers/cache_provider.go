@@ -1,26 +1,73 @@
 package providers
 
 import (
+	"errors"
+
+	appCache "skeleton/app/support/cache"
+	"skeleton/app/support/health"
+
 	cache "github.com/donnigundala/dg-cache"
 	cacheMemory "github.com/donnigundala/dg-cache/drivers/memory"
 	cacheRedis "github.com/donnigundala/dg-cache/drivers/redis"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	goredis "github.com/redis/go-redis/v9"
 )
 
 // NewCacheServiceProvider creates a cache provider using the new plugin pattern.
 // This is the recommended approach for most applications.
 //
 // The provider will automatically:
-// - Register common drivers (memory, redis)
+// - Register common drivers (memory, redis, layered)
 // - Handle graceful shutdown
 //
 // For advanced customization, you can still create a custom provider
 // using cache.NewManager() and cache.RegisterDriver() directly.
-func NewCacheServiceProvider(config cache.Config) *cache.CacheServiceProvider {
-	return &cache.CacheServiceProvider{
+func NewCacheServiceProvider(config cache.Config) *CacheServiceProvider {
+	return &CacheServiceProvider{CacheServiceProvider: &cache.CacheServiceProvider{
 		Config: config,
 		DriverFactories: map[string]cache.DriverFactory{
-			"memory": cacheMemory.NewDriver,
-			"redis":  cacheRedis.NewDriver,
+			"memory":  cacheMemory.NewDriver,
+			"redis":   cacheRedis.NewDriver,
+			"layered": newLayeredCacheDriver,
 		},
+	}}
+}
+
+// CacheServiceProvider is the dg-cache provider, registering the readiness
+// check of the shared Redis connection once it has booted.
+type CacheServiceProvider struct {
+	*cache.CacheServiceProvider
+}
+
+// Boot boots the cache and registers the "redis" check when Redis is configured.
+func (p *CacheServiceProvider) Boot(app foundation.Application) error {
+	if err := p.CacheServiceProvider.Boot(app); err != nil {
+		return err
+	}
+
+	if options, ok := redisOptions(); ok {
+		health.MustResolve(app).Register(redisCheck("redis", options))
+	}
+	return nil
+}
+
+// newLayeredCacheDriver creates a two-tier driver: an in-process LRU in front of
+// the Redis driver, with invalidations broadcast over Redis pub/sub.
+func newLayeredCacheDriver(config cache.StoreConfig) (cache.Driver, error) {
+	options, ok := redisOptions()
+	if !ok {
+		return nil, errors.New("layered cache driver requires redis configuration")
+	}
+
+	l2, err := cacheRedis.NewDriver(config)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := appCache.NewLayeredDriver(l2, goredis.NewClient(options), config.Prefix, appCache.LayeredOptionsFrom(config.Options))
+	if err != nil {
+		_ = l2.Close()
+		return nil, err
 	}
+	return driver, nil
 }
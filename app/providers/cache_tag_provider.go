@@ -0,0 +1,58 @@
+package providers
+
+import (
+	appCache "skeleton/app/support/cache"
+
+	cache "github.com/donnigundala/dg-cache"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// CacheTagServiceProvider registers tag-based invalidation for every cache store.
+type CacheTagServiceProvider struct {
+	config cache.Config
+	client *goredis.Client
+}
+
+// NewCacheTagServiceProvider creates a new CacheTagServiceProvider.
+func NewCacheTagServiceProvider(config cache.Config) *CacheTagServiceProvider {
+	return &CacheTagServiceProvider{config: config}
+}
+
+// Register binds the taggers into the container, one per store.
+// Each store's tag index lives next to its data: in Redis for the redis and
+// layered drivers, in process for the memory driver. Redis tag sets are
+// namespaced by store, so flushing a tag never touches another store's keys.
+func (p *CacheTagServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("cacheTaggers", func() (interface{}, error) {
+		taggers := make(map[string]*appCache.Tagger, len(p.config.Stores))
+		for name, store := range p.config.Stores {
+			taggers[name] = appCache.NewTagger(p.tagIndex(name, store))
+		}
+		return appCache.NewTaggers(p.config.DefaultStore, taggers), nil
+	})
+	return nil
+}
+
+// tagIndex returns the tag index of a store.
+func (p *CacheTagServiceProvider) tagIndex(name string, store cache.StoreConfig) appCache.TagIndex {
+	if store.Driver == "memory" {
+		return appCache.NewMemoryTagIndex()
+	}
+
+	if p.client == nil {
+		options, ok := redisOptions()
+		if !ok {
+			return appCache.NewMemoryTagIndex()
+		}
+		// One client serves the tag indexes of every Redis-backed store
+		p.client = goredis.NewClient(options)
+	}
+	return appCache.NewRedisTagIndex(p.client, "cache:tag:"+name+":")
+}
+
+// Boot boots the service provider.
+func (p *CacheTagServiceProvider) Boot(app foundation.Application) error {
+	// Nothing to boot for cache tags
+	return nil
+}
@@ -1,9 +1,20 @@
 package providers
 
 import (
+	"fmt"
+
+	"skeleton/app/support/health"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
 	database "github.com/donnigundala/dg-database"
 )
 
+// DatabaseServiceProvider is the dg-database provider, registering the readiness
+// check of the default connection once it has booted.
+type DatabaseServiceProvider struct {
+	*database.DatabaseServiceProvider
+}
+
 // NewDatabaseServiceProvider creates a database provider using the new plugin pattern.
 // This is the recommended approach for most applications.
 //
@@ -14,6 +25,20 @@ import (
 //
 // For advanced customization, you can still create a custom provider
 // using database.NewManager() directly.
-func NewDatabaseServiceProvider() *database.DatabaseServiceProvider {
-	return &database.DatabaseServiceProvider{}
+func NewDatabaseServiceProvider() *DatabaseServiceProvider {
+	return &DatabaseServiceProvider{DatabaseServiceProvider: &database.DatabaseServiceProvider{}}
+}
+
+// Boot boots the database and registers the "database" check.
+func (p *DatabaseServiceProvider) Boot(app foundation.Application) error {
+	if err := p.DatabaseServiceProvider.Boot(app); err != nil {
+		return err
+	}
+
+	sqlDB, err := database.MustResolve(app).DB().DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database handle: %w", err)
+	}
+	health.MustResolve(app).Register(health.Check{Name: "database", Critical: true, Run: health.Database(sqlDB)})
+	return nil
 }
@@ -0,0 +1,50 @@
+package providers
+
+import (
+	"time"
+
+	"skeleton/app/support/health"
+
+	"github.com/donnigundala/dg-core/config"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	filesystem "github.com/donnigundala/dg-filesystem"
+)
+
+// FilesystemServiceProvider is the dg-filesystem provider, registering the
+// readiness check of the local disks once it has booted.
+type FilesystemServiceProvider struct {
+	*filesystem.ServiceProvider
+}
+
+// NewFilesystemServiceProvider creates a new FilesystemServiceProvider.
+func NewFilesystemServiceProvider() *FilesystemServiceProvider {
+	return &FilesystemServiceProvider{ServiceProvider: filesystem.NewFilesystemServiceProvider()}
+}
+
+// Boot boots the filesystem and registers the "disk" check for the local disks.
+func (p *FilesystemServiceProvider) Boot(app foundation.Application) error {
+	if err := p.ServiceProvider.Boot(app); err != nil {
+		return err
+	}
+
+	var filesystemConfig struct {
+		Disks map[string]struct {
+			Driver string `mapstructure:"driver"`
+			Root   string `mapstructure:"root"`
+		} `mapstructure:"disks"`
+	}
+	if err := config.Inject("filesystem", &filesystemConfig); err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, disk := range filesystemConfig.Disks {
+		if disk.Driver == "local" && disk.Root != "" {
+			dirs = append(dirs, disk.Root)
+		}
+	}
+	if len(dirs) > 0 {
+		health.MustResolve(app).Register(health.Check{Name: "disk", Interval: 30 * time.Second, Run: health.WritableDirs(dirs...)})
+	}
+	return nil
+}
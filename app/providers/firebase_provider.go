@@ -0,0 +1,47 @@
+package providers
+
+import (
+	"time"
+
+	"skeleton/app/support/health"
+
+	"github.com/donnigundala/dg-core/config"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	firebase "github.com/donnigundala/dg-firebase"
+)
+
+// FirebaseServiceProvider is the dg-firebase provider, registering the readiness
+// check of the credentials once it has booted.
+type FirebaseServiceProvider struct {
+	*firebase.ServiceProvider
+}
+
+// NewFirebaseServiceProvider creates a new FirebaseServiceProvider.
+func NewFirebaseServiceProvider() *FirebaseServiceProvider {
+	return &FirebaseServiceProvider{ServiceProvider: firebase.NewFirebaseServiceProvider()}
+}
+
+// Boot boots Firebase and registers the "firebase" check when credentials are configured.
+func (p *FirebaseServiceProvider) Boot(app foundation.Application) error {
+	if err := p.ServiceProvider.Boot(app); err != nil {
+		return err
+	}
+
+	var firebaseConfig struct {
+		CredentialsFile string `mapstructure:"credentials_file"`
+		CredentialsJSON string `mapstructure:"credentials_json"`
+	}
+	if err := config.Inject("firebase", &firebaseConfig); err != nil {
+		return nil
+	}
+	if firebaseConfig.CredentialsFile == "" && firebaseConfig.CredentialsJSON == "" {
+		return nil
+	}
+
+	health.MustResolve(app).Register(health.Check{
+		Name:     "firebase",
+		Interval: time.Minute,
+		Run:      health.FirebaseCredentials(firebaseConfig.CredentialsFile, firebaseConfig.CredentialsJSON),
+	})
+	return nil
+}
@@ -0,0 +1,34 @@
+package providers
+
+import (
+	"skeleton/app/support/health"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// HealthServiceProvider binds the readiness check registry. Each infrastructure
+// provider registers the check of its own dependency when it boots, and application
+// providers can add theirs with health.MustResolve(app).Register.
+type HealthServiceProvider struct {
+	config     health.Config
+	production bool
+}
+
+// NewHealthServiceProvider creates a new HealthServiceProvider.
+// It must be registered before the providers that register checks.
+func NewHealthServiceProvider(config health.Config, production bool) *HealthServiceProvider {
+	return &HealthServiceProvider{config: config, production: production}
+}
+
+// Register binds the health check registry into the container.
+func (p *HealthServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("health", func() (interface{}, error) {
+		return health.NewRegistry(p.config, p.production), nil
+	})
+	return nil
+}
+
+// Boot does nothing; the checks are registered by the providers they belong to.
+func (p *HealthServiceProvider) Boot(app foundation.Application) error {
+	return nil
+}
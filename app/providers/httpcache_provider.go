@@ -0,0 +1,34 @@
+package providers
+
+import (
+	appCache "skeleton/app/support/cache"
+	"skeleton/app/support/httpcache"
+
+	cache "github.com/donnigundala/dg-cache"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// HTTPCacheServiceProvider registers the HTTP response cache store.
+type HTTPCacheServiceProvider struct {
+	config httpcache.Config
+}
+
+// NewHTTPCacheServiceProvider creates a new HTTPCacheServiceProvider.
+// The cache and cache tag providers must be registered first.
+func NewHTTPCacheServiceProvider(config httpcache.Config) *HTTPCacheServiceProvider {
+	return &HTTPCacheServiceProvider{config: config}
+}
+
+// Register binds the response cache store into the container.
+func (p *HTTPCacheServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("httpCache", func() (interface{}, error) {
+		return httpcache.NewStore(p.config, cache.NewInjectable(app).Cache(), appCache.MustResolveTagger(app)), nil
+	})
+	return nil
+}
+
+// Boot boots the service provider.
+func (p *HTTPCacheServiceProvider) Boot(app foundation.Application) error {
+	// Nothing to boot for the response cache
+	return nil
+}
@@ -0,0 +1,33 @@
+package providers
+
+import (
+	"skeleton/app/support/idempotency"
+
+	cache "github.com/donnigundala/dg-cache"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// IdempotencyServiceProvider registers the idempotency key store.
+type IdempotencyServiceProvider struct {
+	config idempotency.Config
+}
+
+// NewIdempotencyServiceProvider creates a new IdempotencyServiceProvider.
+// The cache provider must be registered first.
+func NewIdempotencyServiceProvider(config idempotency.Config) *IdempotencyServiceProvider {
+	return &IdempotencyServiceProvider{config: config}
+}
+
+// Register binds the idempotency store into the container.
+func (p *IdempotencyServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("idempotency", func() (interface{}, error) {
+		return idempotency.NewStore(p.config, cache.NewInjectable(app).Cache()), nil
+	})
+	return nil
+}
+
+// Boot boots the service provider.
+func (p *IdempotencyServiceProvider) Boot(app foundation.Application) error {
+	// Nothing to boot for idempotency
+	return nil
+}
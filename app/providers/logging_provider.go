@@ -0,0 +1,33 @@
+package providers
+
+import (
+	"skeleton/app/support/logging"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	database "github.com/donnigundala/dg-database"
+)
+
+// LoggingServiceProvider routes database logs through the application logger.
+// The logger itself is configured during bootstrap, before providers are registered.
+type LoggingServiceProvider struct {
+	config logging.Config
+}
+
+// NewLoggingServiceProvider creates a new LoggingServiceProvider.
+// The database provider must be registered first.
+func NewLoggingServiceProvider(config logging.Config) *LoggingServiceProvider {
+	return &LoggingServiceProvider{config: config}
+}
+
+// Register registers the service provider.
+func (p *LoggingServiceProvider) Register(app foundation.Application) error {
+	// Nothing to register; see bootstrap.Application.setupLogger
+	return nil
+}
+
+// Boot replaces the GORM logger with the "db" component logger.
+func (p *LoggingServiceProvider) Boot(app foundation.Application) error {
+	db := database.MustResolve(app).DB()
+	db.Logger = logging.NewGormLogger(logging.Component("db"), p.config.SlowQueryThreshold)
+	return nil
+}
@@ -0,0 +1,37 @@
+package providers
+
+import (
+	"skeleton/app/support/metrics"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	database "github.com/donnigundala/dg-database"
+)
+
+// MetricsServiceProvider registers the Prometheus collectors.
+type MetricsServiceProvider struct {
+	config metrics.Config
+}
+
+// NewMetricsServiceProvider creates a new MetricsServiceProvider.
+// The database provider must be registered first so queries can be instrumented.
+func NewMetricsServiceProvider(config metrics.Config) *MetricsServiceProvider {
+	return &MetricsServiceProvider{config: config}
+}
+
+// Register binds the metrics collectors into the container.
+func (p *MetricsServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("metrics", func() (interface{}, error) {
+		return metrics.New(p.config), nil
+	})
+	return nil
+}
+
+// Boot instruments the database connection when metrics are enabled.
+func (p *MetricsServiceProvider) Boot(app foundation.Application) error {
+	m := metrics.MustResolve(app)
+	if !m.Enabled() {
+		return nil
+	}
+
+	return database.MustResolve(app).DB().Use(m.GormPlugin())
+}
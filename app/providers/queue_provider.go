@@ -3,6 +3,9 @@ package providers
 import (
 	"fmt"
 
+	"skeleton/app/support/health"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
 	queue "github.com/donnigundala/dg-queue"
 	"github.com/donnigundala/dg-queue/drivers/memory"
 	"github.com/donnigundala/dg-queue/drivers/redis"
@@ -19,11 +22,29 @@ import (
 //
 // For advanced customization, you can still create a custom provider
 // using queue.New() and queue.SetDriver() directly.
-func NewQueueServiceProvider(config queue.Config) *queue.QueueServiceProvider {
-	return &queue.QueueServiceProvider{
+func NewQueueServiceProvider(config queue.Config) *QueueServiceProvider {
+	return &QueueServiceProvider{QueueServiceProvider: &queue.QueueServiceProvider{
 		Config:        config,
 		DriverFactory: createQueueDriver,
+	}}
+}
+
+// QueueServiceProvider is the dg-queue provider, registering the readiness
+// check of the Redis backend once it has booted.
+type QueueServiceProvider struct {
+	*queue.QueueServiceProvider
+}
+
+// Boot boots the queue and registers the "queue" check when it runs on Redis.
+func (p *QueueServiceProvider) Boot(app foundation.Application) error {
+	if err := p.QueueServiceProvider.Boot(app); err != nil {
+		return err
 	}
+
+	if p.Config.Driver == "redis" {
+		health.MustResolve(app).Register(redisCheck("queue", queueRedisOptions(p.Config)))
+	}
+	return nil
 }
 
 // createQueueDriver creates a queue driver based on configuration.
@@ -35,32 +56,36 @@ func createQueueDriver(cfg queue.Config) (queue.Driver, error) {
 		return memory.NewDriver(), nil
 
 	case "redis":
-		// Parse Redis options from config
-		redisOptions := &goredis.Options{
-			Addr:     "localhost:6379", // Default
-			Password: "",               // Default
-			DB:       0,                // Default
-		}
-
-		// Override with config options if provided
-		if addr, ok := cfg.Options["addr"].(string); ok {
-			redisOptions.Addr = addr
-		}
-		if password, ok := cfg.Options["password"].(string); ok {
-			redisOptions.Password = password
-		}
-		if db, ok := cfg.Options["db"].(int); ok {
-			redisOptions.DB = db
-		}
-
 		prefix := cfg.Prefix
 		if prefix == "" {
 			prefix = "queue"
 		}
 
-		return redis.NewDriver(prefix, redisOptions)
+		return redis.NewDriver(prefix, queueRedisOptions(cfg))
 
 	default:
 		return nil, fmt.Errorf("unsupported queue driver: %s", cfg.Driver)
 	}
 }
+
+// queueRedisOptions parses the Redis connection options of the queue config.
+func queueRedisOptions(cfg queue.Config) *goredis.Options {
+	redisOptions := &goredis.Options{
+		Addr:     "localhost:6379", // Default
+		Password: "",               // Default
+		DB:       0,                // Default
+	}
+
+	// Override with config options if provided
+	if addr, ok := cfg.Options["addr"].(string); ok {
+		redisOptions.Addr = addr
+	}
+	if password, ok := cfg.Options["password"].(string); ok {
+		redisOptions.Password = password
+	}
+	if db, ok := cfg.Options["db"].(int); ok {
+		redisOptions.DB = db
+	}
+
+	return redisOptions
+}
@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"fmt"
+	"log/slog"
+
+	"skeleton/app/support/ratelimit"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"github.com/donnigundala/dg-core/logging"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RateLimitServiceProvider registers the named rate limiters.
+type RateLimitServiceProvider struct {
+	config ratelimit.Config
+}
+
+// NewRateLimitServiceProvider creates a new RateLimitServiceProvider.
+func NewRateLimitServiceProvider(config ratelimit.Config) *RateLimitServiceProvider {
+	return &RateLimitServiceProvider{config: config}
+}
+
+// Register binds the rate limiter manager into the container.
+func (p *RateLimitServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("rateLimiter", func() (interface{}, error) {
+		store, err := p.createStore(app)
+		if err != nil {
+			return nil, err
+		}
+		return ratelimit.NewManager(p.config, store)
+	})
+	return nil
+}
+
+// Boot boots the service provider.
+func (p *RateLimitServiceProvider) Boot(app foundation.Application) error {
+	// Nothing to boot for rate limiting
+	return nil
+}
+
+// createStore creates the limiter store based on configuration.
+func (p *RateLimitServiceProvider) createStore(app foundation.Application) (ratelimit.Store, error) {
+	switch p.config.Store {
+	case "memory":
+		return ratelimit.NewMemoryStore(), nil
+
+	case "", "redis":
+		// Shared Redis store with an in-memory fallback when it is unreachable
+		options, ok := redisOptions()
+		if !ok {
+			return nil, fmt.Errorf("the redis rate limit store requires a Redis host in config/redis.yaml")
+		}
+		return ratelimit.NewFallbackStore(
+			ratelimit.NewRedisStore(goredis.NewClient(options)),
+			ratelimit.NewMemoryStore(),
+			resolveLogger(app),
+		), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported rate limit store: %s", p.config.Store)
+	}
+}
+
+// resolveLogger resolves the application logger, falling back to the default logger.
+func resolveLogger(app foundation.Application) *slog.Logger {
+	if instance, err := app.Make("logger"); err == nil {
+		if logger, ok := instance.(*logging.Logger); ok {
+			return logger.Underlying()
+		}
+	}
+	return slog.Default()
+}
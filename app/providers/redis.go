@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"fmt"
+
+	"skeleton/app/support/health"
+
+	"github.com/donnigundala/dg-core/config"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redisOptions builds go-redis options from redis.yaml.
+// It returns false when no Redis host is configured.
+func redisOptions() (*goredis.Options, bool) {
+	var redisConfig struct {
+		Host     string `mapstructure:"host"`
+		Port     int    `mapstructure:"port"`
+		Password string `mapstructure:"password"`
+		Database int    `mapstructure:"database"`
+	}
+	if err := config.Inject("redis", &redisConfig); err != nil || redisConfig.Host == "" {
+		return nil, false
+	}
+
+	port := redisConfig.Port
+	if port == 0 {
+		port = 6379
+	}
+
+	return &goredis.Options{
+		Addr:     fmt.Sprintf("%s:%d", redisConfig.Host, port),
+		Password: redisConfig.Password,
+		DB:       redisConfig.Database,
+	}, true
+}
+
+// redisCheck creates a readiness check pinging Redis on its own client,
+// which the health registry closes on shutdown.
+func redisCheck(name string, options *goredis.Options) health.Check {
+	client := goredis.NewClient(options)
+	return health.Check{Name: name, Critical: true, Run: health.Redis(client), Close: client.Close}
+}
@@ -2,22 +2,25 @@ package providers
 
 import (
 	"skeleton/app/repositories"
+	"skeleton/app/support/repository"
 
 	"github.com/donnigundala/dg-core/contracts/foundation"
 )
 
 // RepositoryServiceProvider registers all application repositories.
-type RepositoryServiceProvider struct{}
+type RepositoryServiceProvider struct {
+	config repository.Config
+}
 
 // NewRepositoryServiceProvider creates a new RepositoryServiceProvider.
-func NewRepositoryServiceProvider() *RepositoryServiceProvider {
-	return &RepositoryServiceProvider{}
+func NewRepositoryServiceProvider(config repository.Config) *RepositoryServiceProvider {
+	return &RepositoryServiceProvider{config: config}
 }
 
 // Register binds repositories into the container using auto-discovery.
 func (p *RepositoryServiceProvider) Register(app foundation.Application) error {
 	// Auto-discover and register all repositories
-	return repositories.LoadAll(app)
+	return repositories.LoadAll(app, p.config)
 }
 
 // Boot boots the service provider.
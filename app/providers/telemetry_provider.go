@@ -0,0 +1,39 @@
+package providers
+
+import (
+	"context"
+
+	"skeleton/app/support/telemetry"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	database "github.com/donnigundala/dg-database"
+)
+
+// TelemetryServiceProvider configures OpenTelemetry tracing.
+type TelemetryServiceProvider struct {
+	config telemetry.Config
+}
+
+// NewTelemetryServiceProvider creates a new TelemetryServiceProvider.
+// The database provider must be registered first so queries can be traced.
+func NewTelemetryServiceProvider(config telemetry.Config) *TelemetryServiceProvider {
+	return &TelemetryServiceProvider{config: config}
+}
+
+// Register binds the telemetry instance into the container.
+func (p *TelemetryServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("telemetry", func() (interface{}, error) {
+		return telemetry.New(context.Background(), p.config)
+	})
+	return nil
+}
+
+// Boot installs the global tracer provider and traces database queries.
+func (p *TelemetryServiceProvider) Boot(app foundation.Application) error {
+	t := telemetry.MustResolve(app)
+	if !t.Enabled() {
+		return nil
+	}
+
+	return database.MustResolve(app).DB().Use(telemetry.GormPlugin())
+}
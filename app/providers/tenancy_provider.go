@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"skeleton/app/repositories"
+	"skeleton/app/support/tenancy"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	database "github.com/donnigundala/dg-database"
+)
+
+// TenancyServiceProvider registers the tenant registry and scopes database access to the tenant schema.
+type TenancyServiceProvider struct {
+	config tenancy.Config
+}
+
+// NewTenancyServiceProvider creates a new TenancyServiceProvider.
+// The database and repository providers must be registered first.
+func NewTenancyServiceProvider(config tenancy.Config) *TenancyServiceProvider {
+	return &TenancyServiceProvider{config: config}
+}
+
+// Register binds the tenant registry into the container when tenancy is enabled.
+func (p *TenancyServiceProvider) Register(app foundation.Application) error {
+	if !p.config.Enabled {
+		return nil
+	}
+
+	app.Singleton("tenancy", func() (interface{}, error) {
+		return tenancy.NewRegistry(p.config, repositories.MustResolveTenantRepository(app)), nil
+	})
+	return nil
+}
+
+// Boot installs the GORM plugin qualifying tenant tables with the tenant schema.
+func (p *TenancyServiceProvider) Boot(app foundation.Application) error {
+	if !p.config.Enabled {
+		return nil
+	}
+
+	registry := tenancy.MustResolve(app)
+	return database.MustResolve(app).DB().Use(tenancy.NewSchemaPlugin(registry.Config().SharedTables))
+}
@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"context"
+
+	"skeleton/app/jobs"
+	"skeleton/app/support/logging"
+	"skeleton/app/support/metrics"
+	"skeleton/app/support/tenancy"
+	"skeleton/app/support/worker"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	queue "github.com/donnigundala/dg-queue"
+)
+
+// WorkerServiceProvider registers the job dispatcher and the queue job handlers.
+type WorkerServiceProvider struct {
+	config queue.Config
+}
+
+// NewWorkerServiceProvider creates a new WorkerServiceProvider.
+// The queue and metrics providers must be registered first.
+func NewWorkerServiceProvider(config queue.Config) *WorkerServiceProvider {
+	return &WorkerServiceProvider{config: config}
+}
+
+// Register binds the dispatcher and handler registry into the container.
+func (p *WorkerServiceProvider) Register(app foundation.Application) error {
+	app.Singleton("jobDispatcher", func() (interface{}, error) {
+		m := metrics.MustResolve(app)
+
+		dispatcher := worker.NewDispatcher(queue.MustResolve(app))
+		dispatcher.Enrich(worker.RequestIDEnricher())
+		dispatcher.Enrich(tenancy.JobEnricher())
+		dispatcher.Observe(func(ctx context.Context, name string, err error) {
+			m.JobDispatched(name, err)
+		})
+		return dispatcher, nil
+	})
+
+	app.Singleton("jobRegistry", func() (interface{}, error) {
+		m := metrics.MustResolve(app)
+
+		registry := jobs.LoadQueueHandlers(logging.Component("queue"))
+		registry.Use(worker.ContextLogger())
+		if tenants := tenancy.Resolve(app); tenants != nil {
+			registry.Use(tenancy.JobMiddleware(tenants))
+		}
+		registry.Use(func(name string, next worker.HandlerFunc) worker.HandlerFunc {
+			return func(ctx context.Context, payload worker.Payload) error {
+				return m.ObserveJob(name, func() error { return next(ctx, payload) })
+			}
+		})
+		return registry, nil
+	})
+
+	return nil
+}
+
+// Boot starts a queue worker for each handler. It fails when a handler can't be
+// attached, rather than booting a process that silently never runs its jobs.
+func (p *WorkerServiceProvider) Boot(app foundation.Application) error {
+	instance, err := app.Make("jobRegistry")
+	if err != nil {
+		return err
+	}
+	registry := instance.(*worker.Registry)
+
+	concurrency := p.config.Workers
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return registry.ProcessAll(queue.MustResolve(app), concurrency)
+}
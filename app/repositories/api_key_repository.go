@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"skeleton/app/models"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines the interface for API key data access.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByID(ctx context.Context, id uint) (*models.APIKey, error)
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	GetAll(ctx context.Context) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uint, at time.Time) error
+}
+
+// apiKeyRepository implements APIKeyRepository.
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository.
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create creates a new API key.
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetByID retrieves an API key by ID.
+func (r *apiKeyRepository) GetByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).First(&key, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetByPrefix retrieves an API key by its public prefix.
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAll retrieves all API keys, newest first.
+func (r *apiKeyRepository) GetAll(ctx context.Context) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	err := r.db.WithContext(ctx).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke marks an API key as revoked.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records when an API key was last used.
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
+
+// MustResolveAPIKeyRepository resolves the API key repository from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolveAPIKeyRepository(app foundation.Application) APIKeyRepository {
+	repo, err := app.Make("apiKeyRepository")
+	if err != nil {
+		panic("failed to resolve API key repository: " + err.Error())
+	}
+	return repo.(APIKeyRepository)
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"skeleton/app/models"
 	"skeleton/app/support/repository"
 
 	"github.com/donnigundala/dg-core/contracts/foundation"
@@ -9,7 +10,7 @@ import (
 
 // LoadAll loads and registers all repositories
 // Add new repositories here to make them discoverable
-func LoadAll(app foundation.Application) error {
+func LoadAll(app foundation.Application, config repository.Config) error {
 	// Resolve database connection once for all repositories
 	// Use type-safe helper
 	dbManager := database.MustResolve(app)
@@ -25,8 +26,15 @@ func LoadAll(app foundation.Application) error {
 	// 1. Create the repository interface and implementation in this package
 	// 2. Add a MustResolveX helper in the repository file
 	// 3. Register it here using NewBaseRepository
-	registry.Register(repository.NewBaseRepository("userRepository", func(app foundation.Application) (interface{}, error) {
+	// 4. Optionally wrap it in repository.Cached and enable it in config/repository.yaml
+	registry.Register(repository.Cached(repository.NewBaseRepository("userRepository", func(app foundation.Application) (interface{}, error) {
 		return NewUserRepository(db), nil
+	}), config, func(user *models.User) uint { return user.ID }))
+	registry.Register(repository.NewBaseRepository("apiKeyRepository", func(app foundation.Application) (interface{}, error) {
+		return NewAPIKeyRepository(db), nil
+	}))
+	registry.Register(repository.NewBaseRepository("tenantRepository", func(app foundation.Application) (interface{}, error) {
+		return NewTenantRepository(db), nil
 	}))
 
 	return registry.RegisterAll(app)
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"skeleton/app/models"
+	"skeleton/app/support/tenancy"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"gorm.io/gorm"
+)
+
+// TenantRepository defines the interface for tenant registry data access.
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *models.Tenant) error
+	FindBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	FindByDomain(ctx context.Context, domain string) (*models.Tenant, error)
+	GetActive(ctx context.Context) ([]*models.Tenant, error)
+}
+
+// tenantRepository implements TenantRepository.
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+// NewTenantRepository creates a new tenant repository.
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+// Create creates the tenant's schema and registers the tenant.
+// Tables are created in the schema by running the tenant migrations (cmd/migrate -tenants).
+func (r *tenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
+	if !tenancy.ValidSchema(tenant.Schema) {
+		return fmt.Errorf("invalid tenant schema name %q", tenant.Schema)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The name is validated above, so it is safe to interpolate
+		if err := tx.Exec(fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS "%s"`, tenant.Schema)).Error; err != nil {
+			return err
+		}
+		return tx.Create(tenant).Error
+	})
+}
+
+// FindBySlug retrieves a tenant by slug.
+func (r *tenantRepository) FindBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// FindByDomain retrieves a tenant by custom domain.
+func (r *tenantRepository) FindByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := r.db.WithContext(ctx).Where("domain = ?", domain).First(&tenant).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// GetActive retrieves all active tenants.
+func (r *tenantRepository) GetActive(ctx context.Context) ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	err := r.db.WithContext(ctx).Where("active = ?", true).Order("id").Find(&tenants).Error
+	return tenants, err
+}
+
+// MustResolveTenantRepository resolves the tenant repository from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolveTenantRepository(app foundation.Application) TenantRepository {
+	repo, err := app.Make("tenantRepository")
+	if err != nil {
+		panic("failed to resolve tenant repository: " + err.Error())
+	}
+	return repo.(TenantRepository)
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"skeleton/app/models"
+	"skeleton/app/support/logging"
 
 	"github.com/donnigundala/dg-core/contracts/foundation"
 	"gorm.io/gorm"
@@ -29,7 +30,12 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 
 // Create creates a new user.
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Debug("User created", "user_id", user.ID)
+	return nil
 }
 
 // GetByID retrieves a user by ID.
@@ -69,7 +75,13 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 
 // Delete deletes a user by ID.
 func (r *userRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
+	result := r.db.WithContext(ctx).Delete(&models.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	logging.FromContext(ctx).Debug("User deleted", "user_id", id, "rows_affected", result.RowsAffected)
+	return nil
 }
 
 // MustResolveUserRepository resolves the user repository from the container.
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"skeleton/app/models"
+	"skeleton/app/repositories"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// APIKeyPrefix is prepended to every generated key so keys are recognizable in headers and logs.
+const APIKeyPrefix = "sk_"
+
+// apiKeyPrefixBytes is the length of the random public prefix, which is unique per key:
+// 8 bytes (16 hex characters) make a collision on creation negligible.
+const apiKeyPrefixBytes = 8
+
+// ErrInvalidAPIKey is returned when a key is malformed, unknown, revoked or expired.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService defines the interface for API key management and authentication.
+type APIKeyService interface {
+	// Create issues a new key and returns the plain key, which is never stored.
+	// A key with a tenant ID is bound to that tenant; without one it only reaches the shared schema.
+	Create(ctx context.Context, name string, scopes []string, expiresAt *time.Time, tenantID *uint) (string, *models.APIKey, error)
+	Authenticate(ctx context.Context, plainKey string) (*models.APIKey, error)
+	GetAll(ctx context.Context) ([]*models.APIKey, error)
+	Revoke(ctx context.Context, id uint) error
+}
+
+// apiKeyService implements APIKeyService.
+type apiKeyService struct {
+	repo repositories.APIKeyRepository
+
+	// lastUsed throttles last_used_at writes to one per key per interval.
+	mu            sync.Mutex
+	lastUsed      map[uint]time.Time
+	touchInterval time.Duration
+}
+
+// NewAPIKeyService creates a new API key service.
+func NewAPIKeyService(repo repositories.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		repo:          repo,
+		lastUsed:      make(map[uint]time.Time),
+		touchInterval: time.Minute,
+	}
+}
+
+// Create generates a new key in the form sk_<prefix>_<secret>.
+func (s *apiKeyService) Create(ctx context.Context, name string, scopes []string, expiresAt *time.Time, tenantID *uint) (string, *models.APIKey, error) {
+	prefixBytes, err := randomBytes(apiKeyPrefixBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secretBytes, err := randomBytes(32)
+	if err != nil {
+		return "", nil, err
+	}
+	prefix := hex.EncodeToString(prefixBytes)
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	key := &models.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashSecret(secret),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: expiresAt,
+		TenantID:  tenantID,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	return APIKeyPrefix + prefix + "_" + secret, key, nil
+}
+
+// Authenticate validates a plain key and returns the matching active key.
+func (s *apiKeyService) Authenticate(ctx context.Context, plainKey string) (*models.APIKey, error) {
+	prefix, secret, ok := splitAPIKey(plainKey)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if s.shouldTouch(key.ID, now) {
+		_ = s.repo.TouchLastUsed(ctx, key.ID, now)
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// GetAll retrieves all API keys.
+func (s *apiKeyService) GetAll(ctx context.Context) ([]*models.APIKey, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// Revoke revokes an API key immediately.
+func (s *apiKeyService) Revoke(ctx context.Context, id uint) error {
+	return s.repo.Revoke(ctx, id, time.Now())
+}
+
+func (s *apiKeyService) shouldTouch(id uint, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.lastUsed[id]; ok && now.Sub(last) < s.touchInterval {
+		return false
+	}
+	s.lastUsed[id] = now
+	return true
+}
+
+// IsAPIKey reports whether a credential looks like a key issued by this service.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// splitAPIKey splits sk_<prefix>_<secret> into its prefix and secret.
+func splitAPIKey(plainKey string) (string, string, bool) {
+	if !IsAPIKey(plainKey) {
+		return "", "", false
+	}
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(plainKey, APIKeyPrefix), "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", "", false
+	}
+	return prefix, secret, true
+}
+
+// hashSecret hashes a key secret. Secrets are high-entropy random values,
+// so a fast hash is sufficient.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomBytes returns n cryptographically secure random bytes.
+func randomBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// MustResolveAPIKeyService resolves the API key service from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolveAPIKeyService(app foundation.Application) APIKeyService {
+	svc, err := app.Make("apiKeyService")
+	if err != nil {
+		panic("failed to resolve API key service: " + err.Error())
+	}
+	return svc.(APIKeyService)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"skeleton/app/models"
+
+	"gorm.io/gorm"
+)
+
+// memoryAPIKeyRepository keeps API keys in memory, enforcing the unique prefix
+type memoryAPIKeyRepository struct {
+	keys    []*models.APIKey
+	touches int
+}
+
+func (r *memoryAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	for _, existing := range r.keys {
+		if existing.Prefix == key.Prefix {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	key.ID = uint(len(r.keys) + 1)
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) GetByID(ctx context.Context, id uint) (*models.APIKey, error) {
+	for _, key := range r.keys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			return key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAPIKeyRepository) GetAll(ctx context.Context) ([]*models.APIKey, error) {
+	return r.keys, nil
+}
+
+func (r *memoryAPIKeyRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	key, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	key.RevokedAt = &at
+	return nil
+}
+
+func (r *memoryAPIKeyRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	r.touches++
+	return nil
+}
+
+func TestAPIKeyServiceCreate(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryAPIKeyRepository{}
+	service := NewAPIKeyService(repo)
+
+	tenantID := uint(7)
+	plain, key, err := service.Create(ctx, "ci", []string{"users:read", "users:write"}, nil, &tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefix, secret, ok := splitAPIKey(plain)
+	if !ok || !IsAPIKey(plain) {
+		t.Fatalf("got key %q, want sk_<prefix>_<secret>", plain)
+	}
+	if prefix != key.Prefix || len(prefix) != 2*apiKeyPrefixBytes {
+		t.Errorf("got prefix %q stored as %q, want %d hex characters", prefix, key.Prefix, 2*apiKeyPrefixBytes)
+	}
+	// Only a hash of the secret is stored
+	if key.KeyHash != hashSecret(secret) || strings.Contains(key.KeyHash, secret) {
+		t.Errorf("got key hash %q for secret %q", key.KeyHash, secret)
+	}
+	if key.Scopes != "users:read users:write" || key.TenantID == nil || *key.TenantID != tenantID {
+		t.Errorf("got scopes %q and tenant %v", key.Scopes, key.TenantID)
+	}
+
+	// Every key gets its own prefix and secret
+	other, _, err := service.Create(ctx, "other", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherPrefix, otherSecret, _ := splitAPIKey(other); otherPrefix == prefix || otherSecret == secret {
+		t.Errorf("got %q after %q", other, plain)
+	}
+}
+
+func TestAPIKeyServiceAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		revoke    bool
+		// key returns the presented key from the issued one
+		key     func(plain string) string
+		wantErr bool
+	}{
+		{name: "valid key", key: func(plain string) string { return plain }},
+		{name: "not yet expired", expiresAt: &future, key: func(plain string) string { return plain }},
+		{name: "expired", expiresAt: &past, key: func(plain string) string { return plain }, wantErr: true},
+		{name: "revoked", revoke: true, key: func(plain string) string { return plain }, wantErr: true},
+		{name: "wrong secret", key: func(plain string) string { return plain[:len(plain)-1] + "x" }, wantErr: true},
+		{name: "unknown prefix", key: func(plain string) string {
+			_, secret, _ := splitAPIKey(plain)
+			return APIKeyPrefix + "0000000000000000_" + secret
+		}, wantErr: true},
+		{name: "no secret", key: func(plain string) string {
+			prefix, _, _ := splitAPIKey(plain)
+			return APIKeyPrefix + prefix + "_"
+		}, wantErr: true},
+		{name: "not an api key", key: func(plain string) string { return strings.TrimPrefix(plain, APIKeyPrefix) }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryAPIKeyRepository{}
+			service := NewAPIKeyService(repo)
+			plain, created, err := service.Create(ctx, "ci", nil, tt.expiresAt, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.revoke {
+				if err := service.Revoke(ctx, created.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			key, err := service.Authenticate(ctx, tt.key(plain))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAPIKey) {
+					t.Fatalf("got %v, %v, want ErrInvalidAPIKey", key, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.ID != created.ID || key.LastUsedAt == nil {
+				t.Errorf("got key %d used at %v, want key %d marked used", key.ID, key.LastUsedAt, created.ID)
+			}
+		})
+	}
+}
+
+func TestAPIKeyServiceTouchesLastUsedOncePerInterval(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryAPIKeyRepository{}
+	service := NewAPIKeyService(repo)
+	plain, _, err := service.Create(ctx, "ci", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.Authenticate(ctx, plain); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.touches != 1 {
+		t.Errorf("got %d last_used_at writes, want 1", repo.touches)
+	}
+}
@@ -3,9 +3,9 @@ package services
 import (
 	"skeleton/app/repositories"
 	"skeleton/app/support/service"
+	"skeleton/app/support/worker"
 
 	"github.com/donnigundala/dg-core/contracts/foundation"
-	queue "github.com/donnigundala/dg-queue"
 )
 
 // LoadAll loads and registers all services
@@ -26,9 +26,16 @@ func LoadAll(app foundation.Application) error {
 	registry.Register(service.NewBaseService("userService", func(app foundation.Application) (interface{}, error) {
 		// Resolve dependencies using type-safe helpers
 		userRepo := repositories.MustResolveUserRepository(app)
-		queueManager := queue.MustResolve(app)
+		dispatcher := worker.MustResolveDispatcher(app)
 
-		return NewUserService(userRepo, app, queueManager), nil
+		return NewUserService(userRepo, dispatcher), nil
+	}))
+
+	// Register API Key Service
+	registry.Register(service.NewBaseService("apiKeyService", func(app foundation.Application) (interface{}, error) {
+		apiKeyRepo := repositories.MustResolveAPIKeyRepository(app)
+
+		return NewAPIKeyService(apiKeyRepo), nil
 	}))
 
 	return registry.RegisterAll(app)
@@ -0,0 +1,7 @@
+package services
+
+import "go.opentelemetry.io/otel"
+
+// tracer creates service-layer spans. It uses the global tracer provider
+// configured by the telemetry provider and is a no-op when tracing is disabled.
+var tracer = otel.Tracer("skeleton/app/services")
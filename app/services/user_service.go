@@ -2,15 +2,13 @@ package services
 
 import (
 	"context"
-	"fmt"
-	"time"
 
 	"skeleton/app/models"
 	"skeleton/app/repositories"
+	"skeleton/app/support/logging"
+	"skeleton/app/support/worker"
 
-	cache "github.com/donnigundala/dg-cache"
 	"github.com/donnigundala/dg-core/contracts/foundation"
-	queue "github.com/donnigundala/dg-queue"
 )
 
 // UserService defines the interface for user business logic.
@@ -23,90 +21,72 @@ type UserService interface {
 }
 
 // userService implements UserService.
+// Reads are cached and writes invalidated by the repository decorator (config/repository.yaml).
 type userService struct {
-	repo   repositories.UserRepository
-	inject *cache.Injectable
-	queue  queue.Queue
+	repo       repositories.UserRepository
+	dispatcher *worker.Dispatcher
 }
 
 // NewUserService creates a new user service.
-func NewUserService(repo repositories.UserRepository, app foundation.Application, queueManager queue.Queue) UserService {
+func NewUserService(repo repositories.UserRepository, dispatcher *worker.Dispatcher) UserService {
 	return &userService{
-		repo:   repo,
-		inject: cache.NewInjectable(app),
-		queue:  queueManager,
+		repo:       repo,
+		dispatcher: dispatcher,
 	}
 }
 
 // Create creates a new user and dispatches a welcome email job.
 func (s *userService) Create(ctx context.Context, user *models.User) error {
+	ctx, span := tracer.Start(ctx, "UserService.Create")
+	defer span.End()
+
 	if err := s.repo.Create(ctx, user); err != nil {
 		return err
 	}
 
 	// Dispatch job to send a welcome email.
-	_, err := s.queue.Dispatch("send-welcome-email", map[string]interface{}{
+	err := s.dispatcher.Dispatch(ctx, "send-welcome-email", worker.Payload{
 		"user_id": user.ID,
 		"email":   user.Email,
 	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to dispatch welcome email", "user_id", user.ID, "error", err)
+		return err
+	}
 
-	return err
+	return nil
 }
 
-// GetByID retrieves a user by ID with caching.
+// GetByID retrieves a user by ID.
 func (s *userService) GetByID(ctx context.Context, id uint) (*models.User, error) {
-	cacheKey := fmt.Sprintf("user:%d", id)
-
-	// Try cache first
-	var cachedUser models.User
-	err := s.inject.Cache().GetAs(ctx, cacheKey, &cachedUser)
-	if err == nil {
-		return &cachedUser, nil
-	}
-
-	// Cache miss - get from database
-	user, err := s.repo.GetByID(ctx, id)
-	if err != nil {
-		return nil, err
-	}
-
-	// Store in cache (5 minutes TTL)
-	_ = s.inject.Cache().Put(ctx, cacheKey, user, 5*time.Minute)
+	ctx, span := tracer.Start(ctx, "UserService.GetByID")
+	defer span.End()
 
-	return user, nil
+	return s.repo.GetByID(ctx, id)
 }
 
 // GetAll retrieves all users with pagination.
 func (s *userService) GetAll(ctx context.Context, page, perPage int) ([]*models.User, int64, error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetAll")
+	defer span.End()
+
 	return s.repo.GetAll(ctx, page, perPage)
 }
 
-// Update updates a user and invalidates cache.
+// Update updates a user.
 func (s *userService) Update(ctx context.Context, user *models.User) error {
-	err := s.repo.Update(ctx, user)
-	if err != nil {
-		return err
-	}
-
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("user:%d", user.ID)
-	_ = s.inject.Cache().Forget(ctx, cacheKey)
+	ctx, span := tracer.Start(ctx, "UserService.Update")
+	defer span.End()
 
-	return nil
+	return s.repo.Update(ctx, user)
 }
 
-// Delete deletes a user and invalidates cache.
+// Delete deletes a user.
 func (s *userService) Delete(ctx context.Context, id uint) error {
-	err := s.repo.Delete(ctx, id)
-	if err != nil {
-		return err
-	}
+	ctx, span := tracer.Start(ctx, "UserService.Delete")
+	defer span.End()
 
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("user:%d", id)
-	_ = s.inject.Cache().Forget(ctx, cacheKey)
-
-	return nil
+	return s.repo.Delete(ctx, id)
 }
 
 // MustResolveUserService resolves the user service from the container.
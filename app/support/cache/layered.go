@@ -0,0 +1,278 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"skeleton/app/support/logging"
+	"skeleton/app/support/tenancy"
+
+	cache "github.com/donnigundala/dg-cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// LayeredOptions configures the in-process tier of the layered driver
+type LayeredOptions struct {
+	// Size is the maximum number of L1 entries
+	Size int
+	// TTL bounds how long an entry is served from L1, and so how stale it can be
+	// if an invalidation message is lost
+	TTL time.Duration
+	// Prefixes limits L1 to keys starting with one of them (after the store prefix and the tenant scope)
+	// Empty caches every key in L1
+	Prefixes []string
+	// Channel is the Redis pub/sub channel carrying invalidations
+	Channel string
+}
+
+// LayeredOptionsFrom reads the options of a "layered" store from cache.yaml
+func LayeredOptionsFrom(options map[string]interface{}) LayeredOptions {
+	opts := LayeredOptions{
+		Size:    10000,
+		TTL:     30 * time.Second,
+		Channel: "cache:invalidate",
+	}
+
+	if size, ok := toInt(options["l1_size"]); ok && size > 0 {
+		opts.Size = size
+	}
+	if ttl, ok := options["l1_ttl"].(string); ok {
+		if d, err := time.ParseDuration(ttl); err == nil && d > 0 {
+			opts.TTL = d
+		}
+	}
+	if prefixes, ok := options["l1_prefixes"].([]interface{}); ok {
+		for _, prefix := range prefixes {
+			opts.Prefixes = append(opts.Prefixes, fmt.Sprint(prefix))
+		}
+	}
+	if channel, ok := options["channel"].(string); ok && channel != "" {
+		opts.Channel = channel
+	}
+	return opts
+}
+
+// invalidation is broadcast to every instance when a key changes
+type invalidation struct {
+	Origin string `json:"origin"`
+	Key    string `json:"key,omitempty"`
+	Flush  bool   `json:"flush,omitempty"`
+}
+
+// LayeredDriver reads through a bounded in-process LRU (L1) to a shared Redis driver (L2)
+//
+// Writes go to L2 and evict the key from L1 on every instance through Redis pub/sub
+// L1 is only filled from L2 reads, so both tiers return values of the same shape
+// Every write method of cache.Driver is overridden to evict L1; the embedded L2
+// driver only serves the reads not overridden here, which bypass L1
+type LayeredDriver struct {
+	cache.Driver
+
+	l1        *LRU
+	opts      LayeredOptions
+	keyPrefix string
+	client    *goredis.Client
+	pubsub    *goredis.PubSub
+	origin    string
+	logger    *slog.Logger
+}
+
+// NewLayeredDriver wraps the L2 driver and subscribes to invalidations
+// keyPrefix is the store prefix, stripped before matching keys against the L1 prefixes
+func NewLayeredDriver(l2 cache.Driver, client *goredis.Client, keyPrefix string, opts LayeredOptions) (*LayeredDriver, error) {
+	origin := make([]byte, 8)
+	if _, err := rand.Read(origin); err != nil {
+		return nil, err
+	}
+
+	d := &LayeredDriver{
+		Driver:    l2,
+		l1:        NewLRU(opts.Size),
+		opts:      opts,
+		keyPrefix: keyPrefix,
+		client:    client,
+		origin:    hex.EncodeToString(origin),
+		logger:    logging.Component("cache"),
+	}
+
+	d.pubsub = client.Subscribe(context.Background(), opts.Channel)
+	go d.listen()
+
+	return d, nil
+}
+
+// Name returns the driver name
+func (d *LayeredDriver) Name() string {
+	return "layered"
+}
+
+// Get reads from L1, falling back to L2 and filling L1 on a hit
+func (d *LayeredDriver) Get(ctx context.Context, key string) (interface{}, error) {
+	if !d.cacheable(key) {
+		return d.Driver.Get(ctx, key)
+	}
+
+	if value, ok := d.l1.Get(key); ok {
+		return value, nil
+	}
+
+	value, err := d.Driver.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if value != nil {
+		d.l1.Set(key, value, d.opts.TTL)
+	}
+	return value, nil
+}
+
+// Put writes to L2 and evicts the key from L1 on every instance
+func (d *LayeredDriver) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := d.Driver.Put(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	d.invalidate(ctx, invalidation{Key: key})
+	return nil
+}
+
+// Forever writes to L2 without expiry and evicts the key from L1 on every instance
+func (d *LayeredDriver) Forever(ctx context.Context, key string, value interface{}) error {
+	if err := d.Driver.Forever(ctx, key, value); err != nil {
+		return err
+	}
+	d.invalidate(ctx, invalidation{Key: key})
+	return nil
+}
+
+// PutMany writes the items to L2 and evicts their keys from L1 on every instance
+func (d *LayeredDriver) PutMany(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	err := d.Driver.PutMany(ctx, items, ttl)
+	// A failed batch may have written some of the items
+	for key := range items {
+		d.invalidate(ctx, invalidation{Key: key})
+	}
+	return err
+}
+
+// Increment increments the counter in L2 and evicts it from L1 on every instance
+func (d *LayeredDriver) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	n, err := d.Driver.Increment(ctx, key, value)
+	if err != nil {
+		return n, err
+	}
+	d.invalidate(ctx, invalidation{Key: key})
+	return n, nil
+}
+
+// Decrement decrements the counter in L2 and evicts it from L1 on every instance
+func (d *LayeredDriver) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	n, err := d.Driver.Decrement(ctx, key, value)
+	if err != nil {
+		return n, err
+	}
+	d.invalidate(ctx, invalidation{Key: key})
+	return n, nil
+}
+
+// Forget deletes the key from L2 and from L1 on every instance
+func (d *LayeredDriver) Forget(ctx context.Context, key string) error {
+	if err := d.Driver.Forget(ctx, key); err != nil {
+		return err
+	}
+	d.invalidate(ctx, invalidation{Key: key})
+	return nil
+}
+
+// Flush clears L2 and L1 on every instance
+func (d *LayeredDriver) Flush(ctx context.Context) error {
+	if err := d.Driver.Flush(ctx); err != nil {
+		return err
+	}
+	d.invalidate(ctx, invalidation{Flush: true})
+	return nil
+}
+
+// Close stops listening for invalidations and closes L2
+func (d *LayeredDriver) Close() error {
+	_ = d.pubsub.Close()
+	_ = d.client.Close()
+	return d.Driver.Close()
+}
+
+// cacheable reports whether the key is kept in L1
+func (d *LayeredDriver) cacheable(key string) bool {
+	if len(d.opts.Prefixes) == 0 {
+		return true
+	}
+	key = tenancy.UnscopedKey(strings.TrimPrefix(key, d.keyPrefix))
+	for _, prefix := range d.opts.Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidate evicts locally, then tells the other instances
+// A failed broadcast is logged; peers converge when their L1 entry expires
+func (d *LayeredDriver) invalidate(ctx context.Context, msg invalidation) {
+	if msg.Flush {
+		d.l1.Clear()
+	} else if d.cacheable(msg.Key) {
+		d.l1.Delete(msg.Key)
+	} else {
+		return
+	}
+
+	msg.Origin = d.origin
+	payload, _ := json.Marshal(msg)
+	if err := d.client.Publish(ctx, d.opts.Channel, payload).Err(); err != nil {
+		d.logger.WarnContext(ctx, "Failed to broadcast cache invalidation", "key", msg.Key, "error", err)
+	}
+}
+
+// listen applies invalidations published by other instances
+func (d *LayeredDriver) listen() {
+	for message := range d.pubsub.Channel() {
+		var msg invalidation
+		if err := json.Unmarshal([]byte(message.Payload), &msg); err != nil {
+			d.logger.Warn("Invalid cache invalidation message", "error", err)
+			continue
+		}
+		d.apply(msg)
+	}
+}
+
+// apply evicts what another instance invalidated; its own messages were applied when sent
+func (d *LayeredDriver) apply(msg invalidation) {
+	if msg.Origin == d.origin {
+		return
+	}
+	if msg.Flush {
+		d.l1.Clear()
+	} else {
+		d.l1.Delete(msg.Key)
+	}
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
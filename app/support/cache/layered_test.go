@@ -0,0 +1,233 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/donnigundala/dg-cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// fakeDriver is an L2 driver keeping values in memory, counting reads
+type fakeDriver struct {
+	cache.Driver
+
+	mu     sync.Mutex
+	values map[string]interface{}
+	reads  int
+}
+
+func newFakeDriver() *fakeDriver {
+	return &fakeDriver{values: map[string]interface{}{}}
+}
+
+func (d *fakeDriver) Get(ctx context.Context, key string) (interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reads++
+	return d.values[key], nil
+}
+
+func (d *fakeDriver) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.values[key] = value
+	return nil
+}
+
+func (d *fakeDriver) Forever(ctx context.Context, key string, value interface{}) error {
+	return d.Put(ctx, key, value, 0)
+}
+
+func (d *fakeDriver) PutMany(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	for key, value := range items {
+		_ = d.Put(ctx, key, value, ttl)
+	}
+	return nil
+}
+
+func (d *fakeDriver) Increment(ctx context.Context, key string, value int64) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n, _ := d.values[key].(int64)
+	d.values[key] = n + value
+	return n + value, nil
+}
+
+func (d *fakeDriver) Decrement(ctx context.Context, key string, value int64) (int64, error) {
+	return d.Increment(ctx, key, -value)
+}
+
+func (d *fakeDriver) Forget(ctx context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.values, key)
+	return nil
+}
+
+func (d *fakeDriver) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.values = map[string]interface{}{}
+	return nil
+}
+
+func (d *fakeDriver) Close() error {
+	return nil
+}
+
+// newTestLayeredDriver creates a layered driver over l2 whose Redis is unreachable:
+// broadcasts fail and are logged, local invalidation still happens
+func newTestLayeredDriver(t *testing.T, l2 cache.Driver, opts LayeredOptions) *LayeredDriver {
+	t.Helper()
+	client := goredis.NewClient(&goredis.Options{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond, MaxRetries: -1})
+	d, err := NewLayeredDriver(l2, client, "app:", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = d.Close() })
+	return d
+}
+
+func TestLayeredDriverInvalidation(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		write func(d *LayeredDriver) error
+		want  interface{}
+	}{
+		{name: "put", write: func(d *LayeredDriver) error { return d.Put(ctx, "app:key", "new", time.Minute) }, want: "new"},
+		{name: "forever", write: func(d *LayeredDriver) error { return d.Forever(ctx, "app:key", "new") }, want: "new"},
+		{name: "put many", write: func(d *LayeredDriver) error {
+			return d.PutMany(ctx, map[string]interface{}{"app:key": "new", "app:other": "x"}, time.Minute)
+		}, want: "new"},
+		{name: "increment", write: func(d *LayeredDriver) error {
+			_, err := d.Increment(ctx, "app:key", 2)
+			return err
+		}, want: int64(2)},
+		{name: "decrement", write: func(d *LayeredDriver) error {
+			_, err := d.Decrement(ctx, "app:key", 2)
+			return err
+		}, want: int64(-2)},
+		{name: "forget", write: func(d *LayeredDriver) error { return d.Forget(ctx, "app:key") }, want: nil},
+		{name: "flush", write: func(d *LayeredDriver) error { return d.Flush(ctx) }, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l2 := newFakeDriver()
+			d := newTestLayeredDriver(t, l2, LayeredOptions{Size: 10, TTL: time.Minute, Channel: "test"})
+
+			// Fill L1, then change the value in L2 behind its back
+			if err := d.Put(ctx, "app:key", int64(0), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := d.Get(ctx, "app:key"); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := d.l1.Get("app:key"); !ok {
+				t.Fatal("a read did not fill L1")
+			}
+
+			if err := tt.write(d); err != nil {
+				t.Fatal(err)
+			}
+			got, err := d.Get(ctx, "app:key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v after the write, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayeredDriverReads(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		prefixes  []string
+		key       string
+		wantReads int
+	}{
+		{name: "every key in L1", key: "app:user:1", wantReads: 1},
+		{name: "key matching an L1 prefix", prefixes: []string{"user:"}, key: "app:user:1", wantReads: 1},
+		{name: "key outside the L1 prefixes", prefixes: []string{"user:"}, key: "app:session:1", wantReads: 3},
+		{name: "tenant key matching an L1 prefix", prefixes: []string{"user:"}, key: "app:tenant:acme:user:1", wantReads: 1},
+		{name: "tenant key outside the L1 prefixes", prefixes: []string{"user:"}, key: "app:tenant:acme:session:1", wantReads: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l2 := newFakeDriver()
+			l2.values[tt.key] = "value"
+			d := newTestLayeredDriver(t, l2, LayeredOptions{Size: 10, TTL: time.Minute, Prefixes: tt.prefixes, Channel: "test"})
+
+			for i := 0; i < 3; i++ {
+				got, err := d.Get(ctx, tt.key)
+				if err != nil || got != "value" {
+					t.Fatalf("got %v, %v, want value", got, err)
+				}
+			}
+			if l2.reads != tt.wantReads {
+				t.Errorf("got %d L2 reads, want %d", l2.reads, tt.wantReads)
+			}
+		})
+	}
+}
+
+func TestLayeredDriverApply(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  invalidation
+		want []string
+	}{
+		{name: "key from another instance", msg: invalidation{Origin: "peer", Key: "a"}, want: []string{"b"}},
+		{name: "flush from another instance", msg: invalidation{Origin: "peer", Flush: true}},
+		{name: "own message", msg: invalidation{Key: "a"}, want: []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestLayeredDriver(t, newFakeDriver(), LayeredOptions{Size: 10, TTL: time.Minute, Channel: "test"})
+			d.l1.Set("a", 1, time.Minute)
+			d.l1.Set("b", 2, time.Minute)
+			if tt.msg.Origin == "" {
+				tt.msg.Origin = d.origin
+			}
+
+			d.apply(tt.msg)
+
+			if d.l1.Len() != len(tt.want) {
+				t.Errorf("got %d L1 entries, want %v", d.l1.Len(), tt.want)
+			}
+			for _, key := range tt.want {
+				if _, ok := d.l1.Get(key); !ok {
+					t.Errorf("%s was evicted", key)
+				}
+			}
+		})
+	}
+}
+
+func TestLayeredOptionsFrom(t *testing.T) {
+	opts := LayeredOptionsFrom(map[string]interface{}{
+		"l1_size":     "500",
+		"l1_ttl":      "10s",
+		"l1_prefixes": []interface{}{"user:", "product:"},
+		"channel":     "invalidate",
+	})
+	if opts.Size != 500 || opts.TTL != 10*time.Second || len(opts.Prefixes) != 2 || opts.Channel != "invalidate" {
+		t.Errorf("got %+v", opts)
+	}
+
+	defaults := LayeredOptionsFrom(map[string]interface{}{"l1_size": -1, "l1_ttl": "soon"})
+	if defaults.Size != 10000 || defaults.TTL != 30*time.Second || defaults.Channel != "cache:invalidate" {
+		t.Errorf("invalid options did not fall back to the defaults: %+v", defaults)
+	}
+}
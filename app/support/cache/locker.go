@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Locker acquires short-lived locks shared by all instances
+type Locker interface {
+	// Lock tries to acquire the lock without blocking
+	// The lock expires after ttl if unlock is never called
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(), acquired bool, err error)
+}
+
+// unlockScript deletes the lock only if it is still held by the caller's token
+var unlockScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker implements Locker with SET NX and a token-checked delete
+type RedisLocker struct {
+	client goredis.UniversalClient
+	prefix string
+}
+
+// NewRedisLocker creates a Redis-backed locker; prefix namespaces the lock keys
+func NewRedisLocker(client goredis.UniversalClient, prefix string) *RedisLocker {
+	return &RedisLocker{client: client, prefix: prefix}
+}
+
+// Lock acquires the lock if it is free
+func (l *RedisLocker) Lock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	token := hex.EncodeToString(buf)
+	key = l.prefix + key
+
+	acquired, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	unlock := func() {
+		// Release even if the caller's context was cancelled
+		_ = unlockScript.Run(context.WithoutCancel(ctx), l.client, []string{key}, token).Err()
+	}
+	return unlock, true, nil
+}
+
+// ResolveLocker resolves the distributed locker from the container
+// It returns nil when no locker is configured; Remember then coalesces loads per process only
+func ResolveLocker(app foundation.Application) Locker {
+	instance, err := app.Make("cacheLocker")
+	if err != nil {
+		return nil
+	}
+	locker, _ := instance.(Locker)
+	return locker
+}
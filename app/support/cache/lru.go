@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a bounded in-process cache with per-entry expiry
+// The least recently used entry is evicted when the cache is full
+type LRU struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// NewLRU creates an LRU holding at most size entries
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = 1
+	}
+	return &LRU{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// Get returns a live entry and marks it as recently used
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores an entry for the given duration, evicting the least recently used entry if full
+func (c *LRU) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete removes an entry
+func (c *LRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Clear removes all entries
+func (c *LRU) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[string]*list.Element, c.size)
+}
+
+// Len returns the number of entries, including expired ones not yet evicted
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
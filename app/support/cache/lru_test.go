@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		// run changes the cache before the lookups
+		run  func(c *LRU)
+		want map[string]interface{}
+		// missing are keys expected not to be found
+		missing []string
+	}{
+		{
+			name:    "set and get",
+			size:    2,
+			run:     func(c *LRU) { c.Set("a", 1, time.Minute) },
+			want:    map[string]interface{}{"a": 1},
+			missing: []string{"b"},
+		},
+		{
+			name: "overwrite",
+			size: 2,
+			run: func(c *LRU) {
+				c.Set("a", 1, time.Minute)
+				c.Set("a", 2, time.Minute)
+			},
+			want: map[string]interface{}{"a": 2},
+		},
+		{
+			name: "evicts the least recently used",
+			size: 2,
+			run: func(c *LRU) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+				c.Get("a")
+				c.Set("c", 3, time.Minute)
+			},
+			want:    map[string]interface{}{"a": 1, "c": 3},
+			missing: []string{"b"},
+		},
+		{
+			name:    "expired entries are not served",
+			size:    2,
+			run:     func(c *LRU) { c.Set("a", 1, -time.Second) },
+			missing: []string{"a"},
+		},
+		{
+			name: "delete",
+			size: 2,
+			run: func(c *LRU) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+				c.Delete("a")
+				c.Delete("unknown")
+			},
+			want:    map[string]interface{}{"b": 2},
+			missing: []string{"a"},
+		},
+		{
+			name: "clear",
+			size: 2,
+			run: func(c *LRU) {
+				c.Set("a", 1, time.Minute)
+				c.Clear()
+				c.Set("b", 2, time.Minute)
+			},
+			want:    map[string]interface{}{"b": 2},
+			missing: []string{"a"},
+		},
+		{
+			name: "size below one holds one entry",
+			size: 0,
+			run: func(c *LRU) {
+				c.Set("a", 1, time.Minute)
+				c.Set("b", 2, time.Minute)
+			},
+			want:    map[string]interface{}{"b": 2},
+			missing: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU(tt.size)
+			tt.run(c)
+
+			for key, want := range tt.want {
+				if got, ok := c.Get(key); !ok || got != want {
+					t.Errorf("Get(%q) = %v, %v; want %v", key, got, ok, want)
+				}
+			}
+			for _, key := range tt.missing {
+				if got, ok := c.Get(key); ok {
+					t.Errorf("Get(%q) = %v; want a miss", key, got)
+				}
+			}
+			if c.Len() > max(tt.size, 1) {
+				t.Errorf("got %d entries, more than the size %d", c.Len(), tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"skeleton/app/support/logging"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Store is the subset of a dg-cache store used by Remember
+type Store interface {
+	GetAs(ctx context.Context, key string, dest interface{}) error
+	Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Forget(ctx context.Context, key string) error
+}
+
+// Loader loads a value on a cache miss
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// Option configures a Remember call
+type Option func(*rememberOptions)
+
+type rememberOptions struct {
+	stale       time.Duration
+	beta        float64
+	notFound    error
+	negativeTTL time.Duration
+	locker      Locker
+	lockWait    time.Duration
+	refreshTTL  time.Duration
+}
+
+// WithStale keeps serving an expired value for up to d while it is refreshed in the background
+func WithStale(d time.Duration) Option {
+	return func(o *rememberOptions) { o.stale = d }
+}
+
+// WithEarlyRefresh refreshes a value in the background before it expires, with a probability
+// that grows as expiry approaches and with the cost of the last load (XFetch)
+// beta scales how early refreshes happen; 1 is a good default, 0 disables
+func WithEarlyRefresh(beta float64) Option {
+	return func(o *rememberOptions) { o.beta = beta }
+}
+
+// WithNegative caches loads failing with notFound (matched with errors.Is) for ttl,
+// returning notFound from the cache instead of calling the loader again
+func WithNegative(notFound error, ttl time.Duration) Option {
+	return func(o *rememberOptions) {
+		o.notFound = notFound
+		o.negativeTTL = ttl
+	}
+}
+
+// WithLock coalesces loads across instances: only the lock holder runs the loader while the
+// others wait up to wait for the value to appear, then load anyway
+func WithLock(locker Locker, wait time.Duration) Option {
+	return func(o *rememberOptions) {
+		o.locker = locker
+		o.lockWait = wait
+	}
+}
+
+// entry is the cached representation of a remembered value
+type entry[T any] struct {
+	Value      T         `json:"value"`
+	NotFound   bool      `json:"not_found,omitempty"`
+	FreshUntil time.Time `json:"fresh_until"`
+	// LoadMS is how long the last load took, used for early refresh
+	LoadMS int64 `json:"load_ms"`
+}
+
+// flights coalesces concurrent loads of the same key within the process, keyed by flightKey
+var flights singleflight.Group
+
+// Remember returns the cached value for key, loading and caching it on a miss
+//
+// Concurrent misses for the same key run the loader once per process (and once across
+// instances with WithLock). Values older than ttl are refreshed; see WithStale,
+// WithEarlyRefresh and WithNegative for the optional behaviors.
+func Remember[T any](ctx context.Context, store Store, key string, ttl time.Duration, loader Loader[T], opts ...Option) (T, error) {
+	o := rememberOptions{refreshTTL: 10 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var cached entry[T]
+	if err := store.GetAs(ctx, key, &cached); err == nil && !cached.FreshUntil.IsZero() {
+		now := time.Now()
+		switch {
+		case now.Before(cached.FreshUntil):
+			if o.beta > 0 && shouldRefreshEarly(now, cached, o.beta) {
+				refresh(ctx, store, key, ttl, loader, o)
+			}
+			return cached.result(o)
+
+		case now.Before(cached.FreshUntil.Add(o.stale)):
+			refresh(ctx, store, key, ttl, loader, o)
+			return cached.result(o)
+		}
+	}
+
+	// The load is shared by every caller waiting on it, so one caller going away must not
+	// cancel it for the others; each caller still stops waiting when its own context ends
+	loadCtx := context.WithoutCancel(ctx)
+	ch := flights.DoChan(flightKey[T](store, key), func() (interface{}, error) {
+		return load(loadCtx, store, key, ttl, loader, o)
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		value, ok := res.Val.(T)
+		if !ok && res.Val != nil {
+			return zero, fmt.Errorf("cache: load of %q returned %T, want %s", key, res.Val, reflect.TypeFor[T]())
+		}
+		return value, nil
+	}
+}
+
+// flightKey identifies the loads that may be shared: the same key of the same store,
+// loading the same type. Views of a store, such as the tagged views made for each call,
+// are unwrapped, so loads through them share the flight of the store
+func flightKey[T any](store Store, key string) string {
+	for {
+		view, ok := store.(interface{ Unwrap() Store })
+		if !ok {
+			break
+		}
+		store = view.Unwrap()
+	}
+
+	id := fmt.Sprintf("%T", store)
+	if v := reflect.ValueOf(store); v.Kind() == reflect.Pointer {
+		id = fmt.Sprintf("%s@%x", id, v.Pointer())
+	}
+	return fmt.Sprintf("%s|%s|%s", id, reflect.TypeFor[T](), key)
+}
+
+// result returns the cached value, or the not-found error for a negative entry
+func (e entry[T]) result(o rememberOptions) (T, error) {
+	if e.NotFound {
+		var zero T
+		return zero, o.notFound
+	}
+	return e.Value, nil
+}
+
+// shouldRefreshEarly implements XFetch: now - delta*beta*ln(rand) >= expiry
+func shouldRefreshEarly[T any](now time.Time, cached entry[T], beta float64) bool {
+	delta := float64(cached.LoadMS) * float64(time.Millisecond)
+	gap := time.Duration(-delta * beta * math.Log(1-rand.Float64()))
+	return !now.Add(gap).Before(cached.FreshUntil)
+}
+
+// refresh reloads the value in the background, at most once per key at a time
+func refresh[T any](ctx context.Context, store Store, key string, ttl time.Duration, loader Loader[T], o rememberOptions) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.refreshTTL)
+	ch := flights.DoChan(flightKey[T](store, key), func() (interface{}, error) {
+		return load(ctx, store, key, ttl, loader, o)
+	})
+
+	go func() {
+		defer cancel()
+		if result := <-ch; result.Err != nil && !isNotFound(result.Err, o) {
+			logging.FromContext(ctx).Warn("Background cache refresh failed", "key", key, "error", result.Err)
+		}
+	}()
+}
+
+// load runs the loader, holding the distributed lock when configured, and caches the result
+func load[T any](ctx context.Context, store Store, key string, ttl time.Duration, loader Loader[T], o rememberOptions) (interface{}, error) {
+	if o.locker != nil {
+		unlock, acquired, err := o.locker.Lock(ctx, "lock:"+key, o.refreshTTL)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Cache lock unavailable, loading without it", "key", key, "error", err)
+		} else if acquired {
+			defer unlock()
+		} else if value, ok := waitForValue[T](ctx, store, key, o); ok {
+			return value.result(o)
+		}
+	}
+
+	start := time.Now()
+	value, err := loader(ctx)
+	loadMS := time.Since(start).Milliseconds()
+
+	if err != nil {
+		if !isNotFound(err, o) {
+			return nil, err
+		}
+		negative := entry[T]{NotFound: true, FreshUntil: time.Now().Add(o.negativeTTL), LoadMS: loadMS}
+		if putErr := store.Put(ctx, key, negative, o.negativeTTL); putErr != nil {
+			logging.FromContext(ctx).Warn("Failed to cache not-found result", "key", key, "error", putErr)
+		}
+		return nil, err
+	}
+
+	fresh := entry[T]{Value: value, FreshUntil: time.Now().Add(ttl), LoadMS: loadMS}
+	if err := store.Put(ctx, key, fresh, ttl+o.stale); err != nil {
+		logging.FromContext(ctx).Warn("Failed to cache value", "key", key, "error", err)
+	}
+	return value, nil
+}
+
+// waitForValue polls the cache while another instance holds the lock
+func waitForValue[T any](ctx context.Context, store Store, key string, o rememberOptions) (entry[T], bool) {
+	deadline := time.Now().Add(o.lockWait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return entry[T]{}, false
+		case <-time.After(25 * time.Millisecond):
+		}
+
+		var cached entry[T]
+		if err := store.GetAs(ctx, key, &cached); err == nil && !cached.FreshUntil.IsZero() && time.Now().Before(cached.FreshUntil) {
+			return cached, true
+		}
+	}
+	return entry[T]{}, false
+}
+
+func isNotFound(err error, o rememberOptions) bool {
+	return o.notFound != nil && o.negativeTTL > 0 && errors.Is(err, o.notFound)
+}
@@ -0,0 +1,327 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryStore is a Store keeping JSON values, like the dg-cache drivers
+type memoryStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: map[string][]byte{}}
+}
+
+func (s *memoryStore) GetAs(ctx context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.values[key]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = data
+	return nil
+}
+
+func (s *memoryStore) Forget(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// counter is a loader counting its calls
+type counter struct {
+	calls atomic.Int32
+	value string
+	err   error
+}
+
+func (c *counter) load(ctx context.Context) (string, error) {
+	c.calls.Add(1)
+	return c.value, c.err
+}
+
+var errNotFound = errors.New("not found")
+
+func TestRemember(t *testing.T) {
+	tests := []struct {
+		name string
+		// cached is put in the store before the call, with its freshness relative to now
+		cached    *entry[string]
+		loader    *counter
+		opts      []Option
+		want      string
+		wantErr   error
+		wantCalls int32
+	}{
+		{name: "miss loads", loader: &counter{value: "loaded"}, want: "loaded", wantCalls: 1},
+		{name: "fresh hit", cached: &entry[string]{Value: "cached", FreshUntil: time.Now().Add(time.Minute)}, loader: &counter{value: "loaded"}, want: "cached"},
+		{name: "expired entry loads", cached: &entry[string]{Value: "cached", FreshUntil: time.Now().Add(-time.Second)}, loader: &counter{value: "loaded"}, want: "loaded", wantCalls: 1},
+		{
+			name:    "loader error",
+			loader:  &counter{err: errors.New("database down")},
+			wantErr: errors.New("database down"), wantCalls: 1,
+		},
+		{
+			name:    "not found cached",
+			cached:  &entry[string]{NotFound: true, FreshUntil: time.Now().Add(time.Minute)},
+			loader:  &counter{value: "loaded"},
+			opts:    []Option{WithNegative(errNotFound, time.Minute)},
+			wantErr: errNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemoryStore()
+			if tt.cached != nil {
+				if err := store.Put(ctx, "key", tt.cached, time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := Remember(ctx, store, "key", time.Minute, tt.loader.load, tt.opts...)
+			if (err != nil) != (tt.wantErr != nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if calls := tt.loader.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("got %d loader calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRememberCachesResults(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		loader    *counter
+		opts      []Option
+		wantCalls int32
+	}{
+		{name: "value", loader: &counter{value: "loaded"}, wantCalls: 1},
+		{name: "not found with negative caching", loader: &counter{err: errNotFound}, opts: []Option{WithNegative(errNotFound, time.Minute)}, wantCalls: 1},
+		{name: "not found without negative caching", loader: &counter{err: errNotFound}, wantCalls: 2},
+		{name: "other errors", loader: &counter{err: errors.New("timeout")}, opts: []Option{WithNegative(errNotFound, time.Minute)}, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			for i := 0; i < 2; i++ {
+				_, _ = Remember(ctx, store, "key", time.Minute, tt.loader.load, tt.opts...)
+			}
+			if calls := tt.loader.calls.Load(); calls != tt.wantCalls {
+				t.Errorf("got %d loader calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRememberStale(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	stale := entry[string]{Value: "stale", FreshUntil: time.Now().Add(-time.Second)}
+	if err := store.Put(ctx, "key", stale, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed := make(chan struct{})
+	loader := func(ctx context.Context) (string, error) {
+		defer close(refreshed)
+		return "fresh", nil
+	}
+	got, err := Remember(ctx, store, "key", time.Minute, loader, WithStale(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "stale" {
+		t.Errorf("got %q, want the stale value while refreshing", got)
+	}
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("the stale value was not refreshed")
+	}
+	// The refresh stores the value after the loader returns
+	deadline := time.Now().Add(time.Second)
+	for {
+		var cached entry[string]
+		if err := store.GetAs(ctx, "key", &cached); err == nil && cached.Value == "fresh" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the refreshed value was not cached")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRememberConcurrentMisses(t *testing.T) {
+	const callers = 20
+	ctx := context.Background()
+	storeA, storeB := newMemoryStore(), newMemoryStore()
+	tagger := NewTagger(NewMemoryTagIndex())
+
+	tests := []struct {
+		name string
+		// call runs the i-th caller
+		call      func(i int, loader Loader[string]) (string, error)
+		wantCalls int32
+	}{
+		{
+			name: "same key and store",
+			call: func(i int, loader Loader[string]) (string, error) {
+				return Remember(ctx, storeA, "key", time.Minute, loader)
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "same key through a new tagged view per call",
+			call: func(i int, loader Loader[string]) (string, error) {
+				return Remember(ctx, tagger.Tags(storeA, "tag"), "key", time.Minute, loader)
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "same key in two stores",
+			call: func(i int, loader Loader[string]) (string, error) {
+				return Remember(ctx, pickStore(i, storeA, storeB), "key", time.Minute, loader)
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "same key loading two types",
+			call: func(i int, loader Loader[string]) (string, error) {
+				if i%2 == 0 {
+					return Remember(ctx, storeA, "key", time.Minute, loader)
+				}
+				value, err := Remember(ctx, storeA, "key", time.Minute, func(ctx context.Context) ([]byte, error) {
+					s, err := loader(ctx)
+					return []byte(s), err
+				})
+				return string(value), err
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeA.values = map[string][]byte{}
+			storeB.values = map[string][]byte{}
+
+			var calls atomic.Int32
+			release := make(chan struct{})
+			loader := func(ctx context.Context) (string, error) {
+				calls.Add(1)
+				<-release
+				return "loaded", nil
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, callers)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got, err := tt.call(i, loader)
+					if err == nil && got != "loaded" {
+						err = errors.New("got " + got)
+					}
+					errs <- err
+				}()
+			}
+			// Let every caller reach the flight before the load completes
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				if err != nil {
+					t.Error(err)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("got %d loads, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// pickStore spreads the callers over two stores
+func pickStore(i int, a, b Store) Store {
+	if i%2 == 0 {
+		return a
+	}
+	return b
+}
+
+func TestRememberCancelledCaller(t *testing.T) {
+	store := newMemoryStore()
+	release := make(chan struct{})
+	var loadErr atomic.Value
+	loader := func(ctx context.Context) (string, error) {
+		<-release
+		if err := ctx.Err(); err != nil {
+			loadErr.Store(err)
+			return "", err
+		}
+		return "loaded", nil
+	}
+
+	// The first caller starts the load and goes away
+	cancelled, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := Remember(cancelled, store, "key", time.Minute, loader)
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	second := make(chan string, 1)
+	go func() {
+		value, _ := Remember(context.Background(), store, "key", time.Minute, loader)
+		second <- value
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v for the cancelled caller, want context.Canceled", err)
+	}
+
+	close(release)
+	if got := <-second; got != "loaded" {
+		t.Errorf("got %q for the waiting caller, want the shared load", got)
+	}
+	if err := loadErr.Load(); err != nil {
+		t.Errorf("the shared load saw the cancellation: %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TagIndex records which cache keys carry a tag
+type TagIndex interface {
+	// Add records key under each tag for at least ttl
+	Add(ctx context.Context, key string, ttl time.Duration, tags ...string) error
+	// Pop returns the keys recorded under tag and clears it
+	Pop(ctx context.Context, tag string) ([]string, error)
+}
+
+// Tagger adds tag support to one cache store, with a tag index attached to that store
+type Tagger struct {
+	index TagIndex
+}
+
+// NewTagger creates a tagger on top of the tag index of a store
+func NewTagger(index TagIndex) *Tagger {
+	return &Tagger{index: index}
+}
+
+// Taggers holds the tagger of every cache store, so tags never span stores
+type Taggers struct {
+	defaultStore string
+	taggers      map[string]*Tagger
+}
+
+// NewTaggers creates the taggers of the stores, keyed by store name
+func NewTaggers(defaultStore string, taggers map[string]*Tagger) *Taggers {
+	return &Taggers{defaultStore: defaultStore, taggers: taggers}
+}
+
+// Store returns the tagger of the named store, or of the default store when name is empty
+func (t *Taggers) Store(name string) (*Tagger, error) {
+	if name == "" {
+		name = t.defaultStore
+	}
+	tagger, ok := t.taggers[name]
+	if !ok {
+		return nil, fmt.Errorf("cache store '%s' is not configured", name)
+	}
+	return tagger, nil
+}
+
+// Tags returns a view of store whose writes are recorded under the given tags
+// store must be the store the tagger belongs to
+func (t *Tagger) Tags(store Store, tags ...string) Store {
+	return &taggedStore{store: store, index: t.index, tags: tags}
+}
+
+// Tag records key under the tags without writing it, for tags only known once the value is loaded
+func (t *Tagger) Tag(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return t.index.Add(ctx, key, ttl, tags...)
+}
+
+// FlushTags forgets every key recorded under any of the tags
+func (t *Tagger) FlushTags(ctx context.Context, store Store, tags ...string) error {
+	var errs []error
+	for _, tag := range tags {
+		keys, err := t.index.Pop(ctx, tag)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, key := range keys {
+			if err := store.Forget(ctx, key); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// taggedStore records every Put under its tags
+type taggedStore struct {
+	store Store
+	index TagIndex
+	tags  []string
+}
+
+func (s *taggedStore) GetAs(ctx context.Context, key string, dest interface{}) error {
+	return s.store.GetAs(ctx, key, dest)
+}
+
+// Put indexes the key before writing it, so a concurrent flush never misses a stored value
+func (s *taggedStore) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := s.index.Add(ctx, key, ttl, s.tags...); err != nil {
+		return err
+	}
+	return s.store.Put(ctx, key, value, ttl)
+}
+
+func (s *taggedStore) Forget(ctx context.Context, key string) error {
+	return s.store.Forget(ctx, key)
+}
+
+// Unwrap returns the tagged store, so Remember coalesces loads across tagged views
+func (s *taggedStore) Unwrap() Store {
+	return s.store
+}
+
+// addScript adds a member and extends the set's expiry, never shortening it
+var addScript = goredis.NewScript(`
+redis.call("SADD", KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if redis.call("PTTL", KEYS[1]) < ttl then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return 1
+`)
+
+// popScript returns all members and deletes the set atomically
+var popScript = goredis.NewScript(`
+local keys = redis.call("SMEMBERS", KEYS[1])
+redis.call("DEL", KEYS[1])
+return keys
+`)
+
+// RedisTagIndex keeps one Redis set of keys per tag, shared by all instances
+type RedisTagIndex struct {
+	client goredis.UniversalClient
+	prefix string
+}
+
+// NewRedisTagIndex creates a Redis tag index; prefix namespaces the tag sets
+func NewRedisTagIndex(client goredis.UniversalClient, prefix string) *RedisTagIndex {
+	return &RedisTagIndex{client: client, prefix: prefix}
+}
+
+// Add records key under each tag
+func (i *RedisTagIndex) Add(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	for _, tag := range tags {
+		if err := addScript.Run(ctx, i.client, []string{i.prefix + tag}, key, ttl.Milliseconds()).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pop returns and clears the keys recorded under tag
+func (i *RedisTagIndex) Pop(ctx context.Context, tag string) ([]string, error) {
+	return popScript.Run(ctx, i.client, []string{i.prefix + tag}).StringSlice()
+}
+
+// MemoryTagIndex keeps the tag index in process, for the memory cache driver
+type MemoryTagIndex struct {
+	mu   sync.Mutex
+	tags map[string]map[string]time.Time
+}
+
+// memoryTagPruneThreshold is the tag size above which expired keys are pruned on Add
+const memoryTagPruneThreshold = 1024
+
+// NewMemoryTagIndex creates an in-process tag index
+func NewMemoryTagIndex() *MemoryTagIndex {
+	return &MemoryTagIndex{tags: make(map[string]map[string]time.Time)}
+}
+
+// Add records key under each tag
+func (i *MemoryTagIndex) Add(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	for _, tag := range tags {
+		keys, ok := i.tags[tag]
+		if !ok {
+			keys = make(map[string]time.Time)
+			i.tags[tag] = keys
+		}
+		if len(keys) >= memoryTagPruneThreshold {
+			pruneExpired(keys)
+		}
+		if expires.After(keys[key]) {
+			keys[key] = expires
+		}
+	}
+	return nil
+}
+
+// Pop returns and clears the live keys recorded under tag
+func (i *MemoryTagIndex) Pop(ctx context.Context, tag string) ([]string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := time.Now()
+	keys := make([]string, 0, len(i.tags[tag]))
+	for key, expires := range i.tags[tag] {
+		if now.Before(expires) {
+			keys = append(keys, key)
+		}
+	}
+	delete(i.tags, tag)
+	return keys, nil
+}
+
+func pruneExpired(keys map[string]time.Time) {
+	now := time.Now()
+	for key, expires := range keys {
+		if !now.Before(expires) {
+			delete(keys, key)
+		}
+	}
+}
+
+// MustResolveTaggers resolves the taggers of the cache stores from the container
+// It panics if the resolution fails, which is acceptable during app boot
+func MustResolveTaggers(app foundation.Application) *Taggers {
+	taggers, err := app.Make("cacheTaggers")
+	if err != nil {
+		panic("failed to resolve cache taggers: " + err.Error())
+	}
+	return taggers.(*Taggers)
+}
+
+// MustResolveTagger resolves the tagger of the default cache store
+// It panics if the resolution fails, which is acceptable during app boot
+func MustResolveTagger(app foundation.Application) *Tagger {
+	tagger, err := MustResolveTaggers(app).Store("")
+	if err != nil {
+		panic("failed to resolve cache tagger: " + err.Error())
+	}
+	return tagger
+}
@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMemoryTagIndex(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// add records keys with their TTL under the tags
+		add  func(i *MemoryTagIndex)
+		tag  string
+		want []string
+	}{
+		{
+			name: "keys under a tag",
+			add: func(i *MemoryTagIndex) {
+				_ = i.Add(ctx, "a", time.Minute, "users")
+				_ = i.Add(ctx, "b", time.Minute, "users", "admins")
+				_ = i.Add(ctx, "c", time.Minute, "admins")
+			},
+			tag:  "users",
+			want: []string{"a", "b"},
+		},
+		{
+			name: "expired keys are dropped",
+			add: func(i *MemoryTagIndex) {
+				_ = i.Add(ctx, "a", -time.Second, "users")
+				_ = i.Add(ctx, "b", time.Minute, "users")
+			},
+			tag:  "users",
+			want: []string{"b"},
+		},
+		{
+			name: "a longer TTL extends the key",
+			add: func(i *MemoryTagIndex) {
+				_ = i.Add(ctx, "a", time.Minute, "users")
+				_ = i.Add(ctx, "a", -time.Second, "users")
+			},
+			tag:  "users",
+			want: []string{"a"},
+		},
+		{
+			name: "unknown tag",
+			add:  func(i *MemoryTagIndex) {},
+			tag:  "users",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewMemoryTagIndex()
+			tt.add(index)
+
+			got, err := index.Pop(ctx, tt.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(got)
+			if !equalStrings(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			// Pop clears the tag
+			if again, _ := index.Pop(ctx, tt.tag); len(again) != 0 {
+				t.Errorf("got %v after Pop, want nothing", again)
+			}
+		})
+	}
+}
+
+func TestTagger(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		flush []string
+		// want are the keys left in the store
+		want []string
+	}{
+		{name: "flush one tag", flush: []string{"user:1"}, want: []string{"user:2"}},
+		{name: "flush a shared tag", flush: []string{"users"}, want: []string{"user:1", "user:2"}},
+		{name: "flush several tags", flush: []string{"users", "user:2"}, want: []string{"user:1"}},
+		{name: "flush an unknown tag", flush: []string{"posts"}, want: []string{"list", "user:1", "user:2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			tagger := NewTagger(NewMemoryTagIndex())
+
+			writes := []struct {
+				key  string
+				tags []string
+			}{
+				{key: "user:1", tags: []string{"user:1"}},
+				{key: "user:2", tags: []string{"user:2"}},
+				{key: "list", tags: []string{"users", "user:1", "user:2"}},
+			}
+			for _, w := range writes {
+				if err := tagger.Tags(store, w.tags...).Put(ctx, w.key, "value", time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := tagger.FlushTags(ctx, store, tt.flush...); err != nil {
+				t.Fatal(err)
+			}
+			if got := storeKeys(store); !equalStrings(got, tt.want) {
+				t.Errorf("got keys %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaggerTag(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	tagger := NewTagger(NewMemoryTagIndex())
+
+	// A value written without tags, tagged once loaded
+	if err := store.Put(ctx, "user:1", "value", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := tagger.Tag(ctx, "user:1", time.Minute, "team:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tagger.FlushTags(ctx, store, "team:1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := storeKeys(store); len(got) != 0 {
+		t.Errorf("got keys %v, want none", got)
+	}
+}
+
+func TestTaggers(t *testing.T) {
+	ctx := context.Background()
+	redis, memory := newMemoryStore(), newMemoryStore()
+	taggers := NewTaggers("redis", map[string]*Tagger{
+		"redis":  NewTagger(NewMemoryTagIndex()),
+		"memory": NewTagger(NewMemoryTagIndex()),
+	})
+
+	defaultTagger, err := taggers.Store("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	memoryTagger, err := taggers.Store("memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := taggers.Store("unknown"); err == nil {
+		t.Error("expected an error for an unknown store")
+	}
+
+	// The same key and tag in two stores: flushing one store leaves the other
+	if err := defaultTagger.Tags(redis, "users").Put(ctx, "user:1", "value", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := memoryTagger.Tags(memory, "users").Put(ctx, "user:1", "value", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := defaultTagger.FlushTags(ctx, redis, "users"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := storeKeys(redis); len(got) != 0 {
+		t.Errorf("got keys %v in the flushed store, want none", got)
+	}
+	if got := storeKeys(memory); !equalStrings(got, []string{"user:1"}) {
+		t.Errorf("got keys %v in the other store, want user:1", got)
+	}
+	if err := memoryTagger.FlushTags(ctx, memory, "users"); err != nil {
+		t.Fatal(err)
+	}
+	if got := storeKeys(memory); len(got) != 0 {
+		t.Errorf("the tag of the other store was lost: %v left", got)
+	}
+}
+
+// storeKeys returns the sorted keys of the store
+func storeKeys(s *memoryStore) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
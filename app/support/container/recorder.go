@@ -0,0 +1,138 @@
+package container
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	core "github.com/donnigundala/dg-core/foundation"
+)
+
+// Kinds of binding
+const (
+	KindSingleton = "singleton"
+	KindBind      = "bind"
+	KindInstance  = "instance"
+)
+
+// Binding describes a container binding as it was registered
+type Binding struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Resolved reports whether the binding has been built; Type is only known then
+	Resolved bool   `json:"resolved"`
+	Type     string `json:"type,omitempty"`
+}
+
+// Recorder is a container recording the bindings made through it, so they can be
+// listed without resolving them
+// Providers wrapped with Provider register and boot through it
+type Recorder struct {
+	foundation.Application
+
+	mu       sync.Mutex
+	bindings map[string]*Binding
+}
+
+// NewRecorder creates a recorder over the application container
+func NewRecorder(app foundation.Application) *Recorder {
+	return &Recorder{Application: app, bindings: make(map[string]*Binding)}
+}
+
+// Singleton records and binds a shared factory
+func (r *Recorder) Singleton(name string, factory func() (interface{}, error)) {
+	r.record(name, KindSingleton)
+	r.Application.Singleton(name, r.observe(name, factory))
+}
+
+// Bind records and binds a factory called on every resolution
+func (r *Recorder) Bind(name string, factory func() (interface{}, error)) {
+	r.record(name, KindBind)
+	r.Application.Bind(name, r.observe(name, factory))
+}
+
+// Instance records and binds an existing value
+func (r *Recorder) Instance(name string, instance interface{}) {
+	r.record(name, KindInstance)
+	r.resolved(name, instance)
+	r.Application.Instance(name, instance)
+}
+
+// Bindings returns the recorded bindings, sorted by name
+func (r *Recorder) Bindings() []Binding {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bindings := make([]Binding, 0, len(r.bindings))
+	for _, binding := range r.bindings {
+		bindings = append(bindings, *binding)
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].Name < bindings[j].Name })
+	return bindings
+}
+
+// Provider wraps a service provider so the bindings it makes are recorded
+func (r *Recorder) Provider(provider core.ServiceProvider) core.ServiceProvider {
+	return &recordedProvider{provider: provider, recorder: r}
+}
+
+func (r *Recorder) record(name, kind string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bindings[name] = &Binding{Name: name, Kind: kind}
+}
+
+// observe wraps a factory to record the type it builds
+func (r *Recorder) observe(name string, factory func() (interface{}, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		instance, err := factory()
+		if err == nil {
+			r.resolved(name, instance)
+		}
+		return instance, err
+	}
+}
+
+func (r *Recorder) resolved(name string, instance interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if binding, ok := r.bindings[name]; ok {
+		binding.Resolved = true
+		binding.Type = fmt.Sprintf("%T", instance)
+	}
+}
+
+// recordedProvider registers and boots a provider through the recorder
+type recordedProvider struct {
+	provider core.ServiceProvider
+	recorder *Recorder
+}
+
+func (p *recordedProvider) Register(app foundation.Application) error {
+	return p.provider.Register(p.recorder)
+}
+
+func (p *recordedProvider) Boot(app foundation.Application) error {
+	return p.provider.Boot(p.recorder)
+}
+
+// Resolve returns the binding recorder, or nil when the application has none
+func Resolve(app foundation.Application) *Recorder {
+	recorder, err := app.Make("bindings")
+	if err != nil {
+		return nil
+	}
+	r, _ := recorder.(*Recorder)
+	return r
+}
+
+// MustResolve resolves the binding recorder from the container
+// It panics if the resolution fails, which is acceptable during app boot
+func MustResolve(app foundation.Application) *Recorder {
+	recorder := Resolve(app)
+	if recorder == nil {
+		panic("failed to resolve binding recorder")
+	}
+	return recorder
+}
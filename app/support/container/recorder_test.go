@@ -0,0 +1,116 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// fakeApp is a container building singletons on first use
+type fakeApp struct {
+	factories map[string]func() (interface{}, error)
+	instances map[string]interface{}
+}
+
+func newFakeApp() *fakeApp {
+	return &fakeApp{factories: map[string]func() (interface{}, error){}, instances: map[string]interface{}{}}
+}
+
+func (a *fakeApp) Make(name string) (interface{}, error) {
+	if instance, ok := a.instances[name]; ok {
+		return instance, nil
+	}
+	factory, ok := a.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("%s is not bound", name)
+	}
+	return factory()
+}
+
+func (a *fakeApp) Singleton(name string, factory func() (interface{}, error)) {
+	a.factories[name] = func() (interface{}, error) {
+		instance, err := factory()
+		if err == nil {
+			a.instances[name] = instance
+		}
+		return instance, err
+	}
+}
+
+func (a *fakeApp) Bind(name string, factory func() (interface{}, error)) { a.factories[name] = factory }
+
+func (a *fakeApp) Instance(name string, instance interface{}) { a.instances[name] = instance }
+
+// fakeProvider binds a singleton in Register and an instance in Boot
+type fakeProvider struct{}
+
+func (fakeProvider) Register(app foundation.Application) error {
+	app.Singleton("lazy", func() (interface{}, error) { return &fakeApp{}, nil })
+	return nil
+}
+
+func (fakeProvider) Boot(app foundation.Application) error {
+	app.Instance("booted", 42)
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	built := 0
+	tests := []struct {
+		name string
+		// resolve are the bindings resolved before listing
+		resolve []string
+		want    []Binding
+	}{
+		{
+			name: "nothing resolved",
+			want: []Binding{
+				{Name: "booted", Kind: KindInstance, Resolved: true, Type: "int"},
+				{Name: "counted", Kind: KindBind},
+				{Name: "lazy", Kind: KindSingleton},
+			},
+		},
+		{
+			name:    "resolved bindings have a type",
+			resolve: []string{"lazy", "counted"},
+			want: []Binding{
+				{Name: "booted", Kind: KindInstance, Resolved: true, Type: "int"},
+				{Name: "counted", Kind: KindBind, Resolved: true, Type: "int"},
+				{Name: "lazy", Kind: KindSingleton, Resolved: true, Type: "*container.fakeApp"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := NewRecorder(newFakeApp())
+			recorder.Bind("counted", func() (interface{}, error) {
+				built++
+				return built, nil
+			})
+			provider := recorder.Provider(fakeProvider{})
+			if err := provider.Register(nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := provider.Boot(nil); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range tt.resolve {
+				if _, err := recorder.Make(name); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before := built
+
+			if got := recorder.Bindings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if built != before {
+				t.Error("listing resolved a binding")
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Database pings the connection pool
+func Database(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// Redis sends PING to the server
+func Redis(client goredis.UniversalClient) CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// WritableDirs verifies that each directory exists and accepts new files
+func WritableDirs(dirs ...string) CheckFunc {
+	return func(ctx context.Context) error {
+		for _, dir := range dirs {
+			file, err := os.CreateTemp(dir, ".health-*")
+			if err != nil {
+				return fmt.Errorf("%s is not writable: %w", dir, err)
+			}
+			name := file.Name()
+			_ = file.Close()
+			if err := os.Remove(name); err != nil {
+				return fmt.Errorf("failed to clean up %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// FirebaseCredentials verifies that service account credentials are configured and parse as JSON
+func FirebaseCredentials(credentialsFile, credentialsJSON string) CheckFunc {
+	return func(ctx context.Context) error {
+		raw := []byte(credentialsJSON)
+		if credentialsFile != "" {
+			data, err := os.ReadFile(credentialsFile)
+			if err != nil {
+				return fmt.Errorf("failed to read credentials file: %w", err)
+			}
+			raw = data
+		}
+
+		if len(raw) == 0 {
+			return errors.New("no credentials configured")
+		}
+		if !json.Valid(raw) {
+			return errors.New("credentials are not valid JSON")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWritableDirs(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if err := WritableDirs(dir)(ctx); err != nil {
+		t.Fatalf("got %v for a writable directory", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("the check left %d files behind", len(entries))
+	}
+	if err := WritableDirs(dir, filepath.Join(dir, "missing"))(ctx); err == nil {
+		t.Error("a missing directory passed")
+	}
+}
+
+func TestFirebaseCredentials(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"type":"service_account"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"type":`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		json    string
+		wantErr bool
+	}{
+		{name: "credentials file", file: valid},
+		{name: "credentials json", json: `{"type":"service_account"}`},
+		{name: "file before json", file: valid, json: `not json`},
+		{name: "nothing configured", wantErr: true},
+		{name: "missing file", file: filepath.Join(dir, "missing.json"), wantErr: true},
+		{name: "invalid file", file: invalid, wantErr: true},
+		{name: "invalid json", json: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FirebaseCredentials(tt.file, tt.json)(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
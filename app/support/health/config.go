@@ -0,0 +1,33 @@
+package health
+
+import "time"
+
+// Config represents the health check configuration (config/health.yaml)
+type Config struct {
+	// Interval is the default time a check result is cached
+	Interval time.Duration `mapstructure:"interval"`
+	// Timeout is the default time a check may run
+	Timeout time.Duration `mapstructure:"timeout"`
+	// Token unlocks detailed output on /health in production (X-Health-Token header)
+	Token  string                 `mapstructure:"token"`
+	Checks map[string]CheckConfig `mapstructure:"checks"`
+}
+
+// CheckConfig overrides the defaults of a single check
+type CheckConfig struct {
+	Enabled  *bool         `mapstructure:"enabled"`
+	Critical *bool         `mapstructure:"critical"`
+	Interval time.Duration `mapstructure:"interval"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+// withDefaults fills in missing values
+func (c Config) withDefaults() Config {
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 2 * time.Second
+	}
+	return c
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHeader carries the token that unlocks detailed output in production
+const TokenHeader = "X-Health-Token"
+
+// ReadinessHandler responds 503 when a critical check fails, 200 otherwise
+// Only the status of each check is returned, never error details
+func (r *Registry) ReadinessHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := r.Run(c.Request.Context())
+
+		checks := make(map[string]Status, len(report.Results))
+		for _, result := range report.Results {
+			checks[result.Name] = result.Status
+		}
+
+		c.JSON(statusCode(report), gin.H{"status": report.Status, "checks": checks})
+	}
+}
+
+// HealthHandler reports the overall status, with per-check details
+// (errors, durations, criticality) when the caller is allowed to see them
+func (r *Registry) HealthHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := r.Run(c.Request.Context())
+
+		if !r.detailsAllowed(c) {
+			c.JSON(statusCode(report), gin.H{"status": report.Status})
+			return
+		}
+		c.JSON(statusCode(report), report)
+	}
+}
+
+// detailsAllowed reports whether detailed output may be shown
+// Outside production it always is; in production the configured token is required
+func (r *Registry) detailsAllowed(c *gin.Context) bool {
+	if !r.production {
+		return true
+	}
+	if r.config.Token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(c.GetHeader(TokenHeader)), []byte(r.config.Token)) == 1
+}
+
+func statusCode(report Report) int {
+	if report.Status == StatusUnavailable {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
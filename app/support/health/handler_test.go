@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = "connection refused by 10.0.0.5"
+	up := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New(secret) }
+
+	tests := []struct {
+		name       string
+		production bool
+		token      string
+		sentToken  string
+		checks     []Check
+		wantStatus int
+		// wantDetails is whether /health shows the checks
+		wantDetails bool
+	}{
+		{name: "healthy", checks: []Check{{Name: "db", Critical: true, Run: up}}, wantStatus: http.StatusOK, wantDetails: true},
+		{name: "degraded", checks: []Check{{Name: "db", Critical: true, Run: up}, {Name: "storage", Run: failing}}, wantStatus: http.StatusOK, wantDetails: true},
+		{name: "unavailable", checks: []Check{{Name: "db", Critical: true, Run: failing}}, wantStatus: http.StatusServiceUnavailable, wantDetails: true},
+		{name: "production without a token", production: true, checks: []Check{{Name: "db", Critical: true, Run: failing}}, wantStatus: http.StatusServiceUnavailable},
+		{name: "production with a wrong token", production: true, token: "t0ken", sentToken: "other", checks: []Check{{Name: "db", Critical: true, Run: failing}}, wantStatus: http.StatusServiceUnavailable},
+		{name: "production with the token", production: true, token: "t0ken", sentToken: "t0ken", checks: []Check{{Name: "db", Critical: true, Run: failing}}, wantStatus: http.StatusServiceUnavailable, wantDetails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(Config{Token: tt.token}, tt.production)
+			for _, check := range tt.checks {
+				registry.Register(check)
+			}
+			router := gin.New()
+			router.GET("/health/ready", registry.ReadinessHandler())
+			router.GET("/health", registry.HealthHandler())
+
+			get := func(path string) (int, string) {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if tt.sentToken != "" {
+					req.Header.Set(TokenHeader, tt.sentToken)
+				}
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				return rec.Code, rec.Body.String()
+			}
+
+			// Readiness lists the status of each check, never the errors
+			code, body := get("/health/ready")
+			if code != tt.wantStatus {
+				t.Errorf("got readiness status %d, want %d", code, tt.wantStatus)
+			}
+			var ready struct {
+				Status Status            `json:"status"`
+				Checks map[string]Status `json:"checks"`
+			}
+			if err := json.Unmarshal([]byte(body), &ready); err != nil {
+				t.Fatal(err)
+			}
+			if len(ready.Checks) != len(tt.checks) || strings.Contains(body, secret) {
+				t.Errorf("got readiness %s", body)
+			}
+
+			code, body = get("/health")
+			if code != tt.wantStatus {
+				t.Errorf("got health status %d, want %d", code, tt.wantStatus)
+			}
+			if details := strings.Contains(body, `"checks"`); details != tt.wantDetails {
+				t.Errorf("got health %s, want details: %v", body, tt.wantDetails)
+			}
+			if !tt.wantDetails && strings.Contains(body, secret) {
+				t.Errorf("the health output leaks the error: %s", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// Status is the outcome of a check or of the whole report
+type Status string
+
+const (
+	// StatusUp means the check passed
+	StatusUp Status = "up"
+	// StatusDown means the check failed
+	StatusDown Status = "down"
+
+	// StatusOK means every check passed
+	StatusOK Status = "ok"
+	// StatusDegraded means only non-critical checks failed
+	StatusDegraded Status = "degraded"
+	// StatusUnavailable means at least one critical check failed
+	StatusUnavailable Status = "unavailable"
+)
+
+// CheckFunc probes a dependency and returns an error when it is unhealthy
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named readiness check
+type Check struct {
+	Name string
+	// Critical checks make the application unready when they fail
+	Critical bool
+	// Interval is how long a result is cached
+	Interval time.Duration
+	// Timeout bounds a single run
+	Timeout time.Duration
+	Run     CheckFunc
+	// Close releases what Run holds, such as a client opened for the check
+	Close func() error
+}
+
+// Result is the latest outcome of a check
+type Result struct {
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report aggregates the results of all checks
+type Report struct {
+	Status  Status   `json:"status"`
+	Results []Result `json:"checks"`
+}
+
+// entry caches the latest result of a check
+// The mutex also ensures concurrent probes don't run the same check twice
+type entry struct {
+	check Check
+
+	mu      sync.Mutex
+	result  Result
+	expires time.Time
+}
+
+// Registry holds readiness checks registered by service providers
+type Registry struct {
+	config     Config
+	production bool
+
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// NewRegistry creates a new check registry
+// In production, detailed results are only shown to callers presenting the configured token
+func NewRegistry(config Config, production bool) *Registry {
+	return &Registry{
+		config:     config.withDefaults(),
+		production: production,
+		entries:    make(map[string]*entry),
+	}
+}
+
+// Register adds a check, applying the overrides from config/health.yaml
+// Checks disabled in configuration are closed and ignored
+func (r *Registry) Register(check Check) {
+	override := r.config.Checks[check.Name]
+	if override.Enabled != nil && !*override.Enabled {
+		if check.Close != nil {
+			_ = check.Close()
+		}
+		return
+	}
+	if override.Critical != nil {
+		check.Critical = *override.Critical
+	}
+	if override.Interval > 0 {
+		check.Interval = override.Interval
+	}
+	if override.Timeout > 0 {
+		check.Timeout = override.Timeout
+	}
+	if check.Interval <= 0 {
+		check.Interval = r.config.Interval
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = r.config.Timeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous, ok := r.entries[check.Name]; ok && previous.check.Close != nil {
+		_ = previous.check.Close()
+	}
+	r.entries[check.Name] = &entry{check: check}
+}
+
+// Close closes the checks holding resources, on shutdown
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for _, e := range r.entries {
+		if e.check.Close != nil {
+			if err := e.check.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", e.check.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Names returns the registered check names, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs every check concurrently, reusing cached results that haven't expired
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.get(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := Report{Status: StatusOK, Results: results}
+	for _, result := range results {
+		if result.Status == StatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusUnavailable
+			break
+		}
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// get returns the cached result, running the check when it has expired
+func (e *entry) get(ctx context.Context) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(e.expires) {
+		return e.result
+	}
+
+	// Detach from the probe request so a disconnecting client does not cache a failure
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.check.Timeout)
+	defer cancel()
+
+	err := e.check.Run(ctx)
+	e.result = Result{
+		Name:      e.check.Name,
+		Status:    StatusUp,
+		Critical:  e.check.Critical,
+		Duration:  time.Since(now).Round(time.Microsecond).String(),
+		CheckedAt: now,
+	}
+	if err != nil {
+		e.result.Status = StatusDown
+		e.result.Error = err.Error()
+	}
+	e.expires = now.Add(e.check.Interval)
+	return e.result
+}
+
+// MustResolve resolves the health check registry from the container
+// It panics if the resolution fails, which is acceptable during app boot
+func MustResolve(app foundation.Application) *Registry {
+	registry, err := app.Make("health")
+	if err != nil {
+		panic("failed to resolve health registry: " + err.Error())
+	}
+	return registry.(*Registry)
+}
@@ -0,0 +1,162 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counted returns a check that fails with err, counting its runs in calls
+func counted(calls *atomic.Int32, err error) CheckFunc {
+	return func(ctx context.Context) error {
+		calls.Add(1)
+		return err
+	}
+}
+
+func TestRegistryRun(t *testing.T) {
+	down := errors.New("down")
+	up := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return down }
+
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantStatus Status
+	}{
+		{name: "no checks", wantStatus: StatusOK},
+		{name: "every check up", checks: []Check{{Name: "db", Critical: true, Run: up}, {Name: "storage", Run: up}}, wantStatus: StatusOK},
+		{name: "non-critical check down", checks: []Check{{Name: "db", Critical: true, Run: up}, {Name: "storage", Run: failing}}, wantStatus: StatusDegraded},
+		{name: "critical check down", checks: []Check{{Name: "db", Critical: true, Run: failing}, {Name: "storage", Run: up}}, wantStatus: StatusUnavailable},
+		{name: "critical and non-critical checks down", checks: []Check{{Name: "a", Run: failing}, {Name: "b", Critical: true, Run: failing}}, wantStatus: StatusUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(Config{}, false)
+			for _, check := range tt.checks {
+				registry.Register(check)
+			}
+
+			report := registry.Run(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("got status %q, want %q", report.Status, tt.wantStatus)
+			}
+			if len(report.Results) != len(tt.checks) {
+				t.Fatalf("got %d results, want %d", len(report.Results), len(tt.checks))
+			}
+			for i, result := range report.Results {
+				if i > 0 && report.Results[i-1].Name > result.Name {
+					t.Errorf("results are not sorted: %v", report.Results)
+				}
+				if (result.Status == StatusDown) != (result.Error != "") {
+					t.Errorf("got %s %q with error %q", result.Name, result.Status, result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryCachesResults(t *testing.T) {
+	ctx := context.Background()
+	var cached, expiring atomic.Int32
+	registry := NewRegistry(Config{}, false)
+	registry.Register(Check{Name: "cached", Interval: time.Hour, Run: counted(&cached, nil)})
+	registry.Register(Check{Name: "expiring", Interval: time.Nanosecond, Run: counted(&expiring, nil)})
+
+	for i := 0; i < 3; i++ {
+		registry.Run(ctx)
+		time.Sleep(time.Millisecond)
+	}
+	if cached.Load() != 1 {
+		t.Errorf("got %d runs of a cached check, want 1", cached.Load())
+	}
+	if expiring.Load() != 3 {
+		t.Errorf("got %d runs of an expiring check, want 3", expiring.Load())
+	}
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	registry := NewRegistry(Config{}, false)
+	registry.Register(Check{Name: "slow", Critical: true, Timeout: 20 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("a check with a 20ms timeout ran for %v", elapsed)
+	}
+	if report.Status != StatusUnavailable || report.Results[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("got %+v, want the critical check down on its deadline", report)
+	}
+}
+
+func TestRegistryIgnoresProbeCancellation(t *testing.T) {
+	registry := NewRegistry(Config{}, false)
+	registry.Register(Check{Name: "db", Critical: true, Run: func(ctx context.Context) error { return ctx.Err() }})
+
+	// A probe that went away does not fail, and cache a failure of, the checks
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if report := registry.Run(ctx); report.Status != StatusOK {
+		t.Errorf("got %+v for a cancelled probe, want ok", report)
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	disabled, notCritical := false, false
+	registry := NewRegistry(Config{
+		Interval: time.Minute,
+		Timeout:  time.Second,
+		Checks: map[string]CheckConfig{
+			"firebase": {Enabled: &disabled},
+			"redis":    {Critical: &notCritical, Timeout: 5 * time.Second},
+		},
+	}, false)
+
+	var closed []string
+	closer := func(name string) func() error {
+		return func() error {
+			closed = append(closed, name)
+			return nil
+		}
+	}
+	up := func(ctx context.Context) error { return nil }
+
+	registry.Register(Check{Name: "firebase", Critical: true, Run: up, Close: closer("firebase")})
+	registry.Register(Check{Name: "redis", Critical: true, Run: up, Close: closer("redis")})
+	registry.Register(Check{Name: "db", Critical: true, Interval: time.Second, Run: up})
+
+	// A disabled check is closed and left out
+	if names := registry.Names(); len(names) != 2 || names[0] != "db" || names[1] != "redis" {
+		t.Errorf("got checks %v, want [db redis]", names)
+	}
+	if len(closed) != 1 || closed[0] != "firebase" {
+		t.Errorf("got closed %v, want [firebase]", closed)
+	}
+
+	redis := registry.entries["redis"].check
+	if redis.Critical || redis.Timeout != 5*time.Second || redis.Interval != time.Minute {
+		t.Errorf("got redis check %+v, want the overrides and the default interval", redis)
+	}
+	if db := registry.entries["db"].check; !db.Critical || db.Interval != time.Second || db.Timeout != time.Second {
+		t.Errorf("got db check %+v, want its own interval and the default timeout", db)
+	}
+
+	// Registering a check again closes the one it replaces
+	registry.Register(Check{Name: "redis", Run: up, Close: closer("redis again")})
+	if len(closed) != 2 || closed[1] != "redis" {
+		t.Errorf("got closed %v, want the replaced redis check closed", closed)
+	}
+
+	if err := registry.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 3 || closed[2] != "redis again" {
+		t.Errorf("got closed %v on Close", closed)
+	}
+}
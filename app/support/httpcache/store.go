@@ -0,0 +1,188 @@
+package httpcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	appCache "skeleton/app/support/cache"
+	"skeleton/app/support/tenancy"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// Config represents the response cache configuration (config/httpcache.yaml).
+type Config struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Prefix  string `mapstructure:"prefix"`
+	// TTL applies to responses without a max-age or s-maxage directive.
+	TTL time.Duration `mapstructure:"ttl"`
+	// MaxBodySize is the largest response body stored, in bytes.
+	MaxBodySize int `mapstructure:"max_body_size"`
+	// Vary lists request headers every cached response varies on, in addition to its own Vary header.
+	Vary []string `mapstructure:"vary"`
+	// IgnoreParams lists query parameters left out of the cache key (e.g. tracking parameters).
+	IgnoreParams []string `mapstructure:"ignore_params"`
+}
+
+// Entry is a stored response.
+type Entry struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+// variants records the request headers the responses for a URL vary on.
+type variants struct {
+	Vary []string `json:"vary"`
+}
+
+// Store persists responses in a dg-cache store, tagged by route for purging.
+type Store struct {
+	config Config
+	cache  appCache.Store
+	tagger *appCache.Tagger
+}
+
+// NewStore creates a new response cache store.
+func NewStore(config Config, cache appCache.Store, tagger *appCache.Tagger) *Store {
+	if config.Prefix == "" {
+		config.Prefix = "httpcache"
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	for i, name := range config.Vary {
+		config.Vary[i] = http.CanonicalHeaderKey(name)
+	}
+
+	return &Store{
+		config: config,
+		cache:  cache,
+		tagger: tagger,
+	}
+}
+
+// Config returns the effective configuration.
+func (s *Store) Config() Config {
+	return s.config
+}
+
+// Get returns the stored response for the request, if any.
+func (s *Store) Get(ctx context.Context, r *http.Request) (*Entry, bool) {
+	base := s.baseKey(r)
+
+	var index variants
+	if err := s.cache.GetAs(ctx, base, &index); err != nil {
+		return nil, false
+	}
+
+	var entry Entry
+	if err := s.cache.GetAs(ctx, variantKey(base, r, index.Vary), &entry); err != nil || entry.StatusCode == 0 {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Put stores the response for the request, tagged with its route and the given tags.
+// vary is the Vary header of the response.
+func (s *Store) Put(ctx context.Context, r *http.Request, route string, entry *Entry, vary []string, ttl time.Duration, tags ...string) error {
+	base := s.baseKey(r)
+	vary = s.mergeVary(vary)
+
+	store := s.tagger.Tags(s.cache, append([]string{s.routeTag(ctx, route)}, s.tags(ctx, tags)...)...)
+	if err := store.Put(ctx, base, variants{Vary: vary}, ttl); err != nil {
+		return err
+	}
+	return store.Put(ctx, variantKey(base, r, vary), entry, ttl)
+}
+
+// PurgeRoute removes every stored response of a route pattern (e.g. "/api/v1/users/:id").
+// Responses are purged for the tenant of ctx only.
+func (s *Store) PurgeRoute(ctx context.Context, route string) error {
+	return s.tagger.FlushTags(ctx, s.cache, s.routeTag(ctx, route))
+}
+
+// PurgeTags removes every stored response carrying one of the tags.
+func (s *Store) PurgeTags(ctx context.Context, tags ...string) error {
+	return s.tagger.FlushTags(ctx, s.cache, s.tags(ctx, tags)...)
+}
+
+// baseKey identifies a URL of the current tenant: its path and normalized query.
+func (s *Store) baseKey(r *http.Request) string {
+	return tenancy.CacheKey(r.Context(), s.config.Prefix+":"+hash(r.URL.Path+"?"+s.normalizeQuery(r.URL.Query())))
+}
+
+// normalizeQuery drops ignored parameters and sorts the rest, so equivalent URLs share an entry.
+func (s *Store) normalizeQuery(query url.Values) string {
+	for _, name := range s.config.IgnoreParams {
+		query.Del(name)
+	}
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return query.Encode()
+}
+
+// mergeVary combines the configured and response Vary headers, canonical and sorted.
+func (s *Store) mergeVary(vary []string) []string {
+	seen := make(map[string]bool)
+	merged := make([]string, 0, len(s.config.Vary)+len(vary))
+	for _, name := range append(append([]string(nil), s.config.Vary...), vary...) {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+func (s *Store) routeTag(ctx context.Context, route string) string {
+	return tenancy.CacheKey(ctx, s.config.Prefix+":route:"+route)
+}
+
+func (s *Store) tags(ctx context.Context, tags []string) []string {
+	prefixed := make([]string, len(tags))
+	for i, tag := range tags {
+		prefixed[i] = tenancy.CacheKey(ctx, s.config.Prefix+":tag:"+tag)
+	}
+	return prefixed
+}
+
+// variantKey identifies a response of a URL by the values of the headers it varies on.
+func variantKey(base string, r *http.Request, vary []string) string {
+	var b strings.Builder
+	for _, name := range vary {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(r.Header.Values(name), ","))
+		b.WriteByte('\n')
+	}
+	return base + ":" + hash(b.String())
+}
+
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:16])
+}
+
+// MustResolve resolves the response cache store from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolve(app foundation.Application) *Store {
+	store, err := app.Make("httpCache")
+	if err != nil {
+		panic("failed to resolve response cache store: " + err.Error())
+	}
+	return store.(*Store)
+}
@@ -0,0 +1,171 @@
+package httpcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"skeleton/app/models"
+	appCache "skeleton/app/support/cache"
+	"skeleton/app/support/tenancy"
+)
+
+// jsonStore is a cache store keeping JSON values, like the dg-cache drivers
+type jsonStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newJSONStore() *jsonStore {
+	return &jsonStore{values: map[string][]byte{}}
+}
+
+func (s *jsonStore) GetAs(ctx context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.values[key]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *jsonStore) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = data
+	return nil
+}
+
+func (s *jsonStore) Forget(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+func newTestStore(config Config) *Store {
+	return NewStore(config, newJSONStore(), appCache.NewTagger(appCache.NewMemoryTagIndex()))
+}
+
+// request builds a GET request with the given headers, in the tenant named by slug if any
+func request(url, slug string, headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, url, nil)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+	if slug != "" {
+		r = r.WithContext(tenancy.WithTenant(r.Context(), &models.Tenant{Slug: slug}))
+	}
+	return r
+}
+
+func TestStoreKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		// vary is the Vary header of the stored response
+		vary    []string
+		stored  *http.Request
+		read    *http.Request
+		wantHit bool
+	}{
+		{name: "same url", stored: request("/users?page=1", "", nil), read: request("/users?page=1", "", nil), wantHit: true},
+		{name: "parameters in another order", stored: request("/users?page=1&sort=name", "", nil), read: request("/users?sort=name&page=1", "", nil), wantHit: true},
+		{name: "repeated parameter in another order", stored: request("/users?id=1&id=2", "", nil), read: request("/users?id=2&id=1", "", nil), wantHit: true},
+		{name: "ignored parameter", stored: request("/users?page=1", "", nil), read: request("/users?page=1&utm_source=mail", "", nil), wantHit: true},
+		{name: "other parameter value", stored: request("/users?page=1", "", nil), read: request("/users?page=2", "", nil)},
+		{name: "other path", stored: request("/users", "", nil), read: request("/posts", "", nil)},
+		{name: "configured vary header", stored: request("/users", "", map[string]string{"Accept-Language": "en"}), read: request("/users", "", map[string]string{"Accept-Language": "fr"})},
+		{name: "same configured vary header", stored: request("/users", "", map[string]string{"Accept-Language": "en"}), read: request("/users", "", map[string]string{"Accept-Language": "en"}), wantHit: true},
+		{name: "response vary header", vary: []string{"accept"}, stored: request("/users", "", map[string]string{"Accept": "application/json"}), read: request("/users", "", map[string]string{"Accept": "text/html"})},
+		{name: "header the response does not vary on", stored: request("/users", "", map[string]string{"Accept": "application/json"}), read: request("/users", "", map[string]string{"Accept": "text/html"}), wantHit: true},
+		{name: "other tenant", stored: request("/users", "acme", nil), read: request("/users", "beta", nil)},
+		{name: "tenant and shared schema", stored: request("/users", "acme", nil), read: request("/users", "", nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(Config{Vary: []string{"accept-language"}, IgnoreParams: []string{"utm_source"}})
+			entry := &Entry{StatusCode: http.StatusOK, Body: []byte("users"), StoredAt: time.Now()}
+			if err := store.Put(tt.stored.Context(), tt.stored, "/users", entry, tt.vary, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := store.Get(tt.read.Context(), tt.read)
+			if ok != tt.wantHit {
+				t.Fatalf("got a hit: %v, want %v", ok, tt.wantHit)
+			}
+			if ok && (got.StatusCode != entry.StatusCode || string(got.Body) != "users") {
+				t.Errorf("got entry %+v", got)
+			}
+		})
+	}
+}
+
+func TestStorePurge(t *testing.T) {
+	acme := request("/", "acme", nil).Context()
+	beta := request("/", "beta", nil).Context()
+
+	type cached struct {
+		url, slug, route string
+		tags             []string
+	}
+	entries := []cached{
+		{url: "/users/1", slug: "acme", route: "/users/:id", tags: []string{"user:1"}},
+		{url: "/users/2", slug: "acme", route: "/users/:id", tags: []string{"user:2"}},
+		{url: "/users", slug: "acme", route: "/users", tags: []string{"user:1", "user:2"}},
+		{url: "/users/1", slug: "beta", route: "/users/:id", tags: []string{"user:1"}},
+	}
+
+	tests := []struct {
+		name  string
+		purge func(s *Store) error
+		// wantKept lists whether each entry is still cached
+		wantKept []bool
+	}{
+		{name: "route", purge: func(s *Store) error { return s.PurgeRoute(acme, "/users/:id") }, wantKept: []bool{false, false, true, true}},
+		{name: "tag", purge: func(s *Store) error { return s.PurgeTags(acme, "user:1") }, wantKept: []bool{false, true, false, true}},
+		{name: "tags", purge: func(s *Store) error { return s.PurgeTags(acme, "user:1", "user:2") }, wantKept: []bool{false, false, false, true}},
+		{name: "other tenant", purge: func(s *Store) error { return s.PurgeRoute(beta, "/users/:id") }, wantKept: []bool{true, true, true, false}},
+		{name: "unknown route", purge: func(s *Store) error { return s.PurgeRoute(acme, "/posts") }, wantKept: []bool{true, true, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(Config{})
+			for _, e := range entries {
+				r := request(e.url, e.slug, nil)
+				entry := &Entry{StatusCode: http.StatusOK, StoredAt: time.Now()}
+				if err := store.Put(r.Context(), r, e.route, entry, nil, time.Minute, e.tags...); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := tt.purge(store); err != nil {
+				t.Fatal(err)
+			}
+			for i, e := range entries {
+				r := request(e.url, e.slug, nil)
+				if _, ok := store.Get(r.Context(), r); ok != tt.wantKept[i] {
+					t.Errorf("%s of %s: got cached %v, want %v", e.url, e.slug, ok, tt.wantKept[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewStoreDefaults(t *testing.T) {
+	config := newTestStore(Config{Vary: []string{"accept-language"}}).Config()
+	if config.Prefix != "httpcache" || config.TTL != time.Minute || config.MaxBodySize != 1<<20 || config.Vary[0] != "Accept-Language" {
+		t.Errorf("got config %+v", config)
+	}
+}
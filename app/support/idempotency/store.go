@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"context"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// Status is the lifecycle status of an idempotency record.
+type Status string
+
+const (
+	// StatusInFlight marks a request that is still being processed.
+	StatusInFlight Status = "in_flight"
+	// StatusCompleted marks a request whose response has been stored.
+	StatusCompleted Status = "completed"
+)
+
+// Config represents the idempotency configuration (config/idempotency.yaml).
+type Config struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Header       string        `mapstructure:"header"`
+	Prefix       string        `mapstructure:"prefix"`
+	TTL          time.Duration `mapstructure:"ttl"`
+	LockTimeout  time.Duration `mapstructure:"lock_timeout"`
+	MaxKeyLength int           `mapstructure:"max_key_length"`
+}
+
+// Record is the stored state of an idempotent request.
+type Record struct {
+	Status      Status      `json:"status"`
+	RequestHash string      `json:"request_hash"`
+	StatusCode  int         `json:"status_code,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// CacheBackend is the subset of a dg-cache store used by Store.
+type CacheBackend interface {
+	GetAs(ctx context.Context, key string, dest interface{}) error
+	Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Forget(ctx context.Context, key string) error
+}
+
+// Store persists idempotency records in a dg-cache store.
+type Store struct {
+	config Config
+	cache  CacheBackend
+	locks  [64]sync.Mutex
+}
+
+// NewStore creates a new idempotency store.
+func NewStore(config Config, cache CacheBackend) *Store {
+	if config.Header == "" {
+		config.Header = "Idempotency-Key"
+	}
+	if config.Prefix == "" {
+		config.Prefix = "idempotency"
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = time.Minute
+	}
+	if config.MaxKeyLength <= 0 {
+		config.MaxKeyLength = 255
+	}
+
+	return &Store{
+		config: config,
+		cache:  cache,
+	}
+}
+
+// Config returns the effective configuration.
+func (s *Store) Config() Config {
+	return s.config
+}
+
+// Begin looks up the record for key and, if none exists, claims it by storing
+// an in-flight record. It returns the existing record (if any) and whether the
+// caller now owns the key.
+//
+// Claims are serialized per key within a process; across instances the
+// check-and-set is best effort since dg-cache has no compare-and-swap.
+func (s *Store) Begin(ctx context.Context, key, requestHash string) (*Record, bool, error) {
+	lock := s.lockFor(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	var existing Record
+	if err := s.cache.GetAs(ctx, s.cacheKey(key), &existing); err == nil && existing.Status != "" {
+		return &existing, false, nil
+	}
+
+	record := &Record{
+		Status:      StatusInFlight,
+		RequestHash: requestHash,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.cache.Put(ctx, s.cacheKey(key), record, s.config.LockTimeout); err != nil {
+		return nil, false, err
+	}
+
+	return nil, true, nil
+}
+
+// Complete stores the final response for key.
+func (s *Store) Complete(ctx context.Context, key string, record *Record) error {
+	record.Status = StatusCompleted
+	return s.cache.Put(ctx, s.cacheKey(key), record, s.config.TTL)
+}
+
+// Release removes the record for key so the request can be retried.
+func (s *Store) Release(ctx context.Context, key string) error {
+	return s.cache.Forget(ctx, s.cacheKey(key))
+}
+
+func (s *Store) cacheKey(key string) string {
+	return s.config.Prefix + ":" + key
+}
+
+func (s *Store) lockFor(key string) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &s.locks[h.Sum32()%uint32(len(s.locks))]
+}
+
+// MustResolve resolves the idempotency store from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolve(app foundation.Application) *Store {
+	store, err := app.Make("idempotency")
+	if err != nil {
+		panic("failed to resolve idempotency store: " + err.Error())
+	}
+	return store.(*Store)
+}
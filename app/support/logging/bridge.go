@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Writer returns a writer for loggers that can only be given an output, such as the
+// framework logger: it reads the JSON records they write and hands them to the
+// component logger, so they get its level, redaction and the configured outputs
+// The logger writing to it must use the JSON format
+func (l *Logger) Writer(component string) io.Writer {
+	return &jsonWriter{handler: l.Component(component).Handler()}
+}
+
+// ComponentLevel returns the level of the component, or the default level
+func (l *Logger) ComponentLevel(name string) slog.Level {
+	if level, ok := l.levels[name]; ok {
+		return level
+	}
+	return l.level
+}
+
+// jsonWriter replays JSON log lines as records of handler
+type jsonWriter struct {
+	handler slog.Handler
+}
+
+func (w *jsonWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := w.handle(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// handle replays one line; a line that is not a JSON record is kept as the message
+func (w *jsonWriter) handle(line []byte) error {
+	var fields map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		fields = map[string]any{slog.MessageKey: string(bytes.TrimSpace(line))}
+	}
+
+	level := slog.LevelInfo
+	if name, ok := fields[slog.LevelKey].(string); ok {
+		_ = level.UnmarshalText([]byte(name))
+	}
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	at := time.Now()
+	if value, ok := fields[slog.TimeKey].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			at = t
+		}
+	}
+	message, _ := fields[slog.MessageKey].(string)
+	delete(fields, slog.TimeKey)
+	delete(fields, slog.LevelKey)
+	delete(fields, slog.MessageKey)
+
+	record := slog.NewRecord(at, level, message, 0)
+	record.AddAttrs(jsonAttrs(fields)...)
+	return w.handler.Handle(ctx, record)
+}
+
+// jsonAttrs converts decoded JSON fields to attributes, objects to groups, in key order
+func jsonAttrs(fields map[string]any) []slog.Attr {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		switch value := fields[key].(type) {
+		case map[string]any:
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(jsonAttrs(value)...)})
+		case json.Number:
+			if n, err := value.Int64(); err == nil {
+				attrs = append(attrs, slog.Int64(key, n))
+			} else if f, err := value.Float64(); err == nil {
+				attrs = append(attrs, slog.Float64(key, f))
+			} else {
+				attrs = append(attrs, slog.String(key, value.String()))
+			}
+		default:
+			attrs = append(attrs, slog.Any(key, value))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		// log writes through a JSON logger on the writer, as the framework logger does
+		log  func(l *slog.Logger)
+		want map[string]any
+		// wantNothing is set when the record is below the component level
+		wantNothing bool
+	}{
+		{
+			name: "record replayed in the component",
+			log:  func(l *slog.Logger) { l.Warn("queue stalled", "jobs", 3, "ratio", 0.5) },
+			want: map[string]any{"msg": "queue stalled", "level": "WARN", "component": "framework", "jobs": float64(3), "ratio": 0.5},
+		},
+		{
+			name: "redacted",
+			log:  func(l *slog.Logger) { l.Info("connected", "password", "secret", slog.Group("db", "token", "abc")) },
+			want: map[string]any{"password": "[REDACTED]", "db.token": "[REDACTED]"},
+		},
+		{
+			name:        "filtered at the component level",
+			log:         func(l *slog.Logger) { l.Info("booting") },
+			wantNothing: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l, err := New(Config{Components: map[string]string{"framework": "info"}, Redact: RedactConfig{Enabled: true}}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.handler = newRedactHandler(slog.NewJSONHandler(&buf, nil), RedactConfig{})
+			if tt.wantNothing {
+				l.levels["framework"] = slog.LevelWarn
+			}
+
+			framework := slog.New(slog.NewJSONHandler(l.Writer("framework"), &slog.HandlerOptions{Level: slog.LevelDebug}))
+			tt.log(framework)
+
+			if tt.wantNothing {
+				if buf.Len() > 0 {
+					t.Errorf("got %s, want nothing", buf.String())
+				}
+				return
+			}
+			fields := decode(t, &buf)
+			for path, want := range tt.want {
+				if got := lookup(fields, path); got != want {
+					t.Errorf("%s: got %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriterPlainText(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(Config{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.handler = slog.NewJSONHandler(&buf, nil)
+
+	if _, err := l.Writer("framework").Write([]byte("plain line\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := lookup(decode(t, &buf), "msg"); got != "plain line" {
+		t.Errorf("got message %v, want the line", got)
+	}
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Config represents the logging configuration (config/logging.yaml)
+type Config struct {
+	// Level is the default level; empty means debug when app.debug is true, info otherwise
+	Level      string            `mapstructure:"level"`
+	Format     string            `mapstructure:"format"`
+	AddSource  bool              `mapstructure:"add_source"`
+	Outputs    []OutputConfig    `mapstructure:"outputs"`
+	Components map[string]string `mapstructure:"components"`
+	Redact     RedactConfig      `mapstructure:"redact"`
+
+	// SlowQueryThreshold is the duration above which queries are logged at warn
+	SlowQueryThreshold time.Duration `mapstructure:"slow_query_threshold"`
+}
+
+// OutputConfig configures a log output target
+type OutputConfig struct {
+	Type       string `mapstructure:"type"`
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	MaxAgeDays int    `mapstructure:"max_age_days"`
+	Compress   bool   `mapstructure:"compress"`
+}
+
+// RedactConfig configures masking of sensitive attributes
+type RedactConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Mask    string   `mapstructure:"mask"`
+	Fields  []string `mapstructure:"fields"`
+}
+
+// DefaultRedactFields are masked when redaction is enabled without an explicit field list
+var DefaultRedactFields = []string{
+	"password", "password_confirmation", "token", "secret",
+	"api_key", "authorization", "cookie", "email",
+}
+
+// ParseLevel parses a level name (debug, info, warn, error)
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unsupported log level: %s", name)
+	}
+}
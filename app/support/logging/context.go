@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MetaRequestID is the job metadata key carrying the originating request ID
+const MetaRequestID = "request_id"
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// WithContext returns a copy of ctx carrying the logger
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// With returns a copy of ctx whose logger has the given attributes added
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, loggerFrom(ctx).With(args...))
+}
+
+// FromContext returns the request-scoped logger carried by ctx, falling back to the default logger
+// The trace and span IDs of the active span are added so log lines can be joined with traces
+func FromContext(ctx context.Context) *slog.Logger {
+	logger := loggerFrom(ctx)
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logger = logger.With(
+			"trace_id", sc.TraceID().String(),
+			"span_id", sc.SpanID().String(),
+		)
+	}
+	return logger
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
+
+func traceIDFrom(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
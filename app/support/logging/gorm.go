@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger writes GORM logs through slog, tagged with the request-scoped attributes of the query context
+// Failed queries log at error, slow queries at warn and every query at debug, always without their parameters
+type GormLogger struct {
+	logger        *slog.Logger
+	slowThreshold time.Duration
+}
+
+// NewGormLogger creates a GORM logger on top of the given logger
+func NewGormLogger(logger *slog.Logger, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{logger: logger, slowThreshold: slowThreshold}
+}
+
+// LogMode is a no-op; verbosity follows the slog level of the "db" component
+func (l *GormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+// Info logs an informational message
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.from(ctx).InfoContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Warn logs a warning
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.from(ctx).WarnContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Error logs an error
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.from(ctx).ErrorContext(ctx, fmt.Sprintf(msg, args...))
+}
+
+// Trace logs a completed query
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	logger := l.from(ctx)
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		if !logger.Enabled(ctx, slog.LevelError) {
+			return
+		}
+		sql, rows := fc()
+		logger.ErrorContext(ctx, "Query failed", "sql", sql, "rows", rows, "duration", elapsed, "error", err)
+
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		if !logger.Enabled(ctx, slog.LevelWarn) {
+			return
+		}
+		sql, rows := fc()
+		logger.WarnContext(ctx, "Slow query", "sql", sql, "rows", rows, "duration", elapsed, "threshold", l.slowThreshold)
+
+	default:
+		if !logger.Enabled(ctx, slog.LevelDebug) {
+			return
+		}
+		sql, rows := fc()
+		logger.DebugContext(ctx, "Query executed", "sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
+
+// ParamsFilter drops the query parameters, so queries are logged with placeholders
+// instead of the values they write or match, such as emails and password hashes
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// from adds the request ID and trace ID of the query context to the logger
+func (l *GormLogger) from(ctx context.Context) *slog.Logger {
+	logger := l.logger
+	if id := RequestID(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if traceID := traceIDFrom(ctx); traceID != "" {
+		logger = logger.With("trace_id", traceID)
+	}
+	return logger
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// dryRunDialector builds SQL with ? placeholders and never runs it
+type dryRunDialector struct{}
+
+func (dryRunDialector) Name() string { return "dryrun" }
+
+func (dryRunDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+
+func (dryRunDialector) Migrator(db *gorm.DB) gorm.Migrator { return nil }
+
+func (dryRunDialector) DataTypeOf(*schema.Field) string { return "" }
+
+func (dryRunDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (dryRunDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	_ = writer.WriteByte('?')
+}
+
+func (dryRunDialector) QuoteTo(writer clause.Writer, str string) {
+	_, _ = writer.WriteString(`"` + str + `"`)
+}
+
+func (dryRunDialector) Explain(sql string, vars ...interface{}) string {
+	return gormlogger.ExplainSQL(sql, nil, `'`, vars...)
+}
+
+func TestGormLoggerOmitsParameters(t *testing.T) {
+	type account struct {
+		ID           uint
+		Email        string
+		PasswordHash string
+	}
+
+	var buf bytes.Buffer
+	logger := NewGormLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})), 0)
+	db, err := gorm.Open(dryRunDialector{}, &gorm.Config{DryRun: true, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Create(&account{Email: "ada@example.com", PasswordHash: "$2a$10$hash"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("email = ?", "ada@example.com").First(&account{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logged := buf.String()
+	for _, value := range []string{"ada@example.com", "$2a$10$hash"} {
+		if strings.Contains(logged, value) {
+			t.Errorf("the log contains %q:\n%s", value, logged)
+		}
+	}
+	if !strings.Contains(logged, "INSERT INTO") || !strings.Contains(logged, "email = ?") {
+		t.Errorf("the queries were not logged with placeholders:\n%s", logged)
+	}
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// levelHandler filters records below its own minimum level, so each component
+// can be more or less verbose than the shared output handler's default
+type levelHandler struct {
+	level   slog.Leveler
+	handler slog.Handler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithGroup(name)}
+}
+
+// redactHandler masks the values of sensitive attributes before they reach the output
+// Keys are matched case-insensitively, at any group depth
+type redactHandler struct {
+	fields  map[string]struct{}
+	mask    string
+	handler slog.Handler
+}
+
+func newRedactHandler(handler slog.Handler, config RedactConfig) slog.Handler {
+	fields := config.Fields
+	if len(fields) == 0 {
+		fields = DefaultRedactFields
+	}
+	mask := config.Mask
+	if mask == "" {
+		mask = "[REDACTED]"
+	}
+
+	h := &redactHandler{fields: make(map[string]struct{}, len(fields)), mask: mask, handler: handler}
+	for _, field := range fields {
+		h.fields[strings.ToLower(field)] = struct{}{}
+	}
+	return h
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redact(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redact(attr)
+	}
+	return &redactHandler{fields: h.fields, mask: h.mask, handler: h.handler.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{fields: h.fields, mask: h.mask, handler: h.handler.WithGroup(name)}
+}
+
+func (h *redactHandler) redact(attr slog.Attr) slog.Attr {
+	if _, ok := h.fields[strings.ToLower(attr.Key)]; ok {
+		return slog.String(attr.Key, h.mask)
+	}
+
+	value := attr.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+
+	group := value.Group()
+	redacted := make([]any, len(group))
+	for i, member := range group {
+		redacted[i] = h.redact(member)
+	}
+	return slog.Group(attr.Key, redacted...)
+}
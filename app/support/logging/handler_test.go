@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// jsonLogger returns a logger writing JSON through newRedactHandler into buf
+func jsonLogger(buf *bytes.Buffer, config RedactConfig) *slog.Logger {
+	return slog.New(newRedactHandler(slog.NewJSONHandler(buf, nil), config))
+}
+
+// decode returns the fields of the single JSON record in buf
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var fields map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("%v: %s", err, buf)
+	}
+	return fields
+}
+
+func TestRedactHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RedactConfig
+		log    func(l *slog.Logger)
+		// want maps a dotted path to the expected value
+		want map[string]any
+	}{
+		{
+			name: "default fields",
+			log:  func(l *slog.Logger) { l.Info("login", "email", "ada@example.com", "password", "secret", "user_id", 1) },
+			want: map[string]any{"email": "[REDACTED]", "password": "[REDACTED]", "user_id": float64(1)},
+		},
+		{
+			name: "keys match case-insensitively",
+			log:  func(l *slog.Logger) { l.Info("request", "Authorization", "Bearer abc") },
+			want: map[string]any{"Authorization": "[REDACTED]"},
+		},
+		{
+			name: "nested groups",
+			log: func(l *slog.Logger) {
+				l.Info("request", slog.Group("headers", "cookie", "session=1", "accept", "*/*"))
+			},
+			want: map[string]any{"headers.cookie": "[REDACTED]", "headers.accept": "*/*"},
+		},
+		{
+			name: "attributes added with With",
+			log:  func(l *slog.Logger) { l.With("token", "abc").Info("job") },
+			want: map[string]any{"token": "[REDACTED]"},
+		},
+		{
+			name: "attributes under a group",
+			log:  func(l *slog.Logger) { l.WithGroup("user").Info("created", "email", "ada@example.com") },
+			want: map[string]any{"user.email": "[REDACTED]"},
+		},
+		{
+			name:   "configured fields and mask",
+			config: RedactConfig{Fields: []string{"ssn"}, Mask: "***"},
+			log:    func(l *slog.Logger) { l.Info("profile", "ssn", "123", "email", "ada@example.com") },
+			want:   map[string]any{"ssn": "***", "email": "ada@example.com"},
+		},
+		{
+			name: "lazy values are resolved before matching",
+			log:  func(l *slog.Logger) { l.Info("profile", slog.Any("account", secretValuer{})) },
+			want: map[string]any{"account.secret": "[REDACTED]", "account.name": "ada"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(jsonLogger(&buf, tt.config))
+			fields := decode(t, &buf)
+
+			for path, want := range tt.want {
+				if got := lookup(fields, path); got != want {
+					t.Errorf("%s: got %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+// secretValuer logs as a group holding a secret
+type secretValuer struct{}
+
+func (secretValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", "ada"), slog.String("secret", "s3cr3t"))
+}
+
+// lookup returns the value at a dotted path of decoded JSON
+func lookup(fields map[string]any, path string) any {
+	var value any = fields
+	for _, key := range strings.Split(path, ".") {
+		group, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = group[key]
+	}
+	return value
+}
+
+func TestComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(Config{Format: "json", Components: map[string]string{"db": "warn", "queue": "debug"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.handler = slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+
+	tests := []struct {
+		name   string
+		logger *slog.Logger
+		level  slog.Level
+		want   bool
+	}{
+		{name: "default level", logger: l.Component("http"), level: slog.LevelInfo, want: true},
+		{name: "below the default level", logger: l.Component("http"), level: slog.LevelDebug, want: false},
+		{name: "below a stricter component", logger: l.Component("db"), level: slog.LevelInfo, want: false},
+		{name: "at a stricter component", logger: l.Component("db"), level: slog.LevelWarn, want: true},
+		{name: "verbose component", logger: l.Component("queue"), level: slog.LevelDebug, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.logger.Log(context.Background(), tt.level, "message")
+			if got := buf.Len() > 0; got != tt.want {
+				t.Errorf("got written %v, want %v: %s", got, tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "unknown level", config: Config{Level: "verbose"}},
+		{name: "unknown component level", config: Config{Components: map[string]string{"db": "loud"}}},
+		{name: "unknown format", config: Config{Format: "xml"}},
+		{name: "unknown output", config: Config{Outputs: []OutputConfig{{Type: "syslog"}}}},
+		{name: "file without a path", config: Config{Outputs: []OutputConfig{{Type: "file"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config, false); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
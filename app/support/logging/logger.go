@@ -0,0 +1,190 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Logger builds the application log handlers from configuration:
+// output targets, format, per-component levels and redaction
+type Logger struct {
+	format  string
+	level   slog.Level
+	output  io.Writer
+	closers []io.Closer
+	handler slog.Handler
+	root    *slog.Logger
+
+	levels     map[string]slog.Level
+	mu         sync.Mutex
+	components map[string]*slog.Logger
+}
+
+var std atomic.Pointer[Logger]
+
+// New creates a logger from configuration
+// When no level is configured, debug mode selects the debug level
+func New(config Config, debug bool) (*Logger, error) {
+	levelName := config.Level
+	if levelName == "" && debug {
+		levelName = "debug"
+	}
+	level, err := ParseLevel(levelName)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Logger{
+		format:     config.Format,
+		level:      level,
+		levels:     make(map[string]slog.Level, len(config.Components)),
+		components: make(map[string]*slog.Logger),
+	}
+
+	// The output handler accepts the most verbose configured level;
+	// each logger then filters at its own level
+	minLevel := level
+	for component, name := range config.Components {
+		componentLevel, err := ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("component '%s': %w", component, err)
+		}
+		l.levels[component] = componentLevel
+		minLevel = min(minLevel, componentLevel)
+	}
+
+	if err := l.openOutputs(config.Outputs); err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: minLevel, AddSource: config.AddSource || debug}
+	switch config.Format {
+	case "", "text":
+		l.handler = slog.NewTextHandler(l.output, opts)
+	case "json":
+		l.handler = slog.NewJSONHandler(l.output, opts)
+	default:
+		_ = l.Close()
+		return nil, fmt.Errorf("unsupported log format: %s", config.Format)
+	}
+
+	if config.Redact.Enabled {
+		l.handler = newRedactHandler(l.handler, config.Redact)
+	}
+
+	l.root = slog.New(&levelHandler{level: level, handler: l.handler})
+	return l, nil
+}
+
+// openOutputs opens every output target; stdout is used when none is configured
+func (l *Logger) openOutputs(outputs []OutputConfig) error {
+	if len(outputs) == 0 {
+		l.output = os.Stdout
+		return nil
+	}
+
+	writers := make([]io.Writer, 0, len(outputs))
+	for _, output := range outputs {
+		switch output.Type {
+		case "", "stdout":
+			writers = append(writers, os.Stdout)
+
+		case "stderr":
+			writers = append(writers, os.Stderr)
+
+		case "file":
+			if output.Path == "" {
+				_ = l.Close()
+				return fmt.Errorf("file log output requires a path")
+			}
+			// Rotates by size; old files are pruned by count and age
+			file := &lumberjack.Logger{
+				Filename:   output.Path,
+				MaxSize:    output.MaxSizeMB,
+				MaxBackups: output.MaxBackups,
+				MaxAge:     output.MaxAgeDays,
+				Compress:   output.Compress,
+			}
+			writers = append(writers, file)
+			l.closers = append(l.closers, file)
+
+		default:
+			_ = l.Close()
+			return fmt.Errorf("unsupported log output: %s", output.Type)
+		}
+	}
+
+	l.output = io.MultiWriter(writers...)
+	return nil
+}
+
+// Slog returns the root logger
+func (l *Logger) Slog() *slog.Logger {
+	return l.root
+}
+
+// Component returns a logger tagged with the component name,
+// filtered at the component's configured level (or the default level)
+func (l *Logger) Component(name string) *slog.Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if logger, ok := l.components[name]; ok {
+		return logger
+	}
+
+	level, ok := l.levels[name]
+	if !ok {
+		level = l.level
+	}
+	logger := slog.New(&levelHandler{level: level, handler: l.handler}).With("component", name)
+	l.components[name] = logger
+	return logger
+}
+
+// Level returns the default level
+func (l *Logger) Level() slog.Level {
+	return l.level
+}
+
+// Output returns the writer all records are written to
+func (l *Logger) Output() io.Writer {
+	return l.output
+}
+
+// JSON reports whether records are written as JSON
+func (l *Logger) JSON() bool {
+	return l.format == "json"
+}
+
+// Close closes file outputs
+func (l *Logger) Close() error {
+	var firstErr error
+	for _, closer := range l.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SetDefault makes the logger the process default, for slog.Default() and Component()
+func SetDefault(l *Logger) {
+	std.Store(l)
+	slog.SetDefault(l.root)
+}
+
+// Component returns a component logger from the default logger
+// It falls back to slog.Default() before SetDefault is called
+func Component(name string) *slog.Logger {
+	if l := std.Load(); l != nil {
+		return l.Component(name)
+	}
+	return slog.Default().With("component", name)
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+	"time"
+)
+
+// CacheStore is the subset of a dg-cache store that can be instrumented.
+type CacheStore interface {
+	GetAs(ctx context.Context, key string, dest interface{}) error
+	Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Forget(ctx context.Context, key string) error
+}
+
+// InstrumentCache wraps a cache store so lookups are counted as hits or misses.
+func (m *Metrics) InstrumentCache(store CacheStore) CacheStore {
+	if !m.Enabled() {
+		return store
+	}
+	return &instrumentedCache{CacheStore: store, metrics: m}
+}
+
+type instrumentedCache struct {
+	CacheStore
+	metrics *Metrics
+}
+
+// GetAs records a hit when the value was found and a miss otherwise.
+func (c *instrumentedCache) GetAs(ctx context.Context, key string, dest interface{}) error {
+	err := c.CacheStore.GetAs(ctx, key, dest)
+	if err != nil {
+		c.metrics.CacheMiss(key)
+	} else {
+		c.metrics.CacheHit(key)
+	}
+	return err
+}
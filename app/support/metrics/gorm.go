@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:query_start"
+
+// GormPlugin returns a GORM plugin that records query durations.
+func (m *Metrics) GormPlugin() gorm.Plugin {
+	return &gormPlugin{metrics: m}
+}
+
+type gormPlugin struct {
+	metrics *Metrics
+}
+
+// Name returns the plugin name.
+func (p *gormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registers before/after callbacks for every GORM operation.
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, hook := range hooks {
+		if err := hook.before("metrics:before_"+hook.operation, p.before); err != nil {
+			return err
+		}
+		if err := hook.after("metrics:after_"+hook.operation, p.after(hook.operation)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *gormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func (p *gormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		start, isTime := value.(time.Time)
+		if !ok || !isTime {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+		p.metrics.ObserveQuery(operation, table, time.Since(start), failed)
+	}
+}
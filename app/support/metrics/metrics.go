@@ -0,0 +1,243 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Config represents the metrics configuration (config/metrics.yaml).
+type Config struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Namespace  string `mapstructure:"namespace"`
+	Path       string `mapstructure:"path"`
+	ListenAddr string `mapstructure:"listen_addr"`
+	// AllowedIPs are the IPs or CIDR ranges allowed to scrape the endpoint
+	AllowedIPs []string `mapstructure:"allowed_ips"`
+	// Token is required as an Authorization bearer token when set
+	Token string `mapstructure:"token"`
+}
+
+// Guarded reports whether access to the endpoint is restricted by IP or token.
+func (c Config) Guarded() bool {
+	return len(c.AllowedIPs) > 0 || c.Token != ""
+}
+
+// Metrics holds all application collectors.
+//
+// All methods are safe to call when metrics are disabled; they become no-ops.
+type Metrics struct {
+	config   Config
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	dbDuration *prometheus.HistogramVec
+	dbErrors   *prometheus.CounterVec
+
+	cacheRequests *prometheus.CounterVec
+
+	queueJobs     *prometheus.CounterVec
+	queueDuration *prometheus.HistogramVec
+
+	scheduledDuration *prometheus.HistogramVec
+	scheduledFailures *prometheus.CounterVec
+}
+
+// New creates the collectors and registers them on a dedicated registry.
+func New(config Config) *Metrics {
+	if config.Namespace == "" {
+		config.Namespace = "app"
+	}
+	if config.Path == "" {
+		config.Path = "/metrics"
+	}
+
+	m := &Metrics{config: config}
+	if !config.Enabled {
+		return m
+	}
+
+	ns := config.Namespace
+	m.registry = prometheus.NewRegistry()
+
+	m.httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns, Subsystem: "http", Name: "requests_total",
+		Help: "Total HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+	m.httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns, Subsystem: "http", Name: "request_duration_seconds",
+		Help:    "HTTP request latency by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	m.dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns, Subsystem: "db", Name: "query_duration_seconds",
+		Help:    "GORM query duration by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+	m.dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns, Subsystem: "db", Name: "query_errors_total",
+		Help: "GORM queries that returned an error (excluding record not found).",
+	}, []string{"operation", "table"})
+
+	m.cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns, Subsystem: "cache", Name: "requests_total",
+		Help: "Cache lookups by key group (key prefix before ':') and result (hit, miss).",
+	}, []string{"key", "result"})
+
+	m.queueJobs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns, Subsystem: "queue", Name: "jobs_total",
+		Help: "Queue jobs by name and status (dispatched, dispatch_failed, processed, failed).",
+	}, []string{"job", "status"})
+	m.queueDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns, Subsystem: "queue", Name: "job_duration_seconds",
+		Help:    "Queue job processing duration by name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"job"})
+
+	m.scheduledDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: ns, Subsystem: "scheduler", Name: "job_duration_seconds",
+		Help:    "Scheduled job run duration by name.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"job"})
+	m.scheduledFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: ns, Subsystem: "scheduler", Name: "job_failures_total",
+		Help: "Scheduled job runs that returned an error.",
+	}, []string{"job"})
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration,
+		m.dbDuration, m.dbErrors,
+		m.cacheRequests,
+		m.queueJobs, m.queueDuration,
+		m.scheduledDuration, m.scheduledFailures,
+	)
+
+	return m
+}
+
+// Enabled reports whether metrics are collected.
+func (m *Metrics) Enabled() bool {
+	return m != nil && m.config.Enabled
+}
+
+// Config returns the effective configuration.
+func (m *Metrics) Config() Config {
+	return m.config
+}
+
+// Handler returns the Prometheus exposition handler.
+func (m *Metrics) Handler() http.Handler {
+	if !m.Enabled() {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTP records a completed HTTP request.
+func (m *Metrics) ObserveHTTP(method, route string, status int, duration time.Duration) {
+	if !m.Enabled() {
+		return
+	}
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveQuery records a completed database query.
+func (m *Metrics) ObserveQuery(operation, table string, duration time.Duration, failed bool) {
+	if !m.Enabled() {
+		return
+	}
+	m.dbDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+	if failed {
+		m.dbErrors.WithLabelValues(operation, table).Inc()
+	}
+}
+
+// CacheHit records a cache hit for the key's group.
+func (m *Metrics) CacheHit(key string) {
+	if !m.Enabled() {
+		return
+	}
+	m.cacheRequests.WithLabelValues(keyGroup(key), "hit").Inc()
+}
+
+// CacheMiss records a cache miss for the key's group.
+func (m *Metrics) CacheMiss(key string) {
+	if !m.Enabled() {
+		return
+	}
+	m.cacheRequests.WithLabelValues(keyGroup(key), "miss").Inc()
+}
+
+// JobDispatched records a queue dispatch attempt.
+func (m *Metrics) JobDispatched(job string, err error) {
+	if !m.Enabled() {
+		return
+	}
+	status := "dispatched"
+	if err != nil {
+		status = "dispatch_failed"
+	}
+	m.queueJobs.WithLabelValues(job, status).Inc()
+}
+
+// ObserveJob runs a queue job handler and records its outcome and duration.
+func (m *Metrics) ObserveJob(job string, handler func() error) error {
+	start := time.Now()
+	err := handler()
+	if !m.Enabled() {
+		return err
+	}
+
+	m.queueDuration.WithLabelValues(job).Observe(time.Since(start).Seconds())
+	status := "processed"
+	if err != nil {
+		status = "failed"
+	}
+	m.queueJobs.WithLabelValues(job, status).Inc()
+	return err
+}
+
+// ObserveScheduledJob runs a scheduled job and records its duration and failures.
+func (m *Metrics) ObserveScheduledJob(job string, handler func() error) error {
+	start := time.Now()
+	err := handler()
+	if !m.Enabled() {
+		return err
+	}
+
+	m.scheduledDuration.WithLabelValues(job).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.scheduledFailures.WithLabelValues(job).Inc()
+	}
+	return err
+}
+
+// keyGroup reduces a cache key to a low-cardinality label, e.g. "user:42" -> "user".
+func keyGroup(key string) string {
+	if group, _, ok := strings.Cut(key, ":"); ok {
+		return group
+	}
+	return "other"
+}
+
+// MustResolve resolves the metrics collectors from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolve(app foundation.Application) *Metrics {
+	m, err := app.Make("metrics")
+	if err != nil {
+		panic("failed to resolve metrics: " + err.Error())
+	}
+	return m.(*Metrics)
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scrape returns the exposition of the metrics
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d from the metrics handler", rec.Code)
+	}
+	body, err := io.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestMetricsLabels(t *testing.T) {
+	m := New(Config{Enabled: true, Namespace: "shop"})
+
+	m.ObserveHTTP("GET", "/api/v1/users/:id", 200, 10*time.Millisecond)
+	m.ObserveQuery("query", "users", time.Millisecond, false)
+	m.ObserveQuery("create", "users", time.Millisecond, true)
+	m.CacheHit("user:42")
+	m.CacheMiss("session")
+	m.JobDispatched("send_email", nil)
+	m.JobDispatched("send_email", errors.New("queue down"))
+	_ = m.ObserveJob("send_email", func() error { return nil })
+	_ = m.ObserveJob("send_email", func() error { return errors.New("failed") })
+	_ = m.ObserveScheduledJob("cleanup", func() error { return errors.New("failed") })
+
+	exposition := scrape(t, m)
+	for _, want := range []string{
+		`shop_http_requests_total{method="GET",route="/api/v1/users/:id",status="200"} 1`,
+		`shop_http_request_duration_seconds_count{method="GET",route="/api/v1/users/:id"} 1`,
+		`shop_db_query_duration_seconds_count{operation="query",table="users"} 1`,
+		`shop_db_query_errors_total{operation="create",table="users"} 1`,
+		// Cache keys are reduced to their group, so ids don't become labels
+		`shop_cache_requests_total{key="user",result="hit"} 1`,
+		`shop_cache_requests_total{key="other",result="miss"} 1`,
+		`shop_queue_jobs_total{job="send_email",status="dispatched"} 1`,
+		`shop_queue_jobs_total{job="send_email",status="dispatch_failed"} 1`,
+		`shop_queue_jobs_total{job="send_email",status="processed"} 1`,
+		`shop_queue_jobs_total{job="send_email",status="failed"} 1`,
+		`shop_queue_job_duration_seconds_count{job="send_email"} 2`,
+		`shop_scheduler_job_failures_total{job="cleanup"} 1`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(exposition, want) {
+			t.Errorf("the exposition lacks %s", want)
+		}
+	}
+	if strings.Contains(exposition, `shop_db_query_errors_total{operation="query"`) {
+		t.Error("a successful query was counted as an error")
+	}
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	var nilMetrics *Metrics
+	for _, m := range []*Metrics{New(Config{}), nilMetrics} {
+		if m.Enabled() {
+			t.Fatal("metrics are enabled without the config")
+		}
+
+		// Every method is a no-op, and the handlers still run
+		m.ObserveHTTP("GET", "/", 200, time.Millisecond)
+		m.ObserveQuery("query", "users", time.Millisecond, true)
+		m.CacheHit("user:1")
+		m.CacheMiss("user:1")
+		m.JobDispatched("job", nil)
+		ran := 0
+		_ = m.ObserveJob("job", func() error { ran++; return nil })
+		_ = m.ObserveScheduledJob("job", func() error { ran++; return nil })
+		if ran != 2 {
+			t.Errorf("got %d handlers run, want 2", ran)
+		}
+
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("got status %d from a disabled handler, want %d", rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestConfigDefaults(t *testing.T) {
+	m := New(Config{Enabled: true})
+	if m.Config().Namespace != "app" || m.Config().Path != "/metrics" {
+		t.Errorf("got namespace %q and path %q", m.Config().Namespace, m.Config().Path)
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		want   bool
+	}{
+		{name: "open", config: Config{}, want: false},
+		{name: "ip allow list", config: Config{AllowedIPs: []string{"10.0.0.0/8"}}, want: true},
+		{name: "token", config: Config{Token: "secret"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Guarded(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// mapCache is a cache store of a map, missing the keys it lacks
+type mapCache map[string]string
+
+func (c mapCache) GetAs(ctx context.Context, key string, dest interface{}) error {
+	value, ok := c[key]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	*dest.(*string) = value
+	return nil
+}
+
+func (c mapCache) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c[key] = value.(string)
+	return nil
+}
+
+func (c mapCache) Forget(ctx context.Context, key string) error {
+	delete(c, key)
+	return nil
+}
+
+func TestInstrumentCache(t *testing.T) {
+	ctx := context.Background()
+	m := New(Config{Enabled: true})
+	store := m.InstrumentCache(mapCache{"user:1": "ada"})
+
+	var value string
+	if err := store.GetAs(ctx, "user:1", &value); err != nil || value != "ada" {
+		t.Fatalf("got %q, %v", value, err)
+	}
+	if err := store.GetAs(ctx, "user:2", &value); err == nil {
+		t.Fatal("a missing key was found")
+	}
+
+	exposition := scrape(t, m)
+	for _, want := range []string{
+		`app_cache_requests_total{key="user",result="hit"} 1`,
+		`app_cache_requests_total{key="user",result="miss"} 1`,
+	} {
+		if !strings.Contains(exposition, want) {
+			t.Errorf("the exposition lacks %s", want)
+		}
+	}
+
+	// Disabled metrics leave the store as is
+	plain := mapCache{}
+	if got := New(Config{}).InstrumentCache(plain); got == nil {
+		t.Error("got no store")
+	} else if _, wrapped := got.(*instrumentedCache); wrapped {
+		t.Error("disabled metrics wrapped the store")
+	}
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// Algorithm identifies the throttling strategy used by a limiter.
+type Algorithm string
+
+const (
+	// TokenBucket refills tokens at a constant rate and allows bursts up to the bucket capacity.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow approximates a rolling window by weighting the previous fixed window.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// KeyBy identifies which part of the request a limiter is keyed on.
+type KeyBy string
+
+const (
+	// KeyByIP keys the limiter on the client IP address.
+	KeyByIP KeyBy = "ip"
+	// KeyByUser keys the limiter on the authenticated principal, a user or an API key client (falls back to IP).
+	KeyByUser KeyBy = "user"
+	// KeyByAPIKey keys the limiter on the API key presented by the client (falls back to IP).
+	KeyByAPIKey KeyBy = "api_key"
+)
+
+// Config represents the rate limiting configuration (config/ratelimit.yaml).
+type Config struct {
+	Enabled  bool                     `mapstructure:"enabled"`
+	Store    string                   `mapstructure:"store"`
+	Prefix   string                   `mapstructure:"prefix"`
+	Limiters map[string]LimiterConfig `mapstructure:"limiters"`
+}
+
+// LimiterConfig represents the configuration of a single named limiter.
+type LimiterConfig struct {
+	Algorithm Algorithm     `mapstructure:"algorithm"`
+	Limit     int           `mapstructure:"limit"`
+	Burst     int           `mapstructure:"burst"`
+	Window    time.Duration `mapstructure:"window"`
+	KeyBy     KeyBy         `mapstructure:"key_by"`
+}
+
+// withDefaults fills in optional fields and validates the limiter configuration.
+func (c LimiterConfig) withDefaults(name string) (LimiterConfig, error) {
+	if c.Algorithm == "" {
+		c.Algorithm = SlidingWindow
+	}
+	if c.KeyBy == "" {
+		c.KeyBy = KeyByIP
+	}
+	if c.Burst <= 0 {
+		c.Burst = c.Limit
+	}
+
+	switch c.Algorithm {
+	case TokenBucket, SlidingWindow:
+	default:
+		return c, fmt.Errorf("limiter '%s': unsupported algorithm: %s", name, c.Algorithm)
+	}
+
+	switch c.KeyBy {
+	case KeyByIP, KeyByUser, KeyByAPIKey:
+	default:
+		return c, fmt.Errorf("limiter '%s': unsupported key_by: %s", name, c.KeyBy)
+	}
+
+	if c.Limit <= 0 {
+		return c, fmt.Errorf("limiter '%s': limit must be greater than zero", name)
+	}
+	if c.Window <= 0 {
+		return c, fmt.Errorf("limiter '%s': window must be greater than zero", name)
+	}
+
+	return c, nil
+}
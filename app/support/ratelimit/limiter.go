@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// Result describes the outcome of a rate limit check.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter is a named rate limiter backed by a Store.
+//
+// Every request updates the state of its key atomically in the store, so instances
+// sharing a Redis store never admit more than the limit together.
+type Limiter struct {
+	name   string
+	prefix string
+	config LimiterConfig
+	store  Store
+	now    func() time.Time
+}
+
+// NewLimiter creates a new limiter with the given configuration.
+func NewLimiter(name, prefix string, config LimiterConfig, store Store) (*Limiter, error) {
+	config, err := config.withDefaults(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		name:   name,
+		prefix: prefix,
+		config: config,
+		store:  store,
+		now:    time.Now,
+	}, nil
+}
+
+// Name returns the limiter name.
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+// KeyBy returns the request attribute this limiter is keyed on.
+func (l *Limiter) KeyBy() KeyBy {
+	return l.config.KeyBy
+}
+
+// Allow consumes one request for the given identity and reports whether it is allowed.
+// A key too contended to update is rejected, since it is being hammered.
+func (l *Limiter) Allow(ctx context.Context, identity string) (Result, error) {
+	var result Result
+	err := l.store.Update(ctx, l.storageKey(identity), func(state *State) (*State, time.Duration) {
+		var ttl time.Duration
+		switch l.config.Algorithm {
+		case TokenBucket:
+			state, result, ttl = l.takeToken(state)
+		default:
+			state, result, ttl = l.slideWindow(state)
+		}
+		return state, ttl
+	})
+	if errors.Is(err, ErrContended) {
+		limit := l.config.Limit
+		if l.config.Algorithm == TokenBucket {
+			limit = l.config.Burst
+		}
+		return Result{Limit: limit, ResetAfter: time.Second, RetryAfter: time.Second}, nil
+	}
+	return result, err
+}
+
+// takeToken applies the token bucket algorithm.
+func (l *Limiter) takeToken(state *State) (*State, Result, time.Duration) {
+	now := l.now()
+	capacity := float64(l.config.Burst)
+	rate := float64(l.config.Limit) / l.config.Window.Seconds() // tokens per second
+
+	if state == nil {
+		state = &State{Tokens: capacity, UpdatedAt: now}
+	}
+
+	if elapsed := now.Sub(state.UpdatedAt).Seconds(); elapsed > 0 {
+		state.Tokens = math.Min(capacity, state.Tokens+elapsed*rate)
+	}
+	state.UpdatedAt = now
+
+	result := Result{Limit: l.config.Burst}
+	if state.Tokens >= 1 {
+		state.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - state.Tokens) / rate)
+	}
+
+	result.Remaining = int(state.Tokens)
+	result.ResetAfter = seconds((capacity - state.Tokens) / rate)
+
+	return state, result, seconds(capacity/rate) + time.Second
+}
+
+// slideWindow applies the sliding window counter algorithm.
+func (l *Limiter) slideWindow(state *State) (*State, Result, time.Duration) {
+	now := l.now()
+	window := l.config.Window
+	start := now.Truncate(window)
+
+	if state == nil {
+		state = &State{WindowStart: start}
+	}
+
+	if !state.WindowStart.Equal(start) {
+		if start.Sub(state.WindowStart) == window {
+			state.Previous = state.Current
+		} else {
+			state.Previous = 0
+		}
+		state.Current = 0
+		state.WindowStart = start
+	}
+	state.UpdatedAt = now
+
+	elapsed := float64(now.Sub(start)) / float64(window)
+	estimated := float64(state.Previous)*(1-elapsed) + float64(state.Current)
+
+	limit := float64(l.config.Limit)
+	result := Result{Limit: l.config.Limit}
+	if estimated+1 <= limit {
+		state.Current++
+		estimated++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.windowRetryAfter(state, elapsed)
+	}
+
+	result.Remaining = int(math.Max(0, math.Floor(limit-estimated)))
+	result.ResetAfter = start.Add(window).Sub(now)
+
+	return state, result, 2 * window
+}
+
+// windowRetryAfter calculates how long until the weighted count drops enough to allow a request.
+func (l *Limiter) windowRetryAfter(state *State, elapsed float64) time.Duration {
+	window := l.config.Window
+	untilNextWindow := time.Duration((1 - elapsed) * float64(window))
+
+	// The current window alone exhausts the limit; wait for it to roll over.
+	if state.Current+1 > l.config.Limit || state.Previous == 0 {
+		return untilNextWindow
+	}
+
+	// Solve previous*(1-t) + current + 1 <= limit for t (fraction of the window).
+	t := 1 - float64(l.config.Limit-state.Current-1)/float64(state.Previous)
+	if t <= elapsed {
+		return time.Second
+	}
+	return time.Duration((t - elapsed) * float64(window))
+}
+
+func (l *Limiter) storageKey(identity string) string {
+	return l.prefix + ":" + l.name + ":" + identity
+}
+
+// seconds converts fractional seconds to a duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
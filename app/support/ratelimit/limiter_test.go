@@ -0,0 +1,186 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config LimiterConfig
+		// at are the request times, relative to start
+		at   []time.Duration
+		want []bool
+	}{
+		{
+			name:   "sliding window within the limit",
+			config: LimiterConfig{Limit: 3, Window: time.Minute},
+			at:     []time.Duration{0, time.Second, 2 * time.Second},
+			want:   []bool{true, true, true},
+		},
+		{
+			name:   "sliding window over the limit",
+			config: LimiterConfig{Limit: 2, Window: time.Minute},
+			at:     []time.Duration{0, time.Second, 2 * time.Second},
+			want:   []bool{true, true, false},
+		},
+		{
+			name:   "sliding window weights the previous window",
+			config: LimiterConfig{Limit: 2, Window: time.Minute},
+			// Two requests late in the first window still count at the start of the next one
+			at:   []time.Duration{50 * time.Second, 55 * time.Second, 61 * time.Second, 119 * time.Second},
+			want: []bool{true, true, false, true},
+		},
+		{
+			name:   "sliding window forgets windows older than the previous one",
+			config: LimiterConfig{Limit: 1, Window: time.Minute},
+			at:     []time.Duration{0, 3 * time.Minute},
+			want:   []bool{true, true},
+		},
+		{
+			name:   "token bucket burst",
+			config: LimiterConfig{Algorithm: TokenBucket, Limit: 1, Burst: 3, Window: time.Second},
+			at:     []time.Duration{0, 0, 0, 0},
+			want:   []bool{true, true, true, false},
+		},
+		{
+			name:   "token bucket refill",
+			config: LimiterConfig{Algorithm: TokenBucket, Limit: 1, Burst: 1, Window: time.Second},
+			at:     []time.Duration{0, 500 * time.Millisecond, time.Second},
+			want:   []bool{true, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := NewLimiter("api", "test", tt.config, NewMemoryStore())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, offset := range tt.at {
+				limiter.now = func() time.Time { return start.Add(offset) }
+				result, err := limiter.Allow(context.Background(), "client")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Allowed != tt.want[i] {
+					t.Errorf("request %d at +%s: got allowed %v, want %v (%+v)", i, offset, result.Allowed, tt.want[i], result)
+				}
+				if !result.Allowed && result.RetryAfter <= 0 {
+					t.Errorf("request %d: a rejected request has no RetryAfter", i)
+				}
+				if result.Remaining < 0 || result.Remaining > result.Limit {
+					t.Errorf("request %d: remaining %d out of [0, %d]", i, result.Remaining, result.Limit)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterKeys(t *testing.T) {
+	limiter, err := NewLimiter("api", "test", LimiterConfig{Limit: 1, Window: time.Minute}, NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, identity := range []string{"a", "b"} {
+		result, err := limiter.Allow(ctx, identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed {
+			t.Errorf("the first request of %s was rejected", identity)
+		}
+	}
+	if result, _ := limiter.Allow(ctx, "a"); result.Allowed {
+		t.Error("the second request of a was allowed")
+	}
+}
+
+func TestLimiterShared(t *testing.T) {
+	const limit, requests = 50, 200
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	// Two instances share the store and race on the same key
+	var instances []*Limiter
+	for range 2 {
+		limiter, err := NewLimiter("api", "test", LimiterConfig{Limit: limit, Window: time.Hour}, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		instances = append(instances, limiter)
+	}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := instances[i%2].Allow(ctx, "client")
+			if err != nil {
+				t.Error(err)
+			}
+			if result.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != limit {
+		t.Errorf("got %d requests allowed, want %d", got, limit)
+	}
+}
+
+func TestLimiterContended(t *testing.T) {
+	store := &failingStore{Store: NewMemoryStore(), err: ErrContended}
+	limiter, err := NewLimiter("api", "test", LimiterConfig{Limit: 10, Window: time.Minute}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A hammered key is rejected rather than let through by the failing-open middleware
+	result, err := limiter.Allow(context.Background(), "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.RetryAfter <= 0 || result.Limit != 10 {
+		t.Errorf("got %+v, want a rejection of the limit 10 with a RetryAfter", result)
+	}
+}
+
+func TestLimiterConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  LimiterConfig
+		wantErr bool
+	}{
+		{name: "defaults", config: LimiterConfig{Limit: 10, Window: time.Minute}},
+		{name: "unknown algorithm", config: LimiterConfig{Algorithm: "leaky", Limit: 10, Window: time.Minute}, wantErr: true},
+		{name: "unknown key", config: LimiterConfig{KeyBy: "header", Limit: 10, Window: time.Minute}, wantErr: true},
+		{name: "no limit", config: LimiterConfig{Window: time.Minute}, wantErr: true},
+		{name: "no window", config: LimiterConfig{Limit: 10}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tt.config.withDefaults("api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want one: %v", err, tt.wantErr)
+			}
+			if err == nil && (config.Algorithm != SlidingWindow || config.KeyBy != KeyByIP || config.Burst != config.Limit) {
+				t.Errorf("defaults not applied: %+v", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"fmt"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+)
+
+// Manager holds all named limiters defined in the configuration.
+type Manager struct {
+	enabled  bool
+	limiters map[string]*Limiter
+}
+
+// NewManager creates a limiter for every entry in config.Limiters using the given store.
+func NewManager(config Config, store Store) (*Manager, error) {
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = "rate_limit"
+	}
+
+	limiters := make(map[string]*Limiter, len(config.Limiters))
+	for name, limiterConfig := range config.Limiters {
+		limiter, err := NewLimiter(name, prefix, limiterConfig, store)
+		if err != nil {
+			return nil, err
+		}
+		limiters[name] = limiter
+	}
+
+	return &Manager{
+		enabled:  config.Enabled,
+		limiters: limiters,
+	}, nil
+}
+
+// Enabled reports whether rate limiting is enabled.
+func (m *Manager) Enabled() bool {
+	return m.enabled
+}
+
+// Limiter returns the named limiter.
+func (m *Manager) Limiter(name string) (*Limiter, error) {
+	limiter, ok := m.limiters[name]
+	if !ok {
+		return nil, fmt.Errorf("rate limiter '%s' is not configured", name)
+	}
+	return limiter, nil
+}
+
+// MustResolve resolves the rate limiter manager from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolve(app foundation.Application) *Manager {
+	manager, err := app.Make("rateLimiter")
+	if err != nil {
+		panic("failed to resolve rate limiter: " + err.Error())
+	}
+	return manager.(*Manager)
+}
@@ -0,0 +1,187 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// State is the persisted state of a single limiter key.
+// Token buckets use Tokens; sliding windows use Current, Previous and WindowStart.
+type State struct {
+	Tokens      float64   `json:"tokens"`
+	Current     int       `json:"current"`
+	Previous    int       `json:"previous"`
+	WindowStart time.Time `json:"window_start"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Store persists limiter state.
+type Store interface {
+	// Update applies fn to the state stored under key, nil if there is none, and stores
+	// the state fn returns for the TTL it returns. Updates of a key are atomic across every
+	// process sharing the store; fn may run more than once and must have no side effects.
+	Update(ctx context.Context, key string, fn UpdateFunc) error
+}
+
+// UpdateFunc computes the next state of a key from the current one, nil if there is none.
+type UpdateFunc func(state *State) (*State, time.Duration)
+
+// ErrContended is returned when a key is updated concurrently so often that an update
+// could not be applied.
+var ErrContended = errors.New("rate limit state is contended")
+
+// RedisStore stores limiter state in Redis, shared by every instance.
+type RedisStore struct {
+	client goredis.UniversalClient
+}
+
+// NewRedisStore creates a store on the given Redis client.
+func NewRedisStore(client goredis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// redisUpdateAttempts bounds the optimistic transactions of an update
+const redisUpdateAttempts = 10
+
+// Update applies fn in an optimistic transaction: the key is watched while fn runs, and
+// the update is retried when another instance changed it first. A missing key is no
+// state; any other failure is returned, so a transient error never resets the limit.
+func (s *RedisStore) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	update := func(tx *goredis.Tx) error {
+		var current *State
+		data, err := tx.Get(ctx, key).Bytes()
+		switch {
+		case errors.Is(err, goredis.Nil):
+		case err != nil:
+			return err
+		default:
+			current = &State{}
+			if err := json.Unmarshal(data, current); err != nil {
+				return err
+			}
+		}
+
+		state, ttl := fn(current)
+		if data, err = json.Marshal(state); err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, data, ttl)
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < redisUpdateAttempts; attempt++ {
+		if err := s.client.Watch(ctx, update, key); !errors.Is(err, goredis.TxFailedErr) {
+			return err
+		}
+	}
+	return ErrContended
+}
+
+// MemoryStore stores limiter state in process memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	writes  int
+}
+
+type memoryEntry struct {
+	state     State
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Update applies fn under the store lock, pruning expired entries periodically.
+func (s *MemoryStore) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var current *State
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		state := entry.state
+		current = &state
+	}
+
+	state, ttl := fn(current)
+	s.entries[key] = memoryEntry{state: *state, expiresAt: now.Add(ttl)}
+
+	s.writes++
+	if s.writes%1000 == 0 {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+	}
+
+	return nil
+}
+
+// FallbackStore uses a primary store and switches to a fallback store
+// for a cooldown period whenever the primary store fails.
+type FallbackStore struct {
+	primary  Store
+	fallback Store
+	cooldown time.Duration
+	logger   *slog.Logger
+
+	mu         sync.Mutex
+	retryAfter time.Time
+}
+
+// NewFallbackStore creates a store that degrades to fallback when primary errors.
+func NewFallbackStore(primary, fallback Store, logger *slog.Logger) *FallbackStore {
+	return &FallbackStore{
+		primary:  primary,
+		fallback: fallback,
+		cooldown: 30 * time.Second,
+		logger:   logger,
+	}
+}
+
+// Update updates the primary store unless it is currently degraded. Contention is not
+// a failure of the primary store, so it is returned as is.
+func (s *FallbackStore) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	if s.degraded() {
+		return s.fallback.Update(ctx, key, fn)
+	}
+
+	err := s.primary.Update(ctx, key, fn)
+	if err != nil && !errors.Is(err, ErrContended) {
+		s.degrade(err)
+		return s.fallback.Update(ctx, key, fn)
+	}
+	return err
+}
+
+func (s *FallbackStore) degraded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().Before(s.retryAfter)
+}
+
+func (s *FallbackStore) degrade(err error) {
+	s.mu.Lock()
+	s.retryAfter = time.Now().Add(s.cooldown)
+	s.mu.Unlock()
+
+	s.logger.Warn("Rate limit store unavailable, using in-memory fallback",
+		"error", err,
+		"retry_in", s.cooldown,
+	)
+}
@@ -0,0 +1,206 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// failingStore is a store whose updates fail with err while it is set, counting them.
+type failingStore struct {
+	Store
+	err   error
+	calls int
+}
+
+func (s *failingStore) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	s.calls++
+	if s.err != nil {
+		return s.err
+	}
+	return s.Store.Update(ctx, key, fn)
+}
+
+// current returns the state of key in store, without changing it
+func current(t *testing.T, store Store, key string) *State {
+	t.Helper()
+	var got *State
+	err := store.Update(context.Background(), key, func(state *State) (*State, time.Duration) {
+		got = state
+		if state == nil {
+			return &State{}, -time.Second
+		}
+		copied := *state
+		return &copied, time.Minute
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if state := current(t, store, "missing"); state != nil {
+		t.Fatalf("got %+v for a missing key, want nil", state)
+	}
+
+	want := State{Tokens: 2.5, Current: 3, Previous: 1}
+	var kept *State
+	err := store.Update(ctx, "key", func(state *State) (*State, time.Duration) {
+		kept = &State{Tokens: want.Tokens, Current: want.Current, Previous: want.Previous}
+		return kept, time.Minute
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := current(t, store, "key")
+	if got == nil || got.Tokens != want.Tokens || got.Current != want.Current || got.Previous != want.Previous {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// The stored state is a copy
+	kept.Current = 100
+	got.Current = 100
+	if again := current(t, store, "key"); again.Current != want.Current {
+		t.Errorf("changing a state changed the store: %+v", again)
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	err := store.Update(ctx, "key", func(*State) (*State, time.Duration) { return &State{Current: 1}, -time.Second })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := current(t, store, "key"); state != nil {
+		t.Errorf("got %+v for an expired key, want nil", state)
+	}
+}
+
+// unreachableRedis is a Redis client whose every call fails
+func unreachableRedis(t *testing.T) *goredis.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestRedisStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	store := NewRedisStore(unreachableRedis(t))
+
+	// A read error is not a miss, or a broken Redis would reset every limit
+	called := false
+	err := store.Update(ctx, "key", func(*State) (*State, time.Duration) {
+		called = true
+		return &State{}, time.Minute
+	})
+	if err == nil || errors.Is(err, ErrContended) {
+		t.Errorf("got %v, want the connection error", err)
+	}
+	if called {
+		t.Error("the update ran without the stored state")
+	}
+
+	// The fallback store switches to memory on the error and keeps counting there
+	fallback := NewMemoryStore()
+	degrading := NewFallbackStore(store, fallback, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	err = degrading.Update(ctx, "key", func(*State) (*State, time.Duration) { return &State{Current: 3}, time.Minute })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := current(t, fallback, "key"); state == nil || state.Current != 3 {
+		t.Errorf("got fallback state %+v, want Current 3", state)
+	}
+	if !degrading.degraded() {
+		t.Error("an error did not degrade the store")
+	}
+}
+
+func TestFallbackStore(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name         string
+		primaryErr   error
+		cooldown     time.Duration
+		wantPrimary  int
+		wantFallback bool
+		wantErr      error
+	}{
+		{name: "healthy primary", cooldown: time.Minute, wantPrimary: 3},
+		// The first failure degrades the store; the cooldown skips the primary afterwards
+		{name: "failing primary", primaryErr: errors.New("down"), cooldown: time.Minute, wantPrimary: 1, wantFallback: true},
+		// Without a cooldown the primary is retried on every call
+		{name: "failing primary retried", primaryErr: errors.New("down"), cooldown: -time.Second, wantPrimary: 3, wantFallback: true},
+		// Contention is not a failure: the primary is kept and the error returned
+		{name: "contended primary", primaryErr: ErrContended, cooldown: time.Minute, wantPrimary: 3, wantErr: ErrContended},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &failingStore{Store: NewMemoryStore(), err: tt.primaryErr}
+			fallback := NewMemoryStore()
+			store := NewFallbackStore(primary, fallback, logger)
+			store.cooldown = tt.cooldown
+
+			for i := 1; i <= 3; i++ {
+				err := store.Update(ctx, "key", func(*State) (*State, time.Duration) { return &State{Current: i}, time.Minute })
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+			}
+
+			if primary.calls != tt.wantPrimary {
+				t.Errorf("got %d primary calls, want %d", primary.calls, tt.wantPrimary)
+			}
+			if state := current(t, fallback, "key"); (state != nil) != tt.wantFallback {
+				t.Errorf("got fallback state %+v, want one: %v", state, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestFallbackStoreRecovers(t *testing.T) {
+	ctx := context.Background()
+	primary := &failingStore{Store: NewMemoryStore(), err: errors.New("down")}
+	store := NewFallbackStore(primary, NewMemoryStore(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	set := func(n int) UpdateFunc {
+		return func(*State) (*State, time.Duration) { return &State{Current: n}, time.Minute }
+	}
+	if err := store.Update(ctx, "key", set(1)); err != nil {
+		t.Fatal(err)
+	}
+	if !store.degraded() {
+		t.Fatal("a failing primary did not degrade the store")
+	}
+
+	// Once the cooldown has passed the primary is used again
+	primary.err = nil
+	store.retryAfter = time.Now().Add(-time.Second)
+	if err := store.Update(ctx, "key", set(2)); err != nil {
+		t.Fatal(err)
+	}
+	if state := current(t, primary.Store, "key"); state == nil || state.Current != 2 {
+		t.Errorf("got primary state %+v after recovery, want Current 2", state)
+	}
+}
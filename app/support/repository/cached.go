@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	appCache "skeleton/app/support/cache"
+	"skeleton/app/support/logging"
+	"skeleton/app/support/metrics"
+	"skeleton/app/support/telemetry"
+	"skeleton/app/support/tenancy"
+
+	cache "github.com/donnigundala/dg-cache"
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"gorm.io/gorm"
+)
+
+// CRUD is the generic read and write interface a repository implements to be cached
+type CRUD[T any, ID comparable] interface {
+	Create(ctx context.Context, entity T) error
+	GetByID(ctx context.Context, id ID) (T, error)
+	GetAll(ctx context.Context, page, perPage int) ([]T, int64, error)
+	Update(ctx context.Context, entity T) error
+	Delete(ctx context.Context, id ID) error
+}
+
+// Page is the cached result of GetAll
+type Page[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"`
+}
+
+// CachedRepository decorates a repository with read-through caching and write-through invalidation
+//
+// Entities are cached under <prefix>:<id> and tagged <prefix>:<id>; pages are cached under
+// <prefix>:list:<page>:<perPage> and tagged <prefix> plus the tag of every entity they contain
+// Filtered pages of List are cached under <prefix>:query:<query> and also tagged <prefix>:query
+// Keys and tags are prefixed with the tenant of the context, if any
+// Create and Delete flush every page, Update flushes the pages containing the entity and the filtered pages
+type CachedRepository[T any, ID comparable] struct {
+	CRUD[T, ID]
+
+	store  appCache.Store
+	tagger *appCache.Tagger
+	locker appCache.Locker
+	config CacheConfig
+	idOf   func(T) ID
+}
+
+// NewCachedRepository wraps repo; idOf returns the ID of an entity, and store is the cache store
+// to use, resolved once so concurrent loads through it are coalesced
+func NewCachedRepository[T any, ID comparable](repo CRUD[T, ID], store appCache.Store, tagger *appCache.Tagger, locker appCache.Locker, config CacheConfig, idOf func(T) ID) *CachedRepository[T, ID] {
+	return &CachedRepository[T, ID]{
+		CRUD:   repo,
+		store:  store,
+		tagger: tagger,
+		locker: locker,
+		config: config,
+		idOf:   idOf,
+	}
+}
+
+// Cached registers repo wrapped in a CachedRepository when caching is enabled for it
+// The repository instance must implement CRUD[T, ID]
+func Cached[T any, ID comparable](repo Registrable, config Config, idOf func(T) ID) Registrable {
+	cacheConfig := config.For(repo.Name())
+	if !cacheConfig.Enabled {
+		return repo
+	}
+
+	return NewBaseRepository(repo.Name(), func(app foundation.Application) (interface{}, error) {
+		instance, err := repo.Factory(app)
+		if err != nil {
+			return nil, err
+		}
+
+		crud, ok := instance.(CRUD[T, ID])
+		if !ok {
+			return nil, fmt.Errorf("repository %s does not implement the CRUD interface required for caching", repo.Name())
+		}
+
+		store := telemetry.TraceCache(metrics.MustResolve(app).InstrumentCache(cache.NewInjectable(app).Cache()))
+
+		return NewCachedRepository(crud, store, appCache.MustResolveTagger(app), appCache.ResolveLocker(app), cacheConfig, idOf), nil
+	})
+}
+
+// Create creates the entity and drops every cached page and any cached "not found" result
+func (r *CachedRepository[T, ID]) Create(ctx context.Context, entity T) error {
+	if err := r.CRUD.Create(ctx, entity); err != nil {
+		return err
+	}
+
+	id := r.idOf(entity)
+	r.forget(ctx, r.key(ctx, id))
+	r.flush(ctx, r.listTag(ctx))
+	return nil
+}
+
+// GetByID reads the entity through the cache
+// Concurrent misses load it once, and unknown IDs are cached for NegativeTTL
+func (r *CachedRepository[T, ID]) GetByID(ctx context.Context, id ID) (T, error) {
+	opts := []appCache.Option{
+		appCache.WithStale(r.config.Stale),
+		appCache.WithEarlyRefresh(1),
+		appCache.WithNegative(gorm.ErrRecordNotFound, r.config.NegativeTTL),
+	}
+	if r.config.LockWait > 0 {
+		opts = append(opts, appCache.WithLock(r.locker, r.config.LockWait))
+	}
+
+	store := r.tagger.Tags(r.store, r.key(ctx, id))
+	return appCache.Remember(ctx, store, r.key(ctx, id), r.config.TTL, func(ctx context.Context) (T, error) {
+		return r.CRUD.GetByID(ctx, id)
+	}, opts...)
+}
+
+// GetAll reads a page of entities through the cache
+func (r *CachedRepository[T, ID]) GetAll(ctx context.Context, page, perPage int) ([]T, int64, error) {
+	key := tenancy.CacheKey(ctx, fmt.Sprintf("%s:list:%d:%d", r.config.Prefix, page, perPage))
+	return r.page(ctx, key, []string{r.listTag(ctx)}, func(ctx context.Context) ([]T, int64, error) {
+		return r.CRUD.GetAll(ctx, page, perPage)
+	})
+}
+
+// List reads a filtered and sorted page of entities through the cache
+// The wrapped repository must implement Lister. An update can move an entity into or out of
+// any filtered page, so these pages also carry the query tag, which Update flushes
+func (r *CachedRepository[T, ID]) List(ctx context.Context, query Query) ([]T, int64, error) {
+	lister, ok := r.CRUD.(Lister[T])
+	if !ok {
+		return nil, 0, fmt.Errorf("repository %s does not implement List", r.config.Prefix)
+	}
+
+	key := tenancy.CacheKey(ctx, fmt.Sprintf("%s:query:%s", r.config.Prefix, query.Key()))
+	return r.page(ctx, key, []string{r.listTag(ctx), r.queryTag(ctx)}, func(ctx context.Context) ([]T, int64, error) {
+		return lister.List(ctx, query)
+	})
+}
+
+// page reads a page of entities through the cache, tagged with tags and the tag of every entity on it
+func (r *CachedRepository[T, ID]) page(ctx context.Context, key string, tags []string, load func(ctx context.Context) ([]T, int64, error)) ([]T, int64, error) {
+	var opts []appCache.Option
+	if r.config.LockWait > 0 {
+		opts = append(opts, appCache.WithLock(r.locker, r.config.LockWait))
+	}
+
+	store := r.tagger.Tags(r.store, tags...)
+	result, err := appCache.Remember(ctx, store, key, r.config.ListTTL, func(ctx context.Context) (Page[T], error) {
+		items, total, err := load(ctx)
+		if err != nil {
+			return Page[T]{}, err
+		}
+
+		// The entities on the page are only known now; tag it before Remember stores it
+		tags := make([]string, 0, len(items))
+		for _, item := range items {
+			tags = append(tags, r.key(ctx, r.idOf(item)))
+		}
+		if err := r.tagger.Tag(ctx, key, r.config.ListTTL, tags...); err != nil {
+			return Page[T]{}, err
+		}
+		return Page[T]{Items: items, Total: total}, nil
+	}, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result.Items, result.Total, nil
+}
+
+// Update updates the entity and drops it, every cached page containing it and every filtered page
+func (r *CachedRepository[T, ID]) Update(ctx context.Context, entity T) error {
+	if err := r.CRUD.Update(ctx, entity); err != nil {
+		return err
+	}
+
+	id := r.idOf(entity)
+	r.forget(ctx, r.key(ctx, id))
+	r.flush(ctx, r.key(ctx, id), r.queryTag(ctx))
+	return nil
+}
+
+// Delete deletes the entity and drops it and every cached page, since later pages shift
+func (r *CachedRepository[T, ID]) Delete(ctx context.Context, id ID) error {
+	if err := r.CRUD.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.forget(ctx, r.key(ctx, id))
+	r.flush(ctx, r.listTag(ctx))
+	return nil
+}
+
+// key is both the cache key of an entity and the tag of every entry containing it
+func (r *CachedRepository[T, ID]) key(ctx context.Context, id ID) string {
+	return tenancy.CacheKey(ctx, fmt.Sprintf("%s:%v", r.config.Prefix, id))
+}
+
+// listTag tags every cached page
+func (r *CachedRepository[T, ID]) listTag(ctx context.Context) string {
+	return tenancy.CacheKey(ctx, r.config.Prefix)
+}
+
+// queryTag tags every cached page of List
+func (r *CachedRepository[T, ID]) queryTag(ctx context.Context) string {
+	return tenancy.CacheKey(ctx, r.config.Prefix+":query")
+}
+
+// forget invalidates a cache entry. Failures are logged, not returned:
+// the entry expires with its TTL anyway
+func (r *CachedRepository[T, ID]) forget(ctx context.Context, key string) {
+	if err := r.store.Forget(ctx, key); err != nil {
+		logging.FromContext(ctx).Warn("Failed to invalidate cached entity", "key", key, "error", err)
+	}
+}
+
+// flush invalidates every entry carrying one of the tags. Failures are logged, not returned
+func (r *CachedRepository[T, ID]) flush(ctx context.Context, tags ...string) {
+	if err := r.tagger.FlushTags(ctx, r.store, tags...); err != nil {
+		logging.FromContext(ctx).Warn("Failed to invalidate cached entities", "tags", tags, "error", err)
+	}
+}
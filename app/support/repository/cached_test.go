@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	appCache "skeleton/app/support/cache"
+
+	"gorm.io/gorm"
+)
+
+// item is the entity of the fake repository
+type item struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// fakeRepository keeps items in memory and counts the reads reaching it
+type fakeRepository struct {
+	items map[uint]item
+	reads int
+}
+
+func (r *fakeRepository) Create(ctx context.Context, entity *item) error {
+	r.items[entity.ID] = *entity
+	return nil
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id uint) (*item, error) {
+	r.reads++
+	entity, ok := r.items[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entity, nil
+}
+
+func (r *fakeRepository) GetAll(ctx context.Context, page, perPage int) ([]*item, int64, error) {
+	r.reads++
+	ids := make([]int, 0, len(r.items))
+	for id := range r.items {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+
+	var items []*item
+	for i := (page - 1) * perPage; i < len(ids) && i < page*perPage; i++ {
+		entity := r.items[uint(ids[i])]
+		items = append(items, &entity)
+	}
+	return items, int64(len(ids)), nil
+}
+
+func (r *fakeRepository) List(ctx context.Context, query Query) ([]*item, int64, error) {
+	items, _, err := r.GetAll(ctx, 1, len(r.items)+1)
+	var matching []*item
+	for _, entity := range items {
+		if name, ok := query.Filters["name"]; !ok || entity.Name == name {
+			matching = append(matching, entity)
+		}
+	}
+	return matching, int64(len(matching)), err
+}
+
+func (r *fakeRepository) Update(ctx context.Context, entity *item) error {
+	r.items[entity.ID] = *entity
+	return nil
+}
+
+func (r *fakeRepository) Delete(ctx context.Context, id uint) error {
+	delete(r.items, id)
+	return nil
+}
+
+// jsonStore is a cache store keeping JSON values, like the dg-cache drivers
+type jsonStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (s *jsonStore) GetAs(ctx context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.values[key]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *jsonStore) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = data
+	return nil
+}
+
+func (s *jsonStore) Forget(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+func TestCachedRepository(t *testing.T) {
+	type cached = CachedRepository[*item, uint]
+	// reader is the cached repository or, for the expected results, the repository itself
+	type reader interface {
+		CRUD[*item, uint]
+		Lister[*item]
+	}
+
+	// Reads render their result, so a stale cache shows as a different rendering
+	getByID := func(id uint) func(context.Context, reader) string {
+		return func(ctx context.Context, r reader) string {
+			entity, err := r.GetByID(ctx, id)
+			if err != nil {
+				return err.Error()
+			}
+			return fmt.Sprint(*entity)
+		}
+	}
+	getAll := func(ctx context.Context, r reader) string {
+		items, total, err := r.GetAll(ctx, 1, 2)
+		return renderPage(items, total, err)
+	}
+	list := func(ctx context.Context, r reader) string {
+		items, total, err := r.List(ctx, Query{Page: 1, PerPage: 10, Filters: map[string]interface{}{"name": "b"}})
+		return renderPage(items, total, err)
+	}
+
+	tests := []struct {
+		name  string
+		read  func(context.Context, reader) string
+		write func(context.Context, *cached) error
+		// wantReload reports whether the write invalidates the cached read
+		wantReload bool
+	}{
+		{
+			name:       "update drops the entity",
+			read:       getByID(1),
+			write:      func(ctx context.Context, r *cached) error { return r.Update(ctx, &item{ID: 1, Name: "z"}) },
+			wantReload: true,
+		},
+		{
+			name:  "update of another entity keeps it",
+			read:  getByID(1),
+			write: func(ctx context.Context, r *cached) error { return r.Update(ctx, &item{ID: 2, Name: "z"}) },
+		},
+		{
+			name:       "delete drops the entity",
+			read:       getByID(1),
+			write:      func(ctx context.Context, r *cached) error { return r.Delete(ctx, 1) },
+			wantReload: true,
+		},
+		{
+			name:       "create drops a cached not found",
+			read:       getByID(4),
+			write:      func(ctx context.Context, r *cached) error { return r.Create(ctx, &item{ID: 4, Name: "d"}) },
+			wantReload: true,
+		},
+		{
+			name:       "create flushes the pages",
+			read:       getAll,
+			write:      func(ctx context.Context, r *cached) error { return r.Create(ctx, &item{ID: 0, Name: "first"}) },
+			wantReload: true,
+		},
+		{
+			name:       "delete flushes the pages",
+			read:       getAll,
+			write:      func(ctx context.Context, r *cached) error { return r.Delete(ctx, 3) },
+			wantReload: true,
+		},
+		{
+			name:       "update flushes the pages containing the entity",
+			read:       getAll,
+			write:      func(ctx context.Context, r *cached) error { return r.Update(ctx, &item{ID: 2, Name: "z"}) },
+			wantReload: true,
+		},
+		{
+			name:  "update keeps the pages without the entity",
+			read:  getAll,
+			write: func(ctx context.Context, r *cached) error { return r.Update(ctx, &item{ID: 3, Name: "z"}) },
+		},
+		{
+			name:       "update flushes the filtered pages",
+			read:       list,
+			write:      func(ctx context.Context, r *cached) error { return r.Update(ctx, &item{ID: 3, Name: "b"}) },
+			wantReload: true,
+		},
+		{
+			name:       "create flushes the filtered pages",
+			read:       list,
+			write:      func(ctx context.Context, r *cached) error { return r.Create(ctx, &item{ID: 4, Name: "b"}) },
+			wantReload: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &fakeRepository{items: map[uint]item{1: {1, "a"}, 2: {2, "b"}, 3: {3, "c"}}}
+			config := Config{}.For("itemRepository")
+			config.NegativeTTL = time.Minute
+			r := NewCachedRepository[*item, uint](repo, &jsonStore{values: map[string][]byte{}},
+				appCache.NewTagger(appCache.NewMemoryTagIndex()), nil, config, func(entity *item) uint { return entity.ID })
+
+			// Read through: the second read is served from the cache
+			first := tt.read(ctx, r)
+			if got := tt.read(ctx, r); got != first || repo.reads != 1 {
+				t.Fatalf("got %q after %d reads, want %q from the cache", got, repo.reads, first)
+			}
+
+			if err := tt.write(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+			got := tt.read(ctx, r)
+			if reloaded := repo.reads > 1; reloaded != tt.wantReload {
+				t.Fatalf("got a reload %v, want %v", reloaded, tt.wantReload)
+			}
+			if want := tt.read(ctx, repo); tt.wantReload && got != want {
+				t.Errorf("got %q after the write, want %q", got, want)
+			}
+		})
+	}
+}
+
+// renderPage renders a page of items for comparison
+func renderPage(items []*item, total int64, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	var entities []item
+	for _, entity := range items {
+		entities = append(entities, *entity)
+	}
+	return fmt.Sprint(entities, total)
+}
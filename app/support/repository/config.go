@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"strings"
+	"time"
+)
+
+// Config represents the repository configuration (config/repository.yaml)
+type Config struct {
+	// Cache configures the caching decorator per repository, keyed by binding name
+	Cache map[string]CacheConfig `mapstructure:"cache"`
+}
+
+// CacheConfig configures caching for a single repository
+type CacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Prefix namespaces the cache keys and tags, e.g. "user" gives user:42 and user:list:1:20
+	// Defaults to the binding name without the "Repository" suffix
+	Prefix string `mapstructure:"prefix"`
+	// TTL is how long a single entity stays fresh
+	TTL time.Duration `mapstructure:"ttl"`
+	// ListTTL is how long a page of entities stays fresh
+	ListTTL time.Duration `mapstructure:"list_ttl"`
+	// Stale is how long an expired entity is still served while it is refreshed
+	Stale time.Duration `mapstructure:"stale"`
+	// NegativeTTL is how long a missing entity is remembered; 0 disables
+	NegativeTTL time.Duration `mapstructure:"negative_ttl"`
+	// LockWait is how long to wait for another instance loading the same key; 0 disables the lock
+	LockWait time.Duration `mapstructure:"lock_wait"`
+}
+
+// For returns the cache configuration of the named repository
+func (c Config) For(name string) CacheConfig {
+	return c.Cache[name].withDefaults(name)
+}
+
+// withDefaults fills in missing values
+func (c CacheConfig) withDefaults(name string) CacheConfig {
+	if c.Prefix == "" {
+		c.Prefix = strings.TrimSuffix(name, "Repository")
+	}
+	if c.TTL <= 0 {
+		c.TTL = 5 * time.Minute
+	}
+	if c.ListTTL <= 0 {
+		c.ListTTL = time.Minute
+	}
+	return c
+}
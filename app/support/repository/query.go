@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Query selects a page of entities filtered by column values and ordered by a column
+// Column names come from the caller's allow list, never straight from a request
+type Query struct {
+	Page    int
+	PerPage int
+	// Filters match columns to values, e.g. {"status": "active"}
+	Filters map[string]interface{}
+	// Sort is the column to order by; empty keeps the primary key order
+	Sort string
+	// Desc orders by Sort descending
+	Desc bool
+}
+
+// Lister is implemented by repositories listing entities with a Query
+type Lister[T any] interface {
+	List(ctx context.Context, query Query) ([]T, int64, error)
+}
+
+// Scope applies the filters and the order of the query to a GORM statement
+func (q Query) Scope(db *gorm.DB) *gorm.DB {
+	for _, column := range q.columns() {
+		db = db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: q.Filters[column]})
+	}
+	if q.Sort != "" {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: q.Sort}, Desc: q.Desc})
+	}
+	return db
+}
+
+// Key identifies the query in cache keys; equal queries give equal keys
+func (q Query) Key() string {
+	values := url.Values{}
+	for _, column := range q.columns() {
+		values.Set(column, fmt.Sprint(q.Filters[column]))
+	}
+	order := q.Sort
+	if q.Desc {
+		order = "-" + order
+	}
+	return fmt.Sprintf("%d:%d:%s:%s", q.Page, q.PerPage, order, values.Encode())
+}
+
+// columns returns the filtered columns in a stable order
+func (q Query) columns() []string {
+	columns := make([]string, 0, len(q.Filters))
+	for column := range q.Filters {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
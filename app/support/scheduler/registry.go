@@ -5,10 +5,14 @@ import (
 	"log/slog"
 )
 
+// Middleware wraps a job handler, e.g. to record metrics or add logging
+type Middleware func(job ScheduledJob, next func() error) func() error
+
 // Registry holds all registered scheduled jobs
 type Registry struct {
-	jobs   []ScheduledJob
-	logger *slog.Logger
+	jobs       []ScheduledJob
+	middleware []Middleware
+	logger     *slog.Logger
 }
 
 // NewRegistry creates a new job registry
@@ -25,6 +29,12 @@ func (r *Registry) Register(job ScheduledJob) {
 	r.logger.Debug("Job registered", "name", job.Name(), "schedule", job.Schedule(), "enabled", job.IsEnabled())
 }
 
+// Use adds middleware that wraps every job handler when scheduled
+// Middleware runs in the order it was added
+func (r *Registry) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
 // GetEnabledJobs returns all enabled jobs
 func (r *Registry) GetEnabledJobs() []ScheduledJob {
 	enabled := make([]ScheduledJob, 0)
@@ -43,7 +53,7 @@ func (r *Registry) ScheduleAll(scheduler interface {
 	enabledJobs := r.GetEnabledJobs()
 
 	for _, job := range enabledJobs {
-		if err := scheduler.Schedule(job.Schedule(), job.Name(), job.Handle); err != nil {
+		if err := scheduler.Schedule(job.Schedule(), job.Name(), r.wrap(job)); err != nil {
 			return fmt.Errorf("failed to schedule job '%s': %w", job.Name(), err)
 		}
 		r.logger.Info("Job scheduled", "name", job.Name(), "schedule", job.Schedule())
@@ -52,3 +62,12 @@ func (r *Registry) ScheduleAll(scheduler interface {
 	r.logger.Info("All jobs scheduled", "total", len(r.jobs), "enabled", len(enabledJobs))
 	return nil
 }
+
+// wrap applies all registered middleware to the job handler
+func (r *Registry) wrap(job ScheduledJob) func() error {
+	handler := job.Handle
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](job, handler)
+	}
+	return handler
+}
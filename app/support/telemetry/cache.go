@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheStore is the subset of a dg-cache store that can be traced.
+type CacheStore interface {
+	GetAs(ctx context.Context, key string, dest interface{}) error
+	Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Forget(ctx context.Context, key string) error
+}
+
+// TraceCache wraps a cache store so every call creates a span.
+func TraceCache(store CacheStore) CacheStore {
+	return &tracedCache{store: store}
+}
+
+type tracedCache struct {
+	store CacheStore
+}
+
+// GetAs traces a cache lookup and records whether it was a hit.
+func (c *tracedCache) GetAs(ctx context.Context, key string, dest interface{}) error {
+	ctx, span := startCacheSpan(ctx, "get", key)
+	defer span.End()
+
+	err := c.store.GetAs(ctx, key, dest)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+	return err
+}
+
+// Put traces a cache write.
+func (c *tracedCache) Put(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	ctx, span := startCacheSpan(ctx, "put", key)
+	defer span.End()
+
+	return endCacheSpan(span, c.store.Put(ctx, key, value, ttl))
+}
+
+// Forget traces a cache invalidation.
+func (c *tracedCache) Forget(ctx context.Context, key string) error {
+	ctx, span := startCacheSpan(ctx, "forget", key)
+	defer span.End()
+
+	return endCacheSpan(span, c.store.Forget(ctx, key))
+}
+
+func startCacheSpan(ctx context.Context, operation, key string) (context.Context, trace.Span) {
+	group, _, _ := strings.Cut(key, ":")
+	return otel.Tracer(tracerName).Start(ctx, "cache."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("cache.operation", operation),
+			attribute.String("cache.key_group", group),
+		),
+	)
+}
+
+func endCacheSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+package telemetry
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName   = "skeleton/app/support/telemetry"
+	gormSpanKey  = "telemetry:span"
+	gormCallback = "telemetry"
+)
+
+// GormPlugin returns a GORM plugin that creates a span for every query.
+func GormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+type gormPlugin struct{}
+
+// Name returns the plugin name.
+func (p *gormPlugin) Name() string {
+	return "telemetry"
+}
+
+// Initialize registers before/after callbacks for every GORM operation.
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	hooks := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+
+	for _, hook := range hooks {
+		if err := hook.before(gormCallback+":before_"+hook.operation, p.before(hook.operation)); err != nil {
+			return err
+		}
+		if err := hook.after(gormCallback+":after_"+hook.operation, p.after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *gormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		_, span := otel.Tracer(tracerName).Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", db.Dialector.Name()),
+				attribute.String("db.operation.name", operation),
+			),
+		)
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (p *gormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	span, isSpan := value.(trace.Span)
+	if !ok || !isSpan {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.collection.name", db.Statement.Table),
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.Int64("db.response.returned_rows", db.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
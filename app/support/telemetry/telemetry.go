@@ -0,0 +1,177 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// Config represents the telemetry configuration (config/telemetry.yaml).
+type Config struct {
+	Enabled     bool       `mapstructure:"enabled"`
+	ServiceName string     `mapstructure:"service_name"`
+	Exporter    string     `mapstructure:"exporter"`
+	OTLP        OTLPConfig `mapstructure:"otlp"`
+	SampleRatio float64    `mapstructure:"sample_ratio"`
+}
+
+// OTLPConfig configures the OTLP exporter.
+type OTLPConfig struct {
+	Protocol string            `mapstructure:"protocol"`
+	Endpoint string            `mapstructure:"endpoint"`
+	Insecure bool              `mapstructure:"insecure"`
+	Headers  map[string]string `mapstructure:"headers"`
+}
+
+// Telemetry owns the tracer provider and its exporter.
+type Telemetry struct {
+	config   Config
+	provider *sdktrace.TracerProvider
+	memory   *tracetest.InMemoryExporter
+}
+
+// New configures the global tracer provider and propagator.
+// When tracing is disabled the global no-op provider is left in place,
+// so instrumented code costs next to nothing.
+func New(ctx context.Context, config Config) (*Telemetry, error) {
+	t := &Telemetry{config: config}
+
+	// Always propagate incoming trace context, even when not exporting spans.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !config.Enabled {
+		return t, nil
+	}
+
+	exporter, err := t.createExporter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = "skeleton"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	ratio := config.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(t.provider)
+
+	return t, nil
+}
+
+// createExporter creates the span exporter based on configuration.
+func (t *Telemetry) createExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch t.config.Exporter {
+	case "", "otlp":
+		return t.createOTLPExporter(ctx)
+
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+
+	case "memory":
+		t.memory = tracetest.NewInMemoryExporter()
+		return t.memory, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported telemetry exporter: %s", t.config.Exporter)
+	}
+}
+
+func (t *Telemetry) createOTLPExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	cfg := t.config.OTLP
+
+	switch cfg.Protocol {
+	case "", "grpc":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	case "http":
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s", cfg.Protocol)
+	}
+}
+
+// Enabled reports whether spans are exported.
+func (t *Telemetry) Enabled() bool {
+	return t.provider != nil
+}
+
+// Spans returns the spans recorded by the memory exporter (exporter: memory).
+// Call ForceFlush first to include spans still held by the batcher.
+func (t *Telemetry) Spans() tracetest.SpanStubs {
+	if t.memory == nil {
+		return nil
+	}
+	return t.memory.GetSpans()
+}
+
+// ForceFlush exports all pending spans.
+func (t *Telemetry) ForceFlush(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+	return t.provider.ForceFlush(ctx)
+}
+
+// Shutdown flushes pending spans and stops the exporter.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	if t.provider == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// MustResolve resolves the telemetry instance from the container.
+// It panics if the resolution fails, which is acceptable during app boot.
+func MustResolve(app foundation.Application) *Telemetry {
+	t, err := app.Make("telemetry")
+	if err != nil {
+		panic("failed to resolve telemetry: " + err.Error())
+	}
+	return t.(*Telemetry)
+}
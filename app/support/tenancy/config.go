@@ -0,0 +1,52 @@
+package tenancy
+
+import "time"
+
+// Config represents the tenancy configuration (config/tenancy.yaml)
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Required rejects requests that do not identify a tenant
+	Required bool `mapstructure:"required"`
+	// Resolvers lists the strategies tried in order: subdomain, header, jwt
+	// The header is set by the client, so only enable it behind a gateway that sets it for trusted callers
+	Resolvers []string `mapstructure:"resolvers"`
+	// BaseDomain is the domain tenant subdomains live under, e.g. "example.com" for acme.example.com
+	// Other hosts are looked up as custom tenant domains
+	BaseDomain string `mapstructure:"base_domain"`
+	// Header carries the tenant slug for the header resolver
+	Header string    `mapstructure:"header"`
+	JWT    JWTConfig `mapstructure:"jwt"`
+	// SharedTables live in the shared schema and are never scoped to a tenant
+	SharedTables []string `mapstructure:"shared_tables"`
+	// CacheTTL is how long resolved tenants are kept in process
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// MigrationsPath holds the migrations applied to every tenant schema (cmd/migrate -tenants)
+	// Empty uses the tenant/ migrations embedded in the binary, which leave out the shared tables
+	MigrationsPath string `mapstructure:"migrations_path"`
+}
+
+// JWTConfig configures the jwt resolver, which reads the tenant from a claim of an HS256 bearer token
+type JWTConfig struct {
+	Secret string `mapstructure:"secret"`
+	Claim  string `mapstructure:"claim"`
+}
+
+// withDefaults fills in missing values
+func (c Config) withDefaults() Config {
+	if len(c.Resolvers) == 0 {
+		c.Resolvers = []string{"subdomain"}
+	}
+	if c.Header == "" {
+		c.Header = "X-Tenant"
+	}
+	if c.JWT.Claim == "" {
+		c.JWT.Claim = "tenant"
+	}
+	if c.SharedTables == nil {
+		c.SharedTables = []string{"tenants", "api_keys"}
+	}
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = time.Minute
+	}
+	return c
+}
@@ -0,0 +1,52 @@
+package tenancy
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"skeleton/app/models"
+)
+
+// MetaTenant is the job metadata key carrying the tenant slug
+const MetaTenant = "tenant"
+
+type tenantKey struct{}
+
+// schemaPattern matches the schema names accepted for tenants (unquoted PostgreSQL identifiers)
+var schemaPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// WithTenant returns a copy of ctx scoped to the tenant
+func WithTenant(ctx context.Context, tenant *models.Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// FromContext returns the tenant ctx is scoped to, if any
+func FromContext(ctx context.Context) (*models.Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(*models.Tenant)
+	return tenant, ok && tenant != nil
+}
+
+// CacheKey prefixes key with the tenant of ctx, so tenants never share cache entries
+// Keys are returned unchanged outside a tenant
+func CacheKey(ctx context.Context, key string) string {
+	if tenant, ok := FromContext(ctx); ok {
+		return "tenant:" + tenant.Slug + ":" + key
+	}
+	return key
+}
+
+// UnscopedKey returns a key built by CacheKey without its tenant scope, for matching it by prefix
+func UnscopedKey(key string) string {
+	if rest, ok := strings.CutPrefix(key, "tenant:"); ok {
+		if _, unscoped, ok := strings.Cut(rest, ":"); ok {
+			return unscoped
+		}
+	}
+	return key
+}
+
+// ValidSchema reports whether name can be used as a tenant schema
+func ValidSchema(name string) bool {
+	return schemaPattern.MatchString(name)
+}
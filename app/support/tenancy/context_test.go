@@ -0,0 +1,56 @@
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	"skeleton/app/models"
+)
+
+func TestCacheKey(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "outside a tenant", ctx: context.Background(), want: "user:1"},
+		{name: "in a tenant", ctx: WithTenant(context.Background(), &models.Tenant{Slug: "acme"}), want: "tenant:acme:user:1"},
+		{name: "nil tenant", ctx: WithTenant(context.Background(), nil), want: "user:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CacheKey(tt.ctx, "user:1"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if unscoped := UnscopedKey(CacheKey(tt.ctx, "user:1")); unscoped != "user:1" {
+				t.Errorf("got unscoped key %q, want user:1", unscoped)
+			}
+		})
+	}
+}
+
+func TestValidSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "tenant_acme", want: true},
+		{name: "_private", want: true},
+		{name: "", want: false},
+		{name: "Acme", want: false},
+		{name: "1acme", want: false},
+		{name: `acme"; DROP SCHEMA public; --`, want: false},
+		{name: "acme.users", want: false},
+		{name: "a23456789012345678901234567890123456789012345678901234567890123", want: true},
+		{name: "a234567890123456789012345678901234567890123456789012345678901234", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidSchema(tt.name); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package tenancy
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SchemaPlugin qualifies the tables of every statement run in a tenant context with the tenant schema
+//
+// Repositories need no changes: any query built with db.WithContext(ctx) on a tenant context
+// reads and writes the tenant's tables. Shared tables and already qualified tables are left alone
+// Raw SQL is not rewritten
+type SchemaPlugin struct {
+	shared map[string]bool
+}
+
+// NewSchemaPlugin creates the plugin; sharedTables stay in the shared schema
+func NewSchemaPlugin(sharedTables []string) *SchemaPlugin {
+	shared := make(map[string]bool, len(sharedTables))
+	for _, table := range sharedTables {
+		shared[table] = true
+	}
+	return &SchemaPlugin{shared: shared}
+}
+
+// Name returns the plugin name
+func (p *SchemaPlugin) Name() string {
+	return "tenancy:schema"
+}
+
+// Initialize registers the callbacks
+func (p *SchemaPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register(p.Name(), p.scope),
+		callbacks.Query().Before("gorm:query").Register(p.Name(), p.scope),
+		callbacks.Update().Before("gorm:update").Register(p.Name(), p.scope),
+		callbacks.Delete().Before("gorm:delete").Register(p.Name(), p.scope),
+		callbacks.Row().Before("gorm:row").Register(p.Name(), p.scope),
+	)
+}
+
+func (p *SchemaPlugin) scope(db *gorm.DB) {
+	tenant, ok := FromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+
+	table := db.Statement.Table
+	if table == "" || strings.Contains(table, ".") || p.shared[table] {
+		return
+	}
+	db.Statement.Table = tenant.Schema + "." + table
+}
@@ -0,0 +1,93 @@
+package tenancy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"skeleton/app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// dryRunDialector builds SQL with ? placeholders and never runs it
+type dryRunDialector struct{}
+
+func (dryRunDialector) Name() string { return "dryrun" }
+
+func (dryRunDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+
+func (dryRunDialector) Migrator(db *gorm.DB) gorm.Migrator { return nil }
+
+func (dryRunDialector) DataTypeOf(*schema.Field) string { return "" }
+
+func (dryRunDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (dryRunDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	_ = writer.WriteByte('?')
+}
+
+func (dryRunDialector) QuoteTo(writer clause.Writer, str string) {
+	_, _ = writer.WriteString(`"` + str + `"`)
+}
+
+func (dryRunDialector) Explain(sql string, vars ...interface{}) string {
+	return gormlogger.ExplainSQL(sql, nil, `'`, vars...)
+}
+
+func TestSchemaPlugin(t *testing.T) {
+	type user struct {
+		ID   uint
+		Name string
+	}
+
+	db, err := gorm.Open(dryRunDialector{}, &gorm.Config{DryRun: true, Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewSchemaPlugin([]string{"tenants", "api_keys"})); err != nil {
+		t.Fatal(err)
+	}
+	tenantCtx := WithTenant(context.Background(), &models.Tenant{Slug: "acme", Schema: "tenant_acme"})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		run       func(db *gorm.DB) *gorm.DB
+		wantTable string
+	}{
+		{name: "query", ctx: tenantCtx, run: func(db *gorm.DB) *gorm.DB { return db.Find(&[]user{}) }, wantTable: `"tenant_acme.users"`},
+		{name: "create", ctx: tenantCtx, run: func(db *gorm.DB) *gorm.DB { return db.Create(&user{Name: "ada"}) }, wantTable: `"tenant_acme.users"`},
+		{name: "update", ctx: tenantCtx, run: func(db *gorm.DB) *gorm.DB {
+			return db.Model(&user{ID: 1}).Update("name", "ada")
+		}, wantTable: `"tenant_acme.users"`},
+		{name: "delete", ctx: tenantCtx, run: func(db *gorm.DB) *gorm.DB { return db.Delete(&user{ID: 1}) }, wantTable: `"tenant_acme.users"`},
+		{name: "shared table", ctx: tenantCtx, run: func(db *gorm.DB) *gorm.DB { return db.Find(&[]models.Tenant{}) }, wantTable: `"tenants"`},
+		{name: "qualified table", ctx: tenantCtx, run: func(db *gorm.DB) *gorm.DB {
+			return db.Table("public.users").Find(&[]user{})
+		}, wantTable: `"public.users"`},
+		{name: "outside a tenant", ctx: context.Background(), run: func(db *gorm.DB) *gorm.DB { return db.Find(&[]user{}) }, wantTable: `"users"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.run(db.WithContext(tt.ctx))
+			if result.Error != nil {
+				t.Fatal(result.Error)
+			}
+			sql := result.Statement.SQL.String()
+			if !strings.Contains(sql, tt.wantTable) {
+				t.Errorf("got %s, want the table %s", sql, tt.wantTable)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package tenancy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned when a bearer token carrying the tenant fails verification
+var ErrInvalidToken = errors.New("invalid tenant token")
+
+// claimFromJWT verifies an HS256 token and returns the given claim
+// Tokens signed with another algorithm, expired or not yet valid are rejected
+func claimFromJWT(token, secret, claim string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return "", ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", ErrInvalidToken
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", ErrInvalidToken
+	}
+
+	now := float64(time.Now().Unix())
+	if exp, ok := claims["exp"].(float64); ok && now >= exp {
+		return "", ErrInvalidToken
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now < nbf {
+		return "", ErrInvalidToken
+	}
+
+	switch value := claims[claim].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case float64:
+		return fmt.Sprint(value), nil
+	default:
+		return "", ErrInvalidToken
+	}
+}
+
+func decodeSegment(segment string, dest interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
+}
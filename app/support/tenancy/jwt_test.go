@@ -0,0 +1,69 @@
+package tenancy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// signJWT returns a token with the given header algorithm and claims, signed with HS256 and secret
+func signJWT(t *testing.T, alg, secret string, claims map[string]interface{}) string {
+	t.Helper()
+	segment := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := segment(map[string]string{"alg": alg, "typ": "JWT"}) + "." + segment(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestClaimFromJWT(t *testing.T) {
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "string claim", token: signJWT(t, "HS256", "secret", map[string]interface{}{"tenant": "acme"}), want: "acme"},
+		{name: "numeric claim", token: signJWT(t, "HS256", "secret", map[string]interface{}{"tenant": 42}), want: "42"},
+		{name: "no claim", token: signJWT(t, "HS256", "secret", map[string]interface{}{"sub": "1"}), want: ""},
+		{name: "valid times", token: signJWT(t, "HS256", "secret", map[string]interface{}{"tenant": "acme", "exp": now + 60, "nbf": now - 60}), want: "acme"},
+		{name: "object claim", token: signJWT(t, "HS256", "secret", map[string]interface{}{"tenant": map[string]string{"slug": "acme"}}), wantErr: true},
+		{name: "wrong secret", token: signJWT(t, "HS256", "other", map[string]interface{}{"tenant": "acme"}), wantErr: true},
+		// The algorithm is fixed: a token can't pick a weaker one
+		{name: "other algorithm", token: signJWT(t, "none", "secret", map[string]interface{}{"tenant": "acme"}), wantErr: true},
+		{name: "expired", token: signJWT(t, "HS256", "secret", map[string]interface{}{"tenant": "acme", "exp": now - 1}), wantErr: true},
+		{name: "not yet valid", token: signJWT(t, "HS256", "secret", map[string]interface{}{"tenant": "acme", "nbf": now + 60}), wantErr: true},
+		{name: "malformed", token: "a.b", wantErr: true},
+		{name: "bad signature encoding", token: signJWT(t, "HS256", "secret", map[string]interface{}{"tenant": "acme"}) + "!", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := claimFromJWT(tt.token, "secret", "tenant")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Fatalf("got %q, %v, want ErrInvalidToken", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"skeleton/app/models"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNoTenant is returned when a request does not identify a tenant
+	ErrNoTenant = errors.New("no tenant in request")
+	// ErrTenantNotFound is returned when the identified tenant does not exist
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrTenantInactive is returned when the identified tenant is deactivated
+	ErrTenantInactive = errors.New("tenant is inactive")
+	// ErrTenantMismatch is returned when the tenant header names another tenant than the bearer token
+	ErrTenantMismatch = errors.New("tenant header does not match the token")
+)
+
+// Lookup reads the tenant registry table
+type Lookup interface {
+	FindBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	FindByDomain(ctx context.Context, domain string) (*models.Tenant, error)
+	GetActive(ctx context.Context) ([]*models.Tenant, error)
+}
+
+// Registry resolves tenants, caching lookups in process
+type Registry struct {
+	config Config
+	lookup Lookup
+
+	mu      sync.RWMutex
+	entries map[string]cachedTenant
+}
+
+type cachedTenant struct {
+	tenant  *models.Tenant
+	expires time.Time
+}
+
+// NewRegistry creates a tenant registry on top of the tenant repository
+func NewRegistry(config Config, lookup Lookup) *Registry {
+	return &Registry{
+		config:  config.withDefaults(),
+		lookup:  lookup,
+		entries: make(map[string]cachedTenant),
+	}
+}
+
+// Config returns the effective configuration
+func (r *Registry) Config() Config {
+	return r.config
+}
+
+// FindBySlug returns the tenant with the given slug
+func (r *Registry) FindBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	return r.find(ctx, "slug:"+slug, func() (*models.Tenant, error) {
+		return r.lookup.FindBySlug(ctx, slug)
+	})
+}
+
+// FindByDomain returns the tenant owning the given custom domain
+func (r *Registry) FindByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
+	return r.find(ctx, "domain:"+domain, func() (*models.Tenant, error) {
+		return r.lookup.FindByDomain(ctx, domain)
+	})
+}
+
+// All returns every active tenant, bypassing the cache
+func (r *Registry) All(ctx context.Context) ([]*models.Tenant, error) {
+	return r.lookup.GetActive(ctx)
+}
+
+// Forget drops a tenant from the cache after it changed
+func (r *Registry) Forget(tenant *models.Tenant) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, "slug:"+tenant.Slug)
+	if tenant.Domain != nil {
+		delete(r.entries, "domain:"+*tenant.Domain)
+	}
+}
+
+// Resolve identifies the tenant of a request with the configured resolvers, in order
+// It returns ErrNoTenant when no resolver identifies one
+func (r *Registry) Resolve(req *http.Request) (*models.Tenant, error) {
+	return active(r.resolve(req))
+}
+
+func (r *Registry) resolve(req *http.Request) (*models.Tenant, error) {
+	ctx := req.Context()
+	for _, resolver := range r.config.Resolvers {
+		switch resolver {
+		case "subdomain":
+			host := hostname(req.Host)
+			if slug, ok := r.subdomain(host); ok {
+				return r.FindBySlug(ctx, slug)
+			}
+			// Other hosts may be custom tenant domains; unknown ones fall through to the next resolver
+			if host != "" && r.config.BaseDomain != "" && !r.isBaseDomain(host) && net.ParseIP(host) == nil {
+				tenant, err := r.FindByDomain(ctx, host)
+				if !errors.Is(err, ErrTenantNotFound) {
+					return tenant, err
+				}
+			}
+
+		case "header":
+			// The header is only trusted as far as the signed token: it can't name another tenant
+			if slug := strings.TrimSpace(req.Header.Get(r.config.Header)); slug != "" {
+				claimed, err := r.tokenTenant(req)
+				if err != nil {
+					return nil, err
+				}
+				if claimed != "" && claimed != slug {
+					return nil, ErrTenantMismatch
+				}
+				return r.FindBySlug(ctx, slug)
+			}
+
+		case "jwt":
+			slug, err := r.tokenTenant(req)
+			if err != nil {
+				return nil, err
+			}
+			if slug != "" {
+				return r.FindBySlug(ctx, slug)
+			}
+		}
+	}
+	return nil, ErrNoTenant
+}
+
+// tokenTenant returns the tenant claim of a bearer token, or "" without a token or a jwt secret
+func (r *Registry) tokenTenant(req *http.Request) (string, error) {
+	if r.config.JWT.Secret == "" {
+		return "", nil
+	}
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.Count(token, ".") != 2 {
+		return "", nil
+	}
+	return claimFromJWT(strings.TrimSpace(token), r.config.JWT.Secret, r.config.JWT.Claim)
+}
+
+// active rejects deactivated tenants
+func active(tenant *models.Tenant, err error) (*models.Tenant, error) {
+	if err == nil && !tenant.Active {
+		return nil, ErrTenantInactive
+	}
+	return tenant, err
+}
+
+// find returns a cached tenant or loads it, mapping a missing row to ErrTenantNotFound
+func (r *Registry) find(ctx context.Context, key string, load func() (*models.Tenant, error)) (*models.Tenant, error) {
+	r.mu.RLock()
+	cached, ok := r.entries[key]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.tenant, nil
+	}
+
+	tenant, err := load()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTenantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.entries[key] = cachedTenant{tenant: tenant, expires: time.Now().Add(r.config.CacheTTL)}
+	r.mu.Unlock()
+	return tenant, nil
+}
+
+// subdomain returns the tenant label of a host under the base domain
+func (r *Registry) subdomain(host string) (string, bool) {
+	if r.config.BaseDomain == "" {
+		return "", false
+	}
+	label, ok := strings.CutSuffix(host, "."+r.config.BaseDomain)
+	if !ok || label == "" || strings.Contains(label, ".") || label == "www" {
+		return "", false
+	}
+	return label, true
+}
+
+func (r *Registry) isBaseDomain(host string) bool {
+	return host == r.config.BaseDomain || host == "www."+r.config.BaseDomain || strings.HasSuffix(host, "."+r.config.BaseDomain)
+}
+
+// hostname strips the port and lowercases a Host header
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// Resolve returns the tenant registry, or nil when tenancy is disabled
+func Resolve(app foundation.Application) *Registry {
+	registry, err := app.Make("tenancy")
+	if err != nil {
+		return nil
+	}
+	return registry.(*Registry)
+}
+
+// MustResolve resolves the tenant registry from the container
+// It panics if the resolution fails, which is acceptable during app boot
+func MustResolve(app foundation.Application) *Registry {
+	registry, err := app.Make("tenancy")
+	if err != nil {
+		panic("failed to resolve tenant registry: " + err.Error())
+	}
+	return registry.(*Registry)
+}
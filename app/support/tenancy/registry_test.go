@@ -0,0 +1,159 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"skeleton/app/models"
+
+	"gorm.io/gorm"
+)
+
+// fakeLookup is a tenant table in memory, counting the lookups
+type fakeLookup struct {
+	tenants []*models.Tenant
+	calls   int
+}
+
+func (l *fakeLookup) FindBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	l.calls++
+	for _, tenant := range l.tenants {
+		if tenant.Slug == slug {
+			return tenant, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (l *fakeLookup) FindByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
+	l.calls++
+	for _, tenant := range l.tenants {
+		if tenant.Domain != nil && *tenant.Domain == domain {
+			return tenant, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (l *fakeLookup) GetActive(ctx context.Context) ([]*models.Tenant, error) {
+	var active []*models.Tenant
+	for _, tenant := range l.tenants {
+		if tenant.Active {
+			active = append(active, tenant)
+		}
+	}
+	return active, nil
+}
+
+func newFakeLookup() *fakeLookup {
+	domain := "shop.acme.io"
+	return &fakeLookup{tenants: []*models.Tenant{
+		{Slug: "acme", Schema: "tenant_acme", Domain: &domain, Active: true},
+		{Slug: "beta", Schema: "tenant_beta", Active: true},
+		{Slug: "gone", Schema: "tenant_gone"},
+	}}
+}
+
+func TestRegistryResolve(t *testing.T) {
+	const secret = "secret"
+	bearer := func(slug string) string {
+		return "Bearer " + signJWT(t, "HS256", secret, map[string]interface{}{"tenant": slug})
+	}
+
+	tests := []struct {
+		name      string
+		resolvers []string
+		host      string
+		headers   map[string]string
+		want      string
+		wantErr   error
+	}{
+		{name: "subdomain", resolvers: []string{"subdomain"}, host: "acme.example.com:8080", want: "acme"},
+		{name: "subdomain case and trailing dot", resolvers: []string{"subdomain"}, host: "ACME.example.com.", want: "acme"},
+		{name: "base domain", resolvers: []string{"subdomain"}, host: "example.com", wantErr: ErrNoTenant},
+		{name: "www", resolvers: []string{"subdomain"}, host: "www.example.com", wantErr: ErrNoTenant},
+		{name: "nested subdomain", resolvers: []string{"subdomain"}, host: "a.acme.example.com", wantErr: ErrNoTenant},
+		{name: "custom domain", resolvers: []string{"subdomain"}, host: "shop.acme.io", want: "acme"},
+		{name: "unknown custom domain", resolvers: []string{"subdomain"}, host: "unknown.io", wantErr: ErrNoTenant},
+		{name: "ip host", resolvers: []string{"subdomain"}, host: "10.0.0.1:8080", wantErr: ErrNoTenant},
+		{name: "unknown subdomain", resolvers: []string{"subdomain"}, host: "nope.example.com", wantErr: ErrTenantNotFound},
+		{name: "inactive tenant", resolvers: []string{"subdomain"}, host: "gone.example.com", wantErr: ErrTenantInactive},
+		{name: "header", resolvers: []string{"header"}, headers: map[string]string{"X-Tenant": "beta"}, want: "beta"},
+		{name: "header matching the token", resolvers: []string{"header"}, headers: map[string]string{"X-Tenant": "beta", "Authorization": bearer("beta")}, want: "beta"},
+		{name: "header naming another tenant than the token", resolvers: []string{"header"}, headers: map[string]string{"X-Tenant": "beta", "Authorization": bearer("acme")}, wantErr: ErrTenantMismatch},
+		{name: "header with an invalid token", resolvers: []string{"header"}, headers: map[string]string{"X-Tenant": "beta", "Authorization": "Bearer a.b.c"}, wantErr: ErrInvalidToken},
+		{name: "jwt", resolvers: []string{"jwt"}, headers: map[string]string{"Authorization": bearer("beta")}, want: "beta"},
+		{name: "jwt without a token", resolvers: []string{"jwt"}, wantErr: ErrNoTenant},
+		// API keys are bearer tokens too, but not JWTs
+		{name: "jwt with an api key", resolvers: []string{"jwt"}, headers: map[string]string{"Authorization": "Bearer sk_abcd_secret"}, wantErr: ErrNoTenant},
+		{name: "resolvers in order", resolvers: []string{"subdomain", "header"}, host: "acme.example.com", headers: map[string]string{"X-Tenant": "beta"}, want: "acme"},
+		{name: "next resolver", resolvers: []string{"subdomain", "header"}, host: "example.com", headers: map[string]string{"X-Tenant": "beta"}, want: "beta"},
+		{name: "no tenant", resolvers: []string{"subdomain", "header", "jwt"}, host: "example.com", wantErr: ErrNoTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(Config{
+				Resolvers:  tt.resolvers,
+				BaseDomain: "example.com",
+				JWT:        JWTConfig{Secret: secret},
+			}, newFakeLookup())
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Host = tt.host
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			tenant, err := registry.Resolve(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, %v, want %v", tenant, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tenant.Slug != tt.want {
+				t.Errorf("got tenant %q, want %q", tenant.Slug, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryCache(t *testing.T) {
+	ctx := context.Background()
+	lookup := newFakeLookup()
+	registry := NewRegistry(Config{}, lookup)
+
+	for i := 0; i < 3; i++ {
+		if _, err := registry.FindBySlug(ctx, "acme"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lookup.calls != 1 {
+		t.Errorf("got %d lookups, want 1", lookup.calls)
+	}
+
+	// Misses are not cached, so a new tenant is found at once
+	for i := 0; i < 2; i++ {
+		if _, err := registry.FindBySlug(ctx, "nope"); !errors.Is(err, ErrTenantNotFound) {
+			t.Fatalf("got %v, want ErrTenantNotFound", err)
+		}
+	}
+	if lookup.calls != 3 {
+		t.Errorf("got %d lookups, want 3", lookup.calls)
+	}
+
+	tenant, _ := registry.FindBySlug(ctx, "acme")
+	registry.Forget(tenant)
+	if _, err := registry.FindBySlug(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if lookup.calls != 4 {
+		t.Errorf("got %d lookups after Forget, want 4", lookup.calls)
+	}
+}
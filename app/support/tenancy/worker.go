@@ -0,0 +1,39 @@
+package tenancy
+
+import (
+	"context"
+	"fmt"
+
+	"skeleton/app/support/logging"
+	"skeleton/app/support/worker"
+)
+
+// JobEnricher copies the tenant of the dispatching request into the job metadata
+func JobEnricher() worker.Enricher {
+	return func(ctx context.Context, name string, payload worker.Payload) {
+		if tenant, ok := FromContext(ctx); ok {
+			payload.SetMeta(MetaTenant, tenant.Slug)
+		}
+	}
+}
+
+// JobMiddleware runs jobs dispatched by a tenant in that tenant's context
+// A job whose tenant no longer exists or is inactive fails instead of running against the shared schema
+func JobMiddleware(registry *Registry) worker.Middleware {
+	return func(name string, next worker.HandlerFunc) worker.HandlerFunc {
+		return func(ctx context.Context, payload worker.Payload) error {
+			slug := payload.Meta()[MetaTenant]
+			if slug == "" {
+				return next(ctx, payload)
+			}
+
+			tenant, err := active(registry.FindBySlug(ctx, slug))
+			if err != nil {
+				return fmt.Errorf("resolve tenant %q: %w", slug, err)
+			}
+
+			ctx = logging.With(WithTenant(ctx, tenant), "tenant", tenant.Slug)
+			return next(ctx, payload)
+		}
+	}
+}
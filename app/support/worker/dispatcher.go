@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/donnigundala/dg-core/contracts/foundation"
+	queue "github.com/donnigundala/dg-queue"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Enricher adds metadata from the dispatching context to a job payload
+type Enricher func(ctx context.Context, name string, payload Payload)
+
+// Observer is notified after every dispatch attempt
+type Observer func(ctx context.Context, name string, err error)
+
+// Dispatcher dispatches jobs to the queue, carrying request context
+// (trace context, request ID) in the payload metadata
+type Dispatcher struct {
+	queue     queue.Queue
+	enrichers []Enricher
+	observers []Observer
+}
+
+// NewDispatcher creates a new dispatcher on top of the queue manager
+func NewDispatcher(q queue.Queue) *Dispatcher {
+	return &Dispatcher{queue: q}
+}
+
+// Enrich adds an enricher that runs before every dispatch
+func (d *Dispatcher) Enrich(enricher Enricher) {
+	d.enrichers = append(d.enrichers, enricher)
+}
+
+// Observe adds an observer that runs after every dispatch
+func (d *Dispatcher) Observe(observer Observer) {
+	d.observers = append(d.observers, observer)
+}
+
+// Dispatch pushes a job onto the queue
+// The trace context is injected into the payload so the handler continues the trace
+func (d *Dispatcher) Dispatch(ctx context.Context, name string, payload Payload) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "queue.dispatch "+name,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.operation", "publish"),
+			attribute.String("messaging.destination.name", name),
+		),
+	)
+	defer span.End()
+
+	if payload == nil {
+		payload = Payload{}
+	}
+	injectTraceContext(ctx, payload)
+	for _, enrich := range d.enrichers {
+		enrich(ctx, name, payload)
+	}
+
+	_, err := d.queue.Dispatch(name, map[string]interface{}(payload))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	for _, observe := range d.observers {
+		observe(ctx, name, err)
+	}
+	return err
+}
+
+// MustResolveDispatcher resolves the job dispatcher from the container
+// It panics if the resolution fails, which is acceptable during app boot
+func MustResolveDispatcher(app foundation.Application) *Dispatcher {
+	dispatcher, err := app.Make("jobDispatcher")
+	if err != nil {
+		panic("failed to resolve job dispatcher: " + err.Error())
+	}
+	return dispatcher.(*Dispatcher)
+}
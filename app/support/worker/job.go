@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// MetaKey is the payload key holding job metadata (trace context, request ID, ...)
+const MetaKey = "_meta"
+
+// Payload is the data carried by a queued job
+type Payload map[string]interface{}
+
+// Meta returns the job metadata
+// Metadata may arrive as map[string]interface{} after a round trip through the queue driver
+func (p Payload) Meta() map[string]string {
+	meta := make(map[string]string)
+	switch raw := p[MetaKey].(type) {
+	case map[string]string:
+		for k, v := range raw {
+			meta[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range raw {
+			meta[k] = fmt.Sprint(v)
+		}
+	}
+	return meta
+}
+
+// SetMeta sets a metadata value on the payload
+func (p Payload) SetMeta(key, value string) {
+	meta := p.Meta()
+	meta[key] = value
+	p[MetaKey] = meta
+}
+
+// String returns a string value from the payload
+func (p Payload) String(key string) string {
+	if v, ok := p[key]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// Uint returns an unsigned integer value from the payload
+// JSON-decoded numbers arrive as float64, so all numeric forms are accepted
+func (p Payload) Uint(key string) (uint, error) {
+	switch v := p[key].(type) {
+	case uint:
+		return v, nil
+	case int:
+		return uint(v), nil
+	case int64:
+		return uint(v), nil
+	case float64:
+		return uint(v), nil
+	case string:
+		n, err := strconv.ParseUint(v, 10, 64)
+		return uint(n), err
+	default:
+		return 0, fmt.Errorf("payload key '%s' is not a number", key)
+	}
+}
+
+// Handler defines the interface that all queue job handlers must implement
+type Handler interface {
+	// Name returns the job name the handler processes
+	// Example: "send-welcome-email"
+	Name() string
+
+	// Handle processes a single job
+	Handle(ctx context.Context, payload Payload) error
+}
+
+// HandlerFunc processes a single job
+type HandlerFunc func(ctx context.Context, payload Payload) error
+
+// BaseHandler provides default implementations for common handler methods
+type BaseHandler struct {
+	name string
+}
+
+// NewBaseHandler creates a new base handler for the given job name
+func NewBaseHandler(name string) BaseHandler {
+	return BaseHandler{name: name}
+}
+
+// Name returns the job name
+func (b *BaseHandler) Name() string {
+	return b.name
+}
@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"context"
+
+	"skeleton/app/support/logging"
+)
+
+// RequestIDEnricher copies the request ID of the dispatching request into the job metadata
+func RequestIDEnricher() Enricher {
+	return func(ctx context.Context, name string, payload Payload) {
+		if id := logging.RequestID(ctx); id != "" {
+			payload.SetMeta(logging.MetaRequestID, id)
+		}
+	}
+}
+
+// ContextLogger gives every handler a logger tagged with the job name and the
+// originating request ID, retrievable with logging.FromContext(ctx)
+func ContextLogger() Middleware {
+	return func(name string, next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, payload Payload) error {
+			args := []any{"job", name}
+			if id := payload.Meta()[logging.MetaRequestID]; id != "" {
+				ctx = logging.WithRequestID(ctx, id)
+				args = append(args, "request_id", id)
+			}
+
+			return next(logging.WithContext(ctx, logging.Component("queue").With(args...)), payload)
+		}
+	}
+}
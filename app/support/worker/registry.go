@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	queue "github.com/donnigundala/dg-queue"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware wraps a job handler, e.g. to record metrics or continue a trace
+type Middleware func(name string, next HandlerFunc) HandlerFunc
+
+// Registry holds all registered queue job handlers
+type Registry struct {
+	handlers   map[string]Handler
+	middleware []Middleware
+	logger     *slog.Logger
+}
+
+// NewRegistry creates a new handler registry
+func NewRegistry(logger *slog.Logger) *Registry {
+	return &Registry{
+		handlers: make(map[string]Handler),
+		logger:   logger,
+	}
+}
+
+// Register adds a handler to the registry
+func (r *Registry) Register(handler Handler) {
+	r.handlers[handler.Name()] = handler
+	r.logger.Debug("Queue handler registered", "name", handler.Name())
+}
+
+// Use adds middleware that wraps every handler
+// Middleware runs in the order it was added
+func (r *Registry) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Handle processes a job with the handler registered for its name
+// The job runs in a consumer span that continues the dispatcher's trace
+func (r *Registry) Handle(ctx context.Context, name string, payload Payload) error {
+	handler, ok := r.handlers[name]
+	if !ok {
+		return fmt.Errorf("no queue handler registered for job '%s'", name)
+	}
+
+	ctx = extractTraceContext(ctx, payload)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "queue.process "+name,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.operation", "process"),
+			attribute.String("messaging.destination.name", name),
+		),
+	)
+	defer span.End()
+
+	next := handler.Handle
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		next = r.middleware[i](name, next)
+	}
+
+	err := next(ctx, payload)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// GetNames returns all registered job names
+func (r *Registry) GetNames() []string {
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// ProcessAll starts a queue worker for every handler, running up to concurrency jobs at once
+func (r *Registry) ProcessAll(q queue.Queue, concurrency int) error {
+	for name := range r.handlers {
+		jobName := name
+		err := q.Worker(jobName, concurrency, func(job *queue.Job) error {
+			payload, ok := job.Payload.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("job '%s' has a %T payload, want an object", jobName, job.Payload)
+			}
+			return r.Handle(context.Background(), jobName, Payload(payload))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register queue handler '%s': %w", jobName, err)
+		}
+		r.logger.Info("Queue handler registered with worker", "name", jobName)
+	}
+	return nil
+}
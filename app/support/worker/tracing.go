@@ -0,0 +1,22 @@
+package worker
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const tracerName = "skeleton/app/support/worker"
+
+// injectTraceContext writes the active trace context into the payload metadata
+func injectTraceContext(ctx context.Context, payload Payload) {
+	carrier := propagation.MapCarrier(payload.Meta())
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	payload[MetaKey] = map[string]string(carrier)
+}
+
+// extractTraceContext continues the trace carried in the payload metadata
+func extractTraceContext(ctx context.Context, payload Payload) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(payload.Meta()))
+}
@@ -3,13 +3,25 @@ package bootstrap
 import (
 	"context"
 	"fmt"
-	"log/slog"
+	"net/http"
 	"os"
 	"time"
 
 	appHTTP "skeleton/app/http"
+	"skeleton/app/http/routes"
 	"skeleton/app/jobs"
 	"skeleton/app/providers"
+	"skeleton/app/support/container"
+	"skeleton/app/support/health"
+	"skeleton/app/support/httpcache"
+	"skeleton/app/support/idempotency"
+	applog "skeleton/app/support/logging"
+	"skeleton/app/support/metrics"
+	"skeleton/app/support/ratelimit"
+	"skeleton/app/support/repository"
+	appScheduler "skeleton/app/support/scheduler"
+	"skeleton/app/support/telemetry"
+	"skeleton/app/support/tenancy"
 
 	cache "github.com/donnigundala/dg-cache"
 	"github.com/donnigundala/dg-core/config"
@@ -18,8 +30,6 @@ import (
 	coreHTTP "github.com/donnigundala/dg-core/http"
 	"github.com/donnigundala/dg-core/logging"
 	"github.com/donnigundala/dg-core/validation"
-	filesystem "github.com/donnigundala/dg-filesystem"
-	firebase "github.com/donnigundala/dg-firebase"
 
 	// "github.com/donnigundala/dg-filesystem/drivers/s3" // Uncomment to enable S3 driver
 	queue "github.com/donnigundala/dg-queue"
@@ -46,9 +56,14 @@ type AppConfig struct {
 // Application represents the bootstrapped application.
 type Application struct {
 	foundation *foundation.Application
+	bindings   *container.Recorder
 	logger     *logging.Logger
+	logs       *applog.Logger
+	logConfig  applog.Config
 	config     AppConfig
 	server     *coreHTTP.HTTPServer
+	metrics    *http.Server
+	debug      *http.Server
 	mode       AppMode
 }
 
@@ -57,8 +72,13 @@ func NewApplication(mode AppMode) *Application {
 	basePath, _ := os.Getwd()
 	app := foundation.New(basePath)
 
+	// Bindings made through the recorder are listed by /debug/bindings
+	bindings := container.NewRecorder(app)
+	bindings.Instance("bindings", bindings)
+
 	return &Application{
 		foundation: app,
+		bindings:   bindings,
 		mode:       mode,
 	}
 }
@@ -66,20 +86,21 @@ func NewApplication(mode AppMode) *Application {
 // Boot initializes and bootstraps the application.
 func (a *Application) Boot() error {
 	// Initialize basic logger (before config is loaded)
-	a.logger = a.setupLogger(false)
-	a.foundation.Instance("logger", a.logger)
-	logging.SetDefault(a.logger)
+	if err := a.setupLogger(applog.Config{}); err != nil {
+		return errors.Wrap(err, "failed to configure logging")
+	}
 
 	// Load and Validate Configuration
 	if err := a.loadConfig(); err != nil {
 		return err
 	}
 
-	// Reconfigure logger with debug settings if enabled
-	if a.config.Debug {
-		a.logger = a.setupLogger(true)
-		a.foundation.Instance("logger", a.logger)
-		logging.SetDefault(a.logger)
+	// Reconfigure logger from logging.yaml
+	if err := config.Inject("logging", &a.logConfig); err != nil {
+		return errors.Wrap(err, "failed to load logging configuration")
+	}
+	if err := a.setupLogger(a.logConfig); err != nil {
+		return errors.Wrap(err, "failed to configure logging")
 	}
 
 	a.logger.Info("Starting application",
@@ -106,6 +127,12 @@ func (a *Application) Boot() error {
 		a.server = a.setupHTTPServer()
 	}
 
+	// Setup dedicated metrics listener (if configured)
+	a.metrics = a.setupMetricsServer()
+
+	// Setup internal diagnostics listener (if configured)
+	a.debug = a.setupDebugServer()
+
 	// Register Shutdown Hooks
 	a.registerShutdownHooks()
 
@@ -124,6 +151,22 @@ func (a *Application) Start() error {
 		return err
 	}
 
+	if a.metrics != nil {
+		go func() {
+			if err := a.metrics.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				a.logger.Error("Metrics server error", "error", err)
+			}
+		}()
+	}
+
+	if a.debug != nil {
+		go func() {
+			if err := a.debug.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				a.logger.Error("Debug server error", "error", err)
+			}
+		}()
+	}
+
 	switch a.mode {
 	case ModeWeb:
 		// Web mode: Start HTTP server
@@ -144,22 +187,34 @@ func (a *Application) Start() error {
 	a.foundation.WaitForShutdown()
 
 	a.logger.Info("Application stopped gracefully")
-	return nil
+	return a.logs.Close()
 }
 
-func (a *Application) setupLogger(debug bool) *logging.Logger {
-	level := slog.LevelInfo
-	addSource := false
-	if debug {
-		level = slog.LevelDebug
-		addSource = true
-	}
-	return logging.New(logging.Config{
-		Level:      level,
-		Output:     os.Stdout,
-		JSONFormat: false,
-		AddSource:  addSource,
+func (a *Application) setupLogger(logConfig applog.Config) error {
+	logs, err := applog.New(logConfig, a.config.Debug)
+	if err != nil {
+		return err
+	}
+	if a.logs != nil {
+		_ = a.logs.Close()
+	}
+	a.logs = logs
+
+	// The framework logger writes JSON into the "framework" component, so its records
+	// get the configured outputs, format, level and redaction like every other log
+	a.logger = logging.New(logging.Config{
+		Level:      logs.ComponentLevel("framework"),
+		Output:     logs.Writer("framework"),
+		JSONFormat: true,
+		AddSource:  logConfig.AddSource || a.config.Debug,
 	})
+	a.bindings.Instance("logger", a.logger)
+	logging.SetDefault(a.logger)
+
+	// Application logs (slog.Default, component and request loggers)
+	// also get per-component levels and redaction
+	applog.SetDefault(logs)
+	return nil
 }
 
 func (a *Application) loadConfig() error {
@@ -185,28 +240,83 @@ func (a *Application) registerProviders() error {
 		return errors.Wrap(err, "failed to load cache configuration")
 	}
 
+	var httpCacheConfig httpcache.Config
+	if err := config.Inject("httpcache", &httpCacheConfig); err != nil {
+		return errors.Wrap(err, "failed to load response cache configuration")
+	}
+
 	var queueConfig queue.Config
 	if err := config.Inject("queue", &queueConfig); err != nil {
 		return errors.Wrap(err, "failed to load queue configuration")
 	}
 
+	var rateLimitConfig ratelimit.Config
+	if err := config.Inject("ratelimit", &rateLimitConfig); err != nil {
+		return errors.Wrap(err, "failed to load rate limit configuration")
+	}
+
+	var metricsConfig metrics.Config
+	if err := config.Inject("metrics", &metricsConfig); err != nil {
+		return errors.Wrap(err, "failed to load metrics configuration")
+	}
+
+	var telemetryConfig telemetry.Config
+	if err := config.Inject("telemetry", &telemetryConfig); err != nil {
+		return errors.Wrap(err, "failed to load telemetry configuration")
+	}
+
+	var idempotencyConfig idempotency.Config
+	if err := config.Inject("idempotency", &idempotencyConfig); err != nil {
+		return errors.Wrap(err, "failed to load idempotency configuration")
+	}
+
+	var healthConfig health.Config
+	if err := config.Inject("health", &healthConfig); err != nil {
+		return errors.Wrap(err, "failed to load health configuration")
+	}
+
+	var repositoryConfig repository.Config
+	if err := config.Inject("repository", &repositoryConfig); err != nil {
+		return errors.Wrap(err, "failed to load repository configuration")
+	}
+
+	var tenancyConfig tenancy.Config
+	if err := config.Inject("tenancy", &tenancyConfig); err != nil {
+		return errors.Wrap(err, "failed to load tenancy configuration")
+	}
+
 	// Register providers in dependency order
 	providersToRegister := []foundation.ServiceProvider{
+		// Readiness checks (registered before the infrastructure providers adding their checks)
+		providers.NewHealthServiceProvider(healthConfig, a.config.Env == "production"),
+
 		// Infrastructure layer
 		providers.NewCacheServiceProvider(cacheConfig),
+		providers.NewRateLimitServiceProvider(rateLimitConfig),
+		providers.NewCacheLockServiceProvider(),
+		providers.NewCacheTagServiceProvider(cacheConfig),
+		providers.NewHTTPCacheServiceProvider(httpCacheConfig), // Response cache is tagged for purging
+		providers.NewIdempotencyServiceProvider(idempotencyConfig),
 		providers.NewQueueServiceProvider(queueConfig),
 		providers.NewSchedulerServiceProvider(), // Queue must be registered before Scheduler
 		providers.NewDatabaseServiceProvider(),
-		filesystem.NewFilesystemServiceProvider(), // Filesystem
-		firebase.NewFirebaseServiceProvider(),     // Firebase integration
+		providers.NewFilesystemServiceProvider(), // Filesystem
+		providers.NewFirebaseServiceProvider(),   // Firebase integration
+
+		// Observability (Database must be registered before Logging, Metrics and Telemetry)
+		providers.NewLoggingServiceProvider(a.logConfig),
+		providers.NewTelemetryServiceProvider(telemetryConfig),
+		providers.NewMetricsServiceProvider(metricsConfig),
+		providers.NewWorkerServiceProvider(queueConfig), // Queue job dispatcher and handlers
 
 		// Application layer (order matters: Repositories → Services)
-		providers.NewRepositoryServiceProvider(),
+		providers.NewRepositoryServiceProvider(repositoryConfig),
+		providers.NewTenancyServiceProvider(tenancyConfig), // Repositories must be registered before Tenancy
 		providers.NewServiceLayerProvider(),
 	}
 
 	for _, provider := range providersToRegister {
-		if err := a.foundation.Register(provider); err != nil {
+		if err := a.foundation.Register(a.bindings.Provider(provider)); err != nil {
 			return errors.Wrap(err, fmt.Sprintf("failed to register %T", provider))
 		}
 	}
@@ -234,13 +344,67 @@ func (a *Application) setupHTTPServer() *coreHTTP.HTTPServer {
 		}
 	}
 
-	server := coreHTTP.NewHTTPServer(serverConfig, kernel, coreHTTP.WithHTTPLogger(a.logger.Underlying()))
+	server := coreHTTP.NewHTTPServer(serverConfig, kernel, coreHTTP.WithHTTPLogger(applog.Component("http")))
 
 	a.logger.Info("HTTP server configured", "addr", serverConfig.Addr)
 	return server
 }
 
+func (a *Application) setupMetricsServer() *http.Server {
+	m := metrics.MustResolve(a.foundation)
+	if !m.Enabled() || m.Config().ListenAddr == "" {
+		return nil
+	}
+
+	a.logger.Info("Metrics server configured", "addr", m.Config().ListenAddr, "path", m.Config().Path)
+	return &http.Server{
+		Addr:              m.Config().ListenAddr,
+		Handler:           appHTTP.NewMetricsRouter(m),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+func (a *Application) setupDebugServer() *http.Server {
+	cfg, err := routes.LoadDebugConfig()
+	if err != nil || !cfg.Enabled || cfg.ListenAddr == "" {
+		return nil
+	}
+
+	router := appHTTP.NewDebugRouter(a.foundation, cfg)
+	if router == nil {
+		return nil
+	}
+
+	a.logger.Info("Debug server configured", "addr", cfg.ListenAddr)
+	// No write timeout: CPU profiles and execution traces stream for up to their duration
+	return &http.Server{
+		Addr:              cfg.ListenAddr,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
 func (a *Application) registerShutdownHooks() {
+	a.foundation.RegisterShutdownHook(func() {
+		if a.metrics != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := a.metrics.Shutdown(ctx); err != nil {
+				a.logger.Error("Metrics server shutdown error", "error", err)
+			}
+		}
+	})
+
+	a.foundation.RegisterShutdownHook(func() {
+		if a.debug != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := a.debug.Shutdown(ctx); err != nil {
+				a.logger.Error("Debug server shutdown error", "error", err)
+			}
+		}
+	})
+
 	a.foundation.RegisterShutdownHook(func() {
 		if a.server != nil {
 			a.logger.Info("Shutting down HTTP server...")
@@ -256,6 +420,20 @@ func (a *Application) registerShutdownHooks() {
 		a.logger.Info("Executing cleanup: Closing resources...")
 		a.foundation.StopServices()
 	})
+
+	a.foundation.RegisterShutdownHook(func() {
+		if err := health.MustResolve(a.foundation).Close(); err != nil {
+			a.logger.Error("Health check shutdown error", "error", err)
+		}
+	})
+
+	a.foundation.RegisterShutdownHook(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := telemetry.MustResolve(a.foundation).Shutdown(ctx); err != nil {
+			a.logger.Error("Telemetry shutdown error", "error", err)
+		}
+	})
 }
 
 func (a *Application) registerScheduledJobs() error {
@@ -267,7 +445,15 @@ func (a *Application) registerScheduledJobs() error {
 	}
 
 	// Load all jobs from the registry
-	registry := jobs.LoadAll(a.foundation.Log())
+	registry := jobs.LoadAll(applog.Component("scheduler"))
+
+	// Record run duration and failures for every job
+	m := metrics.MustResolve(a.foundation)
+	registry.Use(func(job appScheduler.ScheduledJob, next func() error) func() error {
+		return func() error {
+			return m.ObserveScheduledJob(job.Name(), next)
+		}
+	})
 
 	// Schedule all enabled jobs
 	if err := registry.ScheduleAll(scheduler); err != nil {
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"skeleton/app/repositories"
+	"skeleton/app/services"
+
+	"github.com/donnigundala/dg-core/config"
+	dgdb "github.com/donnigundala/dg-database"
+)
+
+const usage = `Usage: go run cmd/apikey/main.go <command> [flags]
+
+Commands:
+  create   Issue a new API key (-name, -scopes, -expires, -tenant)
+  list     List all API keys
+  revoke   Revoke an API key (-id)
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	var (
+		configDir = flags.String("config", "config", "Configuration directory")
+		name      = flags.String("name", "", "Key name (create)")
+		scopes    = flags.String("scopes", "", "Comma-separated scopes, e.g. users:read,users:write (create)")
+		expires   = flags.Duration("expires", 0, "Key lifetime, e.g. 720h; 0 never expires (create)")
+		tenant    = flags.String("tenant", "", "Slug of the tenant the key is bound to; empty for the shared schema (create)")
+		id        = flags.Uint("id", 0, "Key ID (revoke)")
+	)
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	// Load configuration
+	if err := config.LoadWithPaths(*configDir); err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	manager, err := connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer manager.Close()
+
+	service := services.NewAPIKeyService(repositories.NewAPIKeyRepository(manager.DB()))
+	ctx := context.Background()
+
+	switch command {
+	case "create":
+		if *name == "" {
+			log.Fatal("-name is required")
+		}
+
+		var expiresAt *time.Time
+		if *expires > 0 {
+			t := time.Now().Add(*expires)
+			expiresAt = &t
+		}
+
+		var tenantID *uint
+		if *tenant != "" {
+			t, err := repositories.NewTenantRepository(manager.DB()).FindBySlug(ctx, *tenant)
+			if err != nil {
+				log.Fatalf("Failed to find tenant %q: %v", *tenant, err)
+			}
+			tenantID = &t.ID
+		}
+
+		plainKey, key, err := service.Create(ctx, *name, splitScopes(*scopes), expiresAt, tenantID)
+		if err != nil {
+			log.Fatalf("Failed to create API key: %v", err)
+		}
+
+		fmt.Printf("API key created (id: %d, prefix: %s)\n", key.ID, key.Prefix)
+		fmt.Printf("Key: %s\n", plainKey)
+		fmt.Println("Store this key now; it cannot be shown again.")
+
+	case "list":
+		keys, err := service.GetAll(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list API keys: %v", err)
+		}
+		if len(keys) == 0 {
+			fmt.Println("No API keys found")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tTENANT\tEXPIRES\tLAST USED\tSTATUS")
+		now := time.Now()
+		for _, key := range keys {
+			status := "active"
+			if key.RevokedAt != nil {
+				status = "revoked"
+			} else if !key.IsActive(now) {
+				status = "expired"
+			}
+			tenantID := "-"
+			if key.TenantID != nil {
+				tenantID = fmt.Sprint(*key.TenantID)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID, key.Name, key.Prefix, key.Scopes, tenantID,
+				formatTime(key.ExpiresAt), formatTime(key.LastUsedAt), status)
+		}
+		w.Flush()
+
+	case "revoke":
+		if *id == 0 {
+			log.Fatal("-id is required")
+		}
+		if err := service.Revoke(ctx, *id); err != nil {
+			log.Fatalf("Failed to revoke API key %d: %v", *id, err)
+		}
+		fmt.Printf("API key %d revoked\n", *id)
+
+	default:
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+}
+
+// connect opens the primary database connection from the flat db.* configuration.
+func connect() (*dgdb.Manager, error) {
+	port := 5432 // default
+	if p := config.Get("db.port"); p != nil {
+		if portInt, ok := p.(int); ok {
+			port = portInt
+		}
+	}
+
+	dbConfig := dgdb.DefaultConfig().
+		WithDriver(config.GetString("db.driver")).
+		WithHost(config.GetString("db.host")).
+		WithPort(port).
+		WithDatabase(config.GetString("db.name")).
+		WithCredentials(
+			config.GetString("db.username"),
+			config.GetString("db.password"),
+		)
+
+	if schema := config.GetString("db.schema"); schema != "" {
+		dbConfig = dbConfig.WithSchema(schema)
+	}
+
+	return dgdb.NewManager(dbConfig, nil)
+}
+
+func splitScopes(value string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// repositoryConfig enables the caching decorator per repository
+const repositoryConfig = "config/repository.yaml"
+
+// entity is an entity name with the fields make:crud generates its stack from; the
+// entities of the other commands have no fields, and get placeholders instead
+type entity struct {
+	names
+	Fields []field
+	// fromModel is set when the fields were read from an existing model, which is kept as is
+	fromModel bool
+}
+
+// crudEntity reads the entity of make:crud from a YAML spec (a .yaml or .yml path), or
+// else from the existing model of that name.
+func crudEntity(arg string) (entity, error) {
+	if ext := filepath.Ext(arg); ext == ".yaml" || ext == ".yml" {
+		s, err := readSpec(arg)
+		if err != nil {
+			return entity{}, err
+		}
+		n := newNames(s.Name)
+		if !n.valid() {
+			return entity{}, fmt.Errorf("%s: invalid name %q: use a name such as Product or OrderItem", arg, s.Name)
+		}
+		return entity{names: n, Fields: s.Fields}, nil
+	}
+
+	n := newNames(arg)
+	if !n.valid() {
+		return entity{}, fmt.Errorf("invalid name %q: use a name such as Product or OrderItem, or a YAML spec", arg)
+	}
+	path := filepath.Join("app", "models", n.Snake+".go")
+	if _, err := os.Stat(path); err != nil {
+		return entity{}, fmt.Errorf("%s does not exist: write the model first, or pass a YAML spec", path)
+	}
+	fields, err := modelFields(path, n.Name)
+	if err != nil {
+		return entity{}, err
+	}
+	return entity{names: n, Fields: fields, fromModel: true}, nil
+}
+
+// crud creates every layer of an entity from its fields: the model (unless it exists), the
+// migration, the repository with a filtered List, the service, the controller with its
+// DTOs, routes and tests, and enables the repository cache.
+func (g *generator) crud(e entity) error {
+	model := g.model
+	if e.fromModel {
+		model = func(e entity) error { return registerModel(e.names) }
+	}
+	steps := []func(entity) error{model, g.migration, g.repository, g.service, g.controller, g.controllerTest, enableCache}
+	for _, step := range steps {
+		if err := step(e); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\nNext: review the migration, run go run ./cmd/migrate up, then go test ./app/http/controllers -run %sController\n", e.Name)
+	return nil
+}
+
+// controllerTest creates the table-driven tests of the controller.
+func (g *generator) controllerTest(e entity) error {
+	return g.write(filepath.Join("app", "http", "controllers", e.Snake+"_controller_test.go"), "controller_test.go.tmpl", e)
+}
+
+// enableCache enables the caching decorator of the repository under repository.cache in
+// config/repository.yaml, editing the YAML nodes so the other entries and comments are kept.
+func enableCache(e entity) error {
+	binding := e.Var + "Repository"
+	src, err := os.ReadFile(repositoryConfig)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return fmt.Errorf("%s: %w", repositoryConfig, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return fmt.Errorf("%s is not a YAML mapping", repositoryConfig)
+	}
+
+	node := doc.Content[0]
+	for _, key := range []string{"repository", "cache"} {
+		if node, err = mappingValue(node, key); err != nil {
+			return fmt.Errorf("%s: %w", repositoryConfig, err)
+		}
+	}
+	if findKey(node, binding) != nil {
+		fmt.Printf("Skipped %s (%s is configured)\n", repositoryConfig, binding)
+		return nil
+	}
+
+	entry := &yaml.Node{Kind: yaml.MappingNode}
+	for _, kv := range [][2]string{{"enabled", "true"}, {"prefix", e.Snake}, {"ttl", "5m"}, {"list_ttl", "1m"}} {
+		entry.Content = append(entry.Content, scalar(kv[0]), scalar(kv[1]))
+	}
+	node.Content = append(node.Content, scalar(binding), entry)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(repositoryConfig, out.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s\n", repositoryConfig)
+	return nil
+}
+
+// mappingValue returns the mapping under key in a mapping node, adding it when the key is
+// missing or empty.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the parent of %s is not a mapping", key)
+	}
+	value := findKey(node, key)
+	if value == nil {
+		value = &yaml.Node{Kind: yaml.MappingNode}
+		node.Content = append(node.Content, scalar(key), value)
+	}
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+		value.Kind, value.Tag, value.Value = yaml.MappingNode, "", ""
+	}
+	if value.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a mapping", key)
+	}
+	return value, nil
+}
+
+// findKey returns the value of key in a mapping node, or nil.
+func findKey(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalar returns a plain scalar node.
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+// Filters returns the fields List filters on.
+func (e entity) Filters() []field {
+	var fields []field
+	for _, f := range e.Fields {
+		if f.Filter {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// SortOptions returns the values of the sort parameter of List, e.g. "id -id name -name".
+func (e entity) SortOptions() string {
+	options := []string{"id", "-id"}
+	for _, f := range e.Fields {
+		if f.Sort {
+			options = append(options, f.Column, "-"+f.Column)
+		}
+	}
+	return strings.Join(options, " ")
+}
+
+// HasTime reports whether a field is a time.Time, for the imports of the DTOs.
+func (e entity) HasTime() bool {
+	for _, f := range e.Fields {
+		if f.Type == "time" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRequired reports whether the create request has a required field.
+func (e entity) HasRequired() bool {
+	for _, f := range e.Fields {
+		if f.Required() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestEnableCache(t *testing.T) {
+	tests := []struct {
+		name string
+		// src is the content of config/repository.yaml
+		src     string
+		wantErr bool
+	}{
+		{name: "application config", src: readFile(t, filepath.Join("..", "..", repositoryConfig))},
+		{name: "empty file", src: ""},
+		{name: "no cache section", src: "repository:\n  other: 1\nlogging:\n  level: info\n"},
+		{name: "empty cache section", src: "repository:\n  cache:\n"},
+		{name: "entries after the cache section", src: "repository:\n  cache:\n    userRepository:\n      enabled: true\nother:\n  key: 1\n"},
+		{name: "cache is not a mapping", src: "repository:\n  cache: []\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "config"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, repositoryConfig), []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(dir)
+
+			err := enableCache(entity{names: newNames("OrderItem")})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if got := readFile(t, repositoryConfig); got != tt.src {
+					t.Errorf("a failed edit changed the file:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			patched := readFile(t, repositoryConfig)
+
+			var before, after map[string]interface{}
+			if err := yaml.Unmarshal([]byte(tt.src), &before); err != nil {
+				t.Fatal(err)
+			}
+			if err := yaml.Unmarshal([]byte(patched), &after); err != nil {
+				t.Fatalf("the edited file does not parse: %v", err)
+			}
+			cache, _ := after["repository"].(map[string]interface{})["cache"].(map[string]interface{})
+			entry, _ := cache["orderItemRepository"].(map[string]interface{})
+			if entry["enabled"] != true || entry["prefix"] != "order_item" {
+				t.Errorf("got entry %v in:\n%s", entry, patched)
+			}
+
+			// Every other setting is kept
+			delete(cache, "orderItemRepository")
+			for key, value := range before {
+				if key == "repository" {
+					continue
+				}
+				if !reflect.DeepEqual(after[key], value) {
+					t.Errorf("got %s: %v, want %v", key, after[key], value)
+				}
+			}
+			if repository, ok := before["repository"].(map[string]interface{}); ok {
+				if old, ok := repository["cache"].(map[string]interface{}); ok && !reflect.DeepEqual(cache, old) {
+					t.Errorf("got the other caches %v, want %v", cache, old)
+				}
+			}
+
+			// Enabling again changes nothing
+			if err := enableCache(entity{names: newNames("OrderItem")}); err != nil {
+				t.Fatal(err)
+			}
+			if again := readFile(t, repositoryConfig); again != patched {
+				t.Errorf("a second run changed the file:\n%s", again)
+			}
+		})
+	}
+}
+
+// crudSpec has a field of every type, with each of the field options
+const crudSpec = `name: Product
+fields:
+  - name: Name
+    type: string
+    size: 100
+    rules: required,min=3
+    filter: true
+    sort: true
+  - name: SKU
+    type: string
+    unique: true
+    rules: required,alphanum
+  - name: Description
+    type: text
+    nullable: true
+  - name: Stock
+    type: int
+    rules: gte=0
+    sort: true
+  - name: Views
+    type: int64
+  - name: CategoryID
+    type: uint
+    filter: true
+  - name: Price
+    type: float64
+    rules: required,gt=0
+  - name: Active
+    type: bool
+    filter: true
+  - name: ReleasedAt
+    type: time
+    nullable: true
+`
+
+func TestCrudVets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("copies the project and runs go vet")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not in PATH")
+	}
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"go.mod", "go.sum", "app", "bootstrap", "cmd", "config", "database"} {
+		if name == "go.mod" || name == "go.sum" {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(readFile(t, filepath.Join(root, name))), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.CopyFS(filepath.Join(dir, name), os.DirFS(filepath.Join(root, name))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := vetEnv(t, goTool, root, dir)
+	t.Chdir(dir)
+
+	if err := os.WriteFile("product.yaml", []byte(crudSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e, err := crudEntity("product.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&generator{}).crud(e); err != nil {
+		t.Fatal(err)
+	}
+
+	// The table is created in the tenant schemas too
+	tenant, _ := filepath.Glob(filepath.Join(migrationsDir, "tenant", "*_create_products_table.up.sql"))
+	if len(tenant) != 1 {
+		t.Errorf("got tenant migrations %v, want one", tenant)
+	}
+
+	vet := exec.Command(goTool, "vet", "./...")
+	vet.Dir = dir
+	vet.Env = env
+	if out, err := vet.CombinedOutput(); err != nil {
+		t.Fatalf("go vet of the generated stack: %v\n%s", err, out)
+	}
+}
+
+// vetEnv returns the environment of go vet in dir, a copy of the project at root: a go.work
+// in use is copied along, with the copy used in place of the project
+func vetEnv(t *testing.T, goTool, root, dir string) []string {
+	t.Helper()
+	cmd := exec.Command(goTool, "env", "GOWORK")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := strings.TrimSpace(string(out))
+	if work == "" || work == "off" {
+		return os.Environ()
+	}
+
+	copied := filepath.Join(dir, "go.work")
+	if err := os.WriteFile(copied, []byte(readFile(t, work)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edit := exec.Command(goTool, "work", "edit", "-dropuse="+root, "-use="+dir, copied)
+	if out, err := edit.CombinedOutput(); err != nil {
+		t.Fatalf("go work edit: %v\n%s", err, out)
+	}
+	return append(os.Environ(), "GOWORK="+copied)
+}
+
+// readFile returns the content of a file
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(src)
+}
@@ -0,0 +1,617 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm/schema"
+)
+
+// field is a column of a make:crud entity, read from a YAML spec or an existing model
+type field struct {
+	Name     string `yaml:"name"`     // Go field name, e.g. UnitPrice
+	Column   string `yaml:"column"`   // unit_price by default
+	Type     string `yaml:"type"`     // one of fieldTypes
+	Size     int    `yaml:"size"`     // length of a string column, 255 by default
+	Nullable bool   `yaml:"nullable"` // a NULL column, a pointer field
+	Unique   bool   `yaml:"unique"`
+	Rules    string `yaml:"rules"`  // validate rules of the create request, e.g. required,min=3
+	Filter   bool   `yaml:"filter"` // List accepts ?<column>=value; the column is indexed
+	Sort     bool   `yaml:"sort"`   // List accepts ?sort=<column>; the column is indexed
+}
+
+// spec is the YAML description of a make:crud entity
+type spec struct {
+	Name   string  `yaml:"name"`
+	Fields []field `yaml:"fields"`
+}
+
+// fieldTypes maps the field types to their Go types
+var fieldTypes = map[string]string{
+	"string":  "string",
+	"text":    "string",
+	"int":     "int",
+	"int64":   "int64",
+	"uint":    "uint",
+	"float64": "float64",
+	"bool":    "bool",
+	"time":    "time.Time",
+}
+
+// sqlTypes maps the field types to their column types, by dialect ("" for PostgreSQL)
+var sqlTypes = map[string]map[string]string{
+	"": {
+		"text": "TEXT", "int": "INTEGER", "int64": "BIGINT", "uint": "BIGINT",
+		"float64": "DOUBLE PRECISION", "bool": "BOOLEAN", "time": "TIMESTAMP",
+	},
+	"mysql": {
+		"text": "TEXT", "int": "INT", "int64": "BIGINT", "uint": "INT UNSIGNED",
+		"float64": "DOUBLE", "bool": "BOOLEAN", "time": "DATETIME",
+	},
+	"sqlite": {
+		"text": "TEXT", "int": "INTEGER", "int64": "INTEGER", "uint": "INTEGER",
+		"float64": "REAL", "bool": "BOOLEAN", "time": "TIMESTAMP",
+	},
+}
+
+// reservedFields are the fields every generated model has
+var reservedFields = map[string]bool{"ID": true, "CreatedAt": true, "UpdatedAt": true, "DeletedAt": true}
+
+// ruleNumber matches a numeric bound of the validate rules, e.g. min=3
+var ruleNumber = regexp.MustCompile(`^(min|max|len|gte|gt|lte|lt)=(-?[0-9.]+)$`)
+
+// numericText matches the strings of the numeric rule
+var numericText = regexp.MustCompile(`^[-+]?[0-9]+(?:\.[0-9]+)?$`)
+
+// boundRules are the rules taking a number, matched by ruleNumber
+var boundRules = map[string]bool{"min": true, "max": true, "len": true, "gte": true, "gt": true, "lte": true, "lt": true}
+
+// textRules are the rules of strings Sample can build a passing value for
+var textRules = map[string]bool{
+	"email": true, "url": true, "http_url": true, "uuid": true, "uuid4": true,
+	"alpha": true, "alphanum": true, "alphaunicode": true, "alphanumunicode": true, "numeric": true, "number": true,
+	"lowercase": true, "uppercase": true, "ascii": true, "printascii": true,
+	"startswith": true, "endswith": true, "contains": true,
+}
+
+// sampleRules are the other rules Sample can build a passing value for; a field with a
+// rule of none of these maps is rejected, since its generated tests would fail
+var sampleRules = map[string]bool{"required": true, "omitempty": true, "oneof": true}
+
+// readSpec reads a YAML spec and checks its fields.
+func readSpec(path string) (spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return spec{}, err
+	}
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return spec{}, fmt.Errorf("Failed to parse %s: %w", path, err)
+	}
+	if len(s.Fields) == 0 {
+		return spec{}, fmt.Errorf("%s has no fields", path)
+	}
+	for i := range s.Fields {
+		if err := s.Fields[i].check(); err != nil {
+			return spec{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return s, checkColumns(s.Fields)
+}
+
+// modelFields reads the fields of an existing model struct. Validate rules come from a
+// validate tag, or else from the gorm tag; indexed columns can be filtered and sorted on.
+func modelFields(path, name string) ([]field, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var st *ast.StructType
+	ast.Inspect(file, func(node ast.Node) bool {
+		if spec, ok := node.(*ast.TypeSpec); ok && spec.Name.Name == name {
+			st, _ = spec.Type.(*ast.StructType)
+		}
+		return st == nil
+	})
+	if st == nil {
+		return nil, fmt.Errorf("%s has no struct %s", path, name)
+	}
+
+	var fields []field
+	for _, f := range st.Fields.List {
+		if len(f.Names) != 1 || !f.Names[0].IsExported() || reservedFields[f.Names[0].Name] {
+			continue
+		}
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			value, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(value)
+		}
+		gorm := schema.ParseTagSetting(tag.Get("gorm"), ";")
+		if _, ignored := gorm["-"]; ignored {
+			continue
+		}
+
+		fd := field{Name: f.Names[0].Name, Column: gorm["COLUMN"], Rules: tag.Get("validate")}
+		typ := f.Type
+		if star, ok := typ.(*ast.StarExpr); ok {
+			fd.Nullable, typ = true, star.X
+		}
+		switch t := typ.(type) {
+		case *ast.Ident:
+			fd.Type = t.Name
+		case *ast.SelectorExpr:
+			if isIdent(t.X, "time") && t.Sel.Name == "Time" {
+				fd.Type = "time"
+			}
+		}
+		if strings.EqualFold(gorm["TYPE"], "text") {
+			fd.Type = "text"
+		}
+		if _, ok := fieldTypes[fd.Type]; !ok {
+			fmt.Printf("Skipped the field %s: its type is not supported\n", fd.Name)
+			continue
+		}
+
+		fd.Size, _ = strconv.Atoi(gorm["SIZE"])
+		_, notNull := gorm["NOT NULL"]
+		_, unique := gorm["UNIQUEINDEX"]
+		_, index := gorm["INDEX"]
+		_, uniqueColumn := gorm["UNIQUE"]
+		fd.Unique = unique || uniqueColumn
+		fd.Filter = (index || fd.Unique) && fd.Type != "text" && fd.Type != "time"
+		fd.Sort = (index || fd.Unique) && fd.Type != "text"
+		if fd.Rules == "" && notNull && (fd.Type == "string" || fd.Type == "text") {
+			fd.Rules = "required"
+		}
+		if err := fd.check(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		fields = append(fields, fd)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s: %s has no fields to generate from", path, name)
+	}
+	return fields, checkColumns(fields)
+}
+
+// check validates a field and fills in its defaults.
+func (f *field) check() error {
+	if !token.IsIdentifier(f.Name) || !token.IsExported(f.Name) {
+		return fmt.Errorf("invalid field name %q: use an exported Go name such as UnitPrice", f.Name)
+	}
+	if reservedFields[f.Name] {
+		return fmt.Errorf("the field %s is generated for every model", f.Name)
+	}
+	if _, ok := fieldTypes[f.Type]; !ok {
+		return fmt.Errorf("the field %s has the unknown type %q (string, text, int, int64, uint, float64, bool or time)", f.Name, f.Type)
+	}
+	if f.Filter && (f.Type == "text" || f.Type == "time") {
+		return fmt.Errorf("the field %s can't be filtered: %s columns are not compared for equality", f.Name, f.Type)
+	}
+	if f.Sort && f.Type == "text" {
+		return fmt.Errorf("the field %s can't be sorted: text columns are not indexed", f.Name)
+	}
+	if f.Column == "" {
+		f.Column = schema.NamingStrategy{}.ColumnName("", f.Name)
+	}
+	if f.Type == "string" && f.Size <= 0 {
+		f.Size = 255
+	}
+	if _, err := f.sample(); err != nil {
+		return fmt.Errorf("the field %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// checkColumns rejects two fields with the same column.
+func checkColumns(fields []field) error {
+	seen := map[string]bool{"id": true, "created_at": true, "updated_at": true}
+	for _, f := range fields {
+		if seen[f.Column] {
+			return fmt.Errorf("the column %s is defined twice", f.Column)
+		}
+		seen[f.Column] = true
+	}
+	return nil
+}
+
+// GoType is the type of the model field, a pointer when the column is nullable.
+func (f field) GoType() string {
+	if f.Nullable {
+		return "*" + fieldTypes[f.Type]
+	}
+	return fieldTypes[f.Type]
+}
+
+// BaseType is the type of the model field without the pointer.
+func (f field) BaseType() string {
+	return fieldTypes[f.Type]
+}
+
+// Gorm is the gorm tag of the model field.
+func (f field) Gorm() string {
+	var settings []string
+	switch f.Type {
+	case "string":
+		settings = append(settings, fmt.Sprintf("size:%d", f.Size))
+	case "text":
+		settings = append(settings, "type:text")
+	}
+	if f.Unique {
+		settings = append(settings, "uniqueIndex")
+	} else if f.Indexed() {
+		settings = append(settings, "index")
+	}
+	if !f.Nullable {
+		settings = append(settings, "not null")
+	}
+	return strings.Join(settings, ";")
+}
+
+// Indexed reports whether the column gets an index of its own.
+func (f field) Indexed() bool {
+	return !f.Unique && (f.Filter || f.Sort)
+}
+
+// Required reports whether the create request must have the field.
+func (f field) Required() bool {
+	for _, rule := range strings.Split(f.Rules, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateRules are the validate rules of the field in the create request. String lengths
+// are bounded by the column size, and nullable fields may be left out.
+func (f field) CreateRules() string {
+	rules := f.rules()
+	if f.Type == "string" && !f.bounded(rules) {
+		rules = append(rules, fmt.Sprintf("max=%d", f.Size))
+	}
+	if f.Nullable && !f.Required() && len(rules) > 0 && rules[0] != "omitempty" {
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+// UpdateRules are the validate rules of the field in the update request, where every field
+// is optional.
+func (f field) UpdateRules() string {
+	var rules []string
+	for _, rule := range strings.Split(f.CreateRules(), ",") {
+		if rule != "" && rule != "omitempty" && !strings.HasPrefix(rule, "required") {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	return strings.Join(append([]string{"omitempty"}, rules...), ",")
+}
+
+// SQL returns the column definition of the field in a CREATE TABLE, by dialect.
+func (f field) SQL(dialect string) string {
+	typ := sqlTypes[dialect][f.Type]
+	if f.Type == "string" {
+		typ = fmt.Sprintf("VARCHAR(%d)", f.Size)
+	}
+	if f.Unique {
+		typ += " UNIQUE"
+	}
+	if !f.Nullable {
+		typ += " NOT NULL"
+	}
+	return fmt.Sprintf("%s %s", f.Column, typ)
+}
+
+// Sample is a JSON value of the field passing its create rules, for the generated tests.
+// check has made sure there is one.
+func (f field) Sample() string {
+	sample, _ := f.sample()
+	return sample
+}
+
+// sample builds the JSON value of Sample, or explains why no value passes the rules.
+func (f field) sample() (string, error) {
+	text := f.Type == "string" || f.Type == "text"
+	bounds := map[string]float64{}
+	var options []string
+	for _, rule := range f.rules() {
+		name, param, _ := strings.Cut(rule, "=")
+		if !sampleRules[name] && !boundRules[name] && !textRules[name] {
+			return "", fmt.Errorf("make:crud can't generate a value passing the rule %q; add it to the generated request instead", rule)
+		}
+		if textRules[name] && !text {
+			return "", fmt.Errorf("the rule %q only applies to strings", rule)
+		}
+		switch {
+		case name == "oneof":
+			if options = strings.Fields(param); len(options) == 0 {
+				return "", fmt.Errorf("the rule %q has no options", rule)
+			}
+		case boundRules[name]:
+			m := ruleNumber.FindStringSubmatch(rule)
+			if m == nil {
+				return "", fmt.Errorf("the rule %q needs a number", rule)
+			}
+			bounds[m[1]], _ = strconv.ParseFloat(m[2], 64)
+		}
+	}
+
+	switch {
+	case f.Type == "bool":
+		return "true", nil
+	case f.Type == "time":
+		return `"2024-01-02T15:04:05Z"`, nil
+	case !text:
+		return f.numberSample(options, bounds)
+	}
+
+	var sample string
+	switch {
+	case len(options) > 0:
+		sample = options[0]
+	case f.hasRule("email"):
+		sample = "user@example.com"
+	case f.hasRule("url", "http_url"):
+		sample = "https://example.com"
+	case f.hasRule("uuid", "uuid4"):
+		sample = "123e4567-e89b-42d3-a456-426614174000"
+	default:
+		sample = f.textSample(bounds)
+	}
+	if !f.passes(sample, bounds) {
+		return "", fmt.Errorf("no generated value passes the rules %q", f.Rules)
+	}
+	return strconv.Quote(sample), nil
+}
+
+// textSample is a string of the character class of the rules, built from the field name,
+// with the required prefix, suffix and substrings, and fitted to the length bounds.
+func (f field) textSample(bounds map[string]float64) string {
+	var prefix, suffix, contains string
+	for _, rule := range f.rules() {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "startswith":
+			prefix = param
+		case "endswith":
+			suffix = param
+		case "contains":
+			contains += param
+		}
+	}
+
+	body := "sample " + strings.ReplaceAll(schema.NamingStrategy{}.ColumnName("", f.Name), "_", " ")
+	filler := "a"
+	switch {
+	case f.hasRule("numeric", "number"):
+		body, filler = "1", "0"
+	case f.hasRule("alpha", "alphaunicode"):
+		body = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return r
+			}
+			return -1
+		}, body)
+	case f.hasRule("alphanum", "alphanumunicode"):
+		body = strings.ReplaceAll(body, " ", "")
+	}
+	if f.hasRule("uppercase") {
+		body, filler = strings.ToUpper(body), "A"
+	}
+
+	minLen, maxLen := f.lengthBounds(bounds)
+	free := utf8.RuneCountInString(prefix + contains + suffix)
+	if maxLen >= 0 && len(body) > maxLen-free {
+		body = body[:max(maxLen-free, 0)]
+	}
+	if len(body) < minLen-free {
+		body += strings.Repeat(filler, minLen-free-len(body))
+	}
+	return prefix + body + contains + suffix
+}
+
+// lengthBounds returns the length a string must have at least and at most, -1 when unbounded.
+func (f field) lengthBounds(bounds map[string]float64) (minLen, maxLen int) {
+	maxLen = -1
+	if f.Type == "string" {
+		maxLen = f.Size
+	}
+	atMost := func(n int) {
+		if maxLen < 0 || n < maxLen {
+			maxLen = n
+		}
+	}
+	for rule, bound := range bounds {
+		n := int(bound)
+		switch rule {
+		case "min", "gte":
+			minLen = max(minLen, n)
+		case "gt":
+			minLen = max(minLen, n+1)
+		case "max", "lte":
+			atMost(n)
+		case "lt":
+			atMost(n - 1)
+		case "len":
+			minLen = max(minLen, n)
+			atMost(n)
+		}
+	}
+	return minLen, maxLen
+}
+
+// passes reports whether a string sample passes the rules and fits the column.
+func (f field) passes(sample string, bounds map[string]float64) bool {
+	minLen, maxLen := f.lengthBounds(bounds)
+	if n := utf8.RuneCountInString(sample); n < minLen || (maxLen >= 0 && n > maxLen) {
+		return false
+	}
+	every := func(ok func(rune) bool) bool {
+		return sample != "" && strings.IndexFunc(sample, func(r rune) bool { return !ok(r) }) < 0
+	}
+	isASCIILetter := func(r rune) bool { return r < unicode.MaxASCII && unicode.IsLetter(r) }
+	for _, rule := range f.rules() {
+		name, param, _ := strings.Cut(rule, "=")
+		ok := true
+		switch name {
+		case "alpha":
+			ok = every(isASCIILetter)
+		case "alphanum":
+			ok = every(func(r rune) bool { return isASCIILetter(r) || (r >= '0' && r <= '9') })
+		case "alphaunicode":
+			ok = every(unicode.IsLetter)
+		case "alphanumunicode":
+			ok = every(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+		case "numeric":
+			ok = numericText.MatchString(sample)
+		case "number":
+			ok = every(func(r rune) bool { return r >= '0' && r <= '9' })
+		case "lowercase":
+			ok = sample != "" && sample == strings.ToLower(sample)
+		case "uppercase":
+			ok = sample != "" && sample == strings.ToUpper(sample)
+		case "ascii":
+			ok = every(func(r rune) bool { return r <= unicode.MaxASCII })
+		case "printascii":
+			ok = every(func(r rune) bool { return r >= ' ' && r <= '~' })
+		case "startswith":
+			ok = strings.HasPrefix(sample, param)
+		case "endswith":
+			ok = strings.HasSuffix(sample, param)
+		case "contains":
+			ok = strings.Contains(sample, param)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// numberSample is the first option of oneof, or else 1 moved within the bounds.
+func (f field) numberSample(options []string, bounds map[string]float64) (string, error) {
+	value := 1.0
+	if len(options) > 0 {
+		var err error
+		if value, err = strconv.ParseFloat(options[0], 64); err != nil {
+			return "", fmt.Errorf("the option %q of oneof is not a number", options[0])
+		}
+	} else {
+		// Integers step over the strict bounds; floats land between them
+		step := 1.0
+		if f.Type == "float64" {
+			step = 0
+		}
+		lower, upper := math.Inf(-1), math.Inf(1)
+		strict := false
+		for rule, bound := range bounds {
+			switch rule {
+			case "min", "gte":
+				lower = math.Max(lower, bound)
+			case "gt":
+				lower, strict = math.Max(lower, bound+step), true
+			case "max", "lte":
+				upper = math.Min(upper, bound)
+			case "lt":
+				upper, strict = math.Min(upper, bound-step), true
+			case "len":
+				lower, upper = math.Max(lower, bound), math.Min(upper, bound)
+			}
+		}
+		value = math.Min(math.Max(value, lower), upper)
+		switch {
+		case f.Type != "float64":
+			value = math.Ceil(value)
+		case !strict:
+		case !math.IsInf(lower, 0) && !math.IsInf(upper, 0):
+			value = (lower + upper) / 2
+		case value == lower:
+			value++
+		case value == upper:
+			value--
+		}
+	}
+
+	ok := f.Type == "float64" || (value == math.Trunc(value) && (f.Type != "uint" || value >= 0))
+	for rule, bound := range bounds {
+		switch rule {
+		case "min", "gte":
+			ok = ok && value >= bound
+		case "gt":
+			ok = ok && value > bound
+		case "max", "lte":
+			ok = ok && value <= bound
+		case "lt":
+			ok = ok && value < bound
+		case "len":
+			ok = ok && value == bound
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("no %s passes the rules %q", f.Type, f.Rules)
+	}
+	if len(options) > 0 {
+		return options[0], nil
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64), nil
+}
+
+// QuerySample is the Sample of a filtered field as a query string value, for the generated tests.
+func (f field) QuerySample() string {
+	sample := f.Sample()
+	if unquoted, err := strconv.Unquote(sample); err == nil {
+		sample = unquoted
+	}
+	return url.QueryEscape(sample)
+}
+
+// rules splits the validate rules of the spec.
+func (f field) rules() []string {
+	var rules []string
+	for _, rule := range strings.Split(f.Rules, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// hasRule reports whether the validate rules have one of the rules.
+func (f field) hasRule(names ...string) bool {
+	for _, rule := range f.rules() {
+		for _, name := range names {
+			if rule == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bounded reports whether the rules limit the length of a string.
+func (f field) bounded(rules []string) bool {
+	for _, rule := range rules {
+		if strings.HasPrefix(rule, "max=") || strings.HasPrefix(rule, "len=") || strings.HasPrefix(rule, "lte=") ||
+			strings.HasPrefix(rule, "oneof=") || rule == "email" || rule == "uuid" || rule == "uuid4" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestSample(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   field
+		wantErr bool
+	}{
+		{name: "plain string", field: field{Name: "Title", Type: "string"}},
+		{name: "bounded string", field: field{Name: "Title", Type: "string", Size: 10, Rules: "required,min=3"}},
+		{name: "exact length", field: field{Name: "Code", Type: "string", Rules: "len=6"}},
+		{name: "minimum length of a text", field: field{Name: "Body", Type: "text", Rules: "gt=40"}},
+		{name: "alpha", field: field{Name: "FirstName", Type: "string", Rules: "required,alpha"}},
+		{name: "alphanum", field: field{Name: "SKU", Type: "string", Rules: "required,alphanum,max=5"}},
+		{name: "numeric", field: field{Name: "PostalCode", Type: "string", Rules: "required,numeric,len=5"}},
+		{name: "number", field: field{Name: "Pin", Type: "string", Rules: "number,min=4"}},
+		{name: "uppercase", field: field{Name: "Currency", Type: "string", Rules: "uppercase,alpha,len=3"}},
+		{name: "lowercase", field: field{Name: "Slug", Type: "string", Rules: "lowercase,printascii"}},
+		{name: "startswith", field: field{Name: "Reference", Type: "string", Rules: "required,startswith=REF-,max=8"}},
+		{name: "endswith and contains", field: field{Name: "Host", Type: "string", Rules: "contains=.,endswith=.com"}},
+		{name: "email", field: field{Name: "Email", Type: "string", Rules: "required,email"}},
+		{name: "oneof", field: field{Name: "Status", Type: "string", Rules: "oneof=draft published"}},
+		{name: "int in a range", field: field{Name: "Quantity", Type: "int", Rules: "gt=5,lte=10"}},
+		{name: "negative int", field: field{Name: "Offset", Type: "int64", Rules: "lt=-3"}},
+		{name: "float", field: field{Name: "Price", Type: "float64", Rules: "gte=0.5,lt=1"}},
+		{name: "int oneof", field: field{Name: "Rating", Type: "int", Rules: "oneof=3 4 5"}},
+		{name: "unsupported rule", field: field{Name: "Title", Type: "string", Rules: "excludes=x"}, wantErr: true},
+		{name: "string rule on a number", field: field{Name: "Quantity", Type: "int", Rules: "alpha"}, wantErr: true},
+		{name: "bound without a number", field: field{Name: "Title", Type: "string", Rules: "min=x"}, wantErr: true},
+		{name: "prefix longer than the column", field: field{Name: "Code", Type: "string", Size: 3, Rules: "startswith=ABCD"}, wantErr: true},
+		{name: "conflicting classes", field: field{Name: "Code", Type: "string", Rules: "alpha,numeric"}, wantErr: true},
+		{name: "empty int range", field: field{Name: "Quantity", Type: "int", Rules: "gt=1,lt=2"}, wantErr: true},
+		{name: "negative uint", field: field{Name: "Count", Type: "uint", Rules: "max=-1"}, wantErr: true},
+	}
+
+	validate := validator.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.field.check()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got the sample %s", tt.field.Sample())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The sample decodes into the field type and passes its create rules
+			sample := tt.field.Sample()
+			value := reflect.New(reflect.TypeOf(map[string]interface{}{
+				"string": "", "text": "", "int": 0, "int64": int64(0), "uint": uint(0), "float64": 0.0,
+			}[tt.field.Type]))
+			if err := json.Unmarshal([]byte(sample), value.Interface()); err != nil {
+				t.Fatalf("the sample %s does not decode: %v", sample, err)
+			}
+			if err := validate.Var(value.Elem().Interface(), tt.field.CreateRules()); err != nil {
+				t.Errorf("the sample %s fails %q: %v", sample, tt.field.CreateRules(), err)
+			}
+			if query := tt.field.QuerySample(); strings.TrimSpace(query) == "" {
+				t.Errorf("got an empty query sample for %s", sample)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// generator writes the files of the components and registers them in the loaders.
+type generator struct {
+	// force overwrites existing files instead of skipping them
+	force bool
+}
+
+// model creates the model and adds it to models.All.
+func (g *generator) model(e entity) error {
+	if err := g.write(filepath.Join("app", "models", e.Snake+".go"), "model.go.tmpl", e); err != nil {
+		return err
+	}
+	return registerModel(e.names)
+}
+
+// repository creates the repository and registers it in repositories.LoadAll.
+func (g *generator) repository(e entity) error {
+	if err := g.write(filepath.Join("app", "repositories", e.Snake+"_repository.go"), "repository.go.tmpl", e); err != nil {
+		return err
+	}
+	return registerRepository(e.names)
+}
+
+// service creates the service and registers it in services.LoadAll.
+func (g *generator) service(e entity) error {
+	if err := g.write(filepath.Join("app", "services", e.Snake+"_service.go"), "service.go.tmpl", e); err != nil {
+		return err
+	}
+	return registerService(e.names)
+}
+
+// controller creates the controller and its DTOs, wires it in controllers.Initialize and adds its routes.
+func (g *generator) controller(e entity) error {
+	if err := g.write(filepath.Join("app", "http", "dto", e.Snake+"_dto.go"), "dto.go.tmpl", e); err != nil {
+		return err
+	}
+	if err := g.write(filepath.Join("app", "http", "controllers", e.Snake+"_controller.go"), "controller.go.tmpl", e); err != nil {
+		return err
+	}
+	if err := registerController(e.names); err != nil {
+		return err
+	}
+	return registerRoutes(e.names)
+}
+
+// job creates a queue handler, or a scheduled job, and registers it in the jobs loader.
+func (g *generator) job(n names, scheduled bool) error {
+	tmpl := "job.go.tmpl"
+	if scheduled {
+		tmpl = "scheduled_job.go.tmpl"
+	}
+	if err := g.write(filepath.Join("app", "jobs", n.Snake+"_job.go"), tmpl, n); err != nil {
+		return err
+	}
+	return registerJob(n, scheduled)
+}
+
+// resource creates every layer of an entity, from its table to its routes.
+func (g *generator) resource(e entity) error {
+	steps := []func(entity) error{g.model, g.migration, g.repository, g.service, g.controller}
+	for _, step := range steps {
+		if err := step(e); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\nNext: add the %s fields to the model, the migration and the DTOs, then run go run ./cmd/migrate up\n", e.Words)
+	return nil
+}
+
+// write renders a template with data to path; existing files are kept unless force is set.
+// Go files are formatted.
+func (g *generator) write(path, tmpl string, data interface{}) error {
+	if _, err := os.Stat(path); err == nil && !g.force {
+		fmt.Printf("Skipped %s (it exists; -force overwrites it)\n", path)
+		return nil
+	}
+
+	t, err := template.ParseFS(templates, "templates/"+tmpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return fmt.Errorf("Failed to render %s: %w", tmpl, err)
+	}
+	content := buf.Bytes()
+	if strings.HasSuffix(path, ".go") {
+		if content, err = format.Source(content); err != nil {
+			return fmt.Errorf("Failed to format %s: %w", path, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s\n", path)
+	return nil
+}
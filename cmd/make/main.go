@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+const usage = `Usage: go run ./cmd/make <command> [flags] <Name>
+       go run ./cmd/make make:crud [flags] <Name | spec.yaml>
+
+Commands:
+  make:model       Create a model and add it to models.All
+  make:repository  Create a repository and register it in repositories.LoadAll
+  make:service     Create a service and register it in services.LoadAll
+  make:controller  Create a controller with its DTOs, wire it in controllers.Initialize and add its routes
+  make:job         Create a queue job handler (-scheduled for a scheduled job) and register it in the jobs loader
+  make:resource    Create the model, migration, repository, service, controller and routes of an entity
+  make:crud        Create the whole stack of an entity from its fields, read from a YAML spec or an
+                   existing model: migration, model, DTOs with validate rules, cached repository,
+                   service, controller with a filtered and sorted list, routes and tests
+
+Flags (before the name):
+  -force      Overwrite existing files
+  -scheduled  Create a scheduled job instead of a queue handler (make:job)
+  -schedule   Cron expression of a scheduled job (default: "0 * * * *")
+
+Run it from the project root, e.g. go run ./cmd/make make:resource Product
+or go run ./cmd/make make:crud product.yaml
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.Usage = func() { fmt.Print(usage) }
+	var (
+		force     = flags.Bool("force", false, "Overwrite existing files")
+		scheduled = flags.Bool("scheduled", false, "Create a scheduled job instead of a queue handler (make:job)")
+		schedule  = flags.String("schedule", "0 * * * *", "Cron expression of a scheduled job (make:job)")
+	)
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v", err)
+	}
+	if flags.NArg() != 1 {
+		log.Fatalf("Usage: go run ./cmd/make %s [flags] <Name>", command)
+	}
+	if _, err := os.Stat("app"); err != nil {
+		log.Fatal("Run cmd/make from the project root")
+	}
+
+	kind := strings.TrimPrefix(command, "make:")
+	var e entity
+	if kind == "crud" {
+		var err error
+		if e, err = crudEntity(flags.Arg(0)); err != nil {
+			log.Fatalf("Failed to read the entity: %v", err)
+		}
+	} else {
+		n := newNames(flags.Arg(0))
+		if kind == "job" {
+			n = newJobNames(flags.Arg(0), *schedule)
+		}
+		if !n.valid() {
+			log.Fatalf("Invalid name %q: use a name such as Product or OrderItem", flags.Arg(0))
+		}
+		e = entity{names: n}
+	}
+
+	g := &generator{force: *force}
+	var err error
+	switch kind {
+	case "model":
+		err = g.model(e)
+	case "repository":
+		err = g.repository(e)
+	case "service":
+		err = g.service(e)
+	case "controller":
+		err = g.controller(e)
+	case "job":
+		err = g.job(e.names, *scheduled)
+	case "resource":
+		err = g.resource(e)
+	case "crud":
+		err = g.crud(e)
+	default:
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Failed to run %s: %v", command, err)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// migrationsDir holds the SQL migrations; dialect directories hold the other drivers' SQL
+const migrationsDir = "database/migrations"
+
+// migrationVersion matches the version of migration files, SQL and Go
+var migrationVersion = regexp.MustCompile(`^([0-9]+)_`)
+
+// primaryKeys are the id column of a new table, by dialect ("" for PostgreSQL)
+var primaryKeys = map[string]string{
+	"":       "id SERIAL PRIMARY KEY",
+	"mysql":  "id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY",
+	"sqlite": "id INTEGER PRIMARY KEY AUTOINCREMENT",
+}
+
+// timestamps are the created_at and updated_at columns of a new table, by dialect
+var timestamps = map[string][]string{
+	"": {
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	},
+	"mysql": {
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP",
+	},
+	"sqlite": {
+		"created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+		"updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP",
+	},
+}
+
+// migration writes the migration creating the table of the entity, with its field columns
+// and indexes, in the migrations directory and in each of its dialect directories, with the
+// next version. With tenancy, the table lives in every tenant schema, so the migration is
+// also written to the tenant set, which is PostgreSQL only and has versions of its own.
+func (g *generator) migration(e entity) error {
+	name := fmt.Sprintf("create_%s_table", e.Table)
+	dirs := map[string]string{"": migrationsDir}
+	for _, dialect := range []string{"mysql", "sqlite"} {
+		if info, err := os.Stat(filepath.Join(migrationsDir, dialect)); err == nil && info.IsDir() {
+			dirs[dialect] = filepath.Join(migrationsDir, dialect)
+		}
+	}
+	if err := writeCreateTable(e, name, dirs); err != nil {
+		return err
+	}
+
+	tenantDir := filepath.Join(migrationsDir, "tenant")
+	if info, err := os.Stat(tenantDir); err == nil && info.IsDir() {
+		return writeCreateTable(e, name, map[string]string{"": tenantDir})
+	}
+	return nil
+}
+
+// writeCreateTable writes the migration creating the table of the entity in a migration set,
+// given by its directory of each dialect, unless the set already has a migration of that name.
+func writeCreateTable(e entity, name string, dirs map[string]string) error {
+	existing, _ := filepath.Glob(filepath.Join(dirs[""], "*_"+name+".up.sql"))
+	if len(existing) > 0 {
+		fmt.Printf("Skipped the migration: %s exists\n", existing[0])
+		return nil
+	}
+
+	version, err := nextVersion(dirs)
+	if err != nil {
+		return err
+	}
+	for _, dialect := range []string{"", "mysql", "sqlite"} {
+		dir, ok := dirs[dialect]
+		if !ok {
+			continue
+		}
+		up, down := createTable(e, dialect)
+		for _, file := range [][2]string{{"up", up}, {"down", down}} {
+			path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", version, name, file[0]))
+			if err := os.WriteFile(path, []byte(file[1]), 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("Created %s\n", path)
+		}
+	}
+	return nil
+}
+
+// createTable returns the up and down SQL of the table of the entity in a dialect.
+// MySQL declares the indexes in the table; the other dialects create them after it.
+func createTable(e entity, dialect string) (string, string) {
+	columns := []string{primaryKeys[dialect]}
+	for _, f := range e.Fields {
+		columns = append(columns, f.SQL(dialect))
+	}
+	columns = append(columns, timestamps[dialect]...)
+
+	var indexes, drops []string
+	for _, f := range e.Fields {
+		if !f.Indexed() {
+			continue
+		}
+		index := fmt.Sprintf("idx_%s_%s", e.Table, f.Column)
+		switch dialect {
+		case "mysql":
+			columns = append(columns, fmt.Sprintf("INDEX %s (%s)", index, f.Column))
+		case "sqlite":
+			indexes = append(indexes, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s(%s);\n", index, e.Table, f.Column))
+		default:
+			indexes = append(indexes, fmt.Sprintf("CREATE INDEX %s ON %s(%s);\n", index, e.Table, f.Column))
+		}
+		if dialect != "mysql" {
+			drops = append(drops, fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", index))
+		}
+	}
+
+	up := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n    %s\n);\n", e.Table, strings.Join(columns, ",\n    "))
+	if len(indexes) > 0 {
+		up += "\n" + strings.Join(indexes, "")
+	}
+	down := strings.Join(drops, "") + fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", e.Table)
+	return up, down
+}
+
+// nextVersion returns the version following the highest one in any of the directories.
+func nextVersion(dirs map[string]string) (uint64, error) {
+	var highest uint64
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			m := migrationVersion.FindStringSubmatch(entry.Name())
+			if m == nil {
+				continue
+			}
+			if version, err := strconv.ParseUint(m[1], 10, 64); err == nil && version > highest {
+				highest = version
+			}
+		}
+	}
+	return highest + 1, nil
+}
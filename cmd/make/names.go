@@ -0,0 +1,89 @@
+package main
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm/schema"
+)
+
+// nameWords matches the words of a name given as Product, OrderItem, order_item or order-item
+var nameWords = regexp.MustCompile(`[A-Za-z0-9]+`)
+
+// names are the spellings of an entity name used in the generated code, e.g. for OrderItem
+type names struct {
+	Name        string // OrderItem
+	Var         string // orderItem
+	PluralVar   string // orderItems
+	Plural      string // OrderItems
+	Snake       string // order_item, for file names and log keys
+	Table       string // order_items
+	Route       string // order-items
+	Kebab       string // order-item, for job names
+	Words       string // order item
+	A           string // an, the article of Words
+	PluralWords string // order items
+	Title       string // Order item
+	// Schedule is the cron expression of a scheduled job
+	Schedule string
+}
+
+// newNames derives the spellings from an entity name. Table names follow
+// GORM's naming strategy, so they match the tables GORM expects.
+func newNames(name string) names {
+	var n names
+	for _, word := range nameWords.FindAllString(name, -1) {
+		n.Name += strings.ToUpper(word[:1]) + word[1:]
+	}
+	if n.Name == "" {
+		return n
+	}
+
+	naming := schema.NamingStrategy{}
+	n.Snake = naming.ColumnName("", n.Name)
+	n.Table = naming.TableName(n.Name)
+	n.Var = lowerCamel(n.Snake)
+	n.PluralVar = lowerCamel(n.Table)
+	n.Plural = strings.ToUpper(n.PluralVar[:1]) + n.PluralVar[1:]
+	n.Route = strings.ReplaceAll(n.Table, "_", "-")
+	n.Kebab = strings.ReplaceAll(n.Snake, "_", "-")
+	n.Words = strings.ReplaceAll(n.Snake, "_", " ")
+	n.PluralWords = strings.ReplaceAll(n.Table, "_", " ")
+	n.Title = strings.ToUpper(n.Words[:1]) + n.Words[1:]
+	n.A = "a"
+	if strings.ContainsAny(n.Words[:1], "aeiou") {
+		n.A = "an"
+	}
+	return n
+}
+
+// newJobNames derives the spellings of a job, without a Job or Handler suffix.
+func newJobNames(name, schedule string) names {
+	n := newNames(name)
+	for _, suffix := range []string{"Job", "Handler"} {
+		if trimmed := strings.TrimSuffix(n.Name, suffix); trimmed != "" && trimmed != n.Name {
+			n = newNames(trimmed)
+			break
+		}
+	}
+	n.Schedule = schedule
+	return n
+}
+
+// valid reports whether the name makes Go identifiers; its variable name can't be a keyword.
+func (n names) valid() bool {
+	return n.Name != "" && unicode.IsLetter(rune(n.Name[0])) && !token.IsKeyword(n.Var)
+}
+
+// lowerCamel turns a snake_case name into lowerCamelCase.
+func lowerCamel(snake string) string {
+	parts := strings.Split(snake, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
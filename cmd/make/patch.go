@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// insertion is text to insert at a byte offset of a file
+type insertion struct {
+	offset int
+	text   string
+}
+
+// editFunc finds where a file needs new code; no insertions means it is registered already
+type editFunc func(file *ast.File, offset func(token.Pos) int, src []byte) ([]insertion, error)
+
+// patchFile parses a Go file, inserts the code returned by edit at the positions found in
+// its syntax tree, and formats the result. Only the inserted text changes: the rest of the
+// file, comments included, is kept as written.
+func patchFile(path string, edit editFunc) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	insertions, err := edit(file, func(pos token.Pos) int { return fset.Position(pos).Offset }, src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(insertions) == 0 {
+		fmt.Printf("Skipped %s (already registered)\n", path)
+		return nil
+	}
+
+	// Insert from the end, so earlier offsets stay valid
+	sort.Slice(insertions, func(i, j int) bool { return insertions[i].offset > insertions[j].offset })
+	for _, ins := range insertions {
+		src = append(src[:ins.offset], append([]byte(ins.text), src[ins.offset:]...)...)
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("%s: the patched file does not parse: %w", path, err)
+	}
+	if err := os.WriteFile(path, formatted, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s\n", path)
+	return nil
+}
+
+// registerModel adds the model to the list returned by models.All.
+func registerModel(n names) error {
+	return patchFile(filepath.Join("app", "models", "models.go"), func(file *ast.File, offset func(token.Pos) int, src []byte) ([]insertion, error) {
+		if hasIdent(file, n.Name) {
+			return nil, nil
+		}
+		fn := findFunc(file, "All")
+		if fn == nil {
+			return nil, fmt.Errorf("func All not found")
+		}
+		lit := returnedLiteral(fn)
+		if lit == nil {
+			return nil, fmt.Errorf("All does not return a slice literal")
+		}
+		return []insertion{appendElement(lit, fmt.Sprintf("&%s{}", n.Name), offset, src)}, nil
+	})
+}
+
+// registerRepository registers the repository in repositories.LoadAll, behind the cache
+// decorator, which config/repository.yaml enables.
+func registerRepository(n names) error {
+	code := fmt.Sprintf(`
+	registry.Register(repository.Cached(repository.NewBaseRepository(%q, func(app foundation.Application) (interface{}, error) {
+		return New%sRepository(db), nil
+	}), config, func(%s *models.%s) uint { return %s.ID }))`, n.Var+"Repository", n.Name, n.Var, n.Name, n.Var)
+	return patchFile(filepath.Join("app", "repositories", "loader.go"), afterRegistrations("LoadAll", n.Var+"Repository", code))
+}
+
+// registerService registers the service in services.LoadAll.
+func registerService(n names) error {
+	code := fmt.Sprintf(`
+
+	// Register %s Service
+	registry.Register(service.NewBaseService(%q, func(app foundation.Application) (interface{}, error) {
+		%sRepo := repositories.MustResolve%sRepository(app)
+
+		return New%sService(%sRepo), nil
+	}))`, heading(n.Words), n.Var+"Service", n.Var, n.Name, n.Name, n.Var)
+	return patchFile(filepath.Join("app", "services", "loader.go"), afterRegistrations("LoadAll", n.Var+"Service", code))
+}
+
+// registerJob registers a queue handler in jobs.LoadQueueHandlers, or a scheduled job in jobs.LoadAll.
+func registerJob(n names, scheduled bool) error {
+	fn, code := "LoadQueueHandlers", fmt.Sprintf("\n\tregistry.Register(New%sHandler())", n.Name)
+	if scheduled {
+		fn, code = "LoadAll", fmt.Sprintf("\n\tregistry.Register(New%sJob(logger))", n.Name)
+	}
+	return patchFile(filepath.Join("app", "jobs", "loader.go"), func(file *ast.File, offset func(token.Pos) int, src []byte) ([]insertion, error) {
+		if hasIdent(file, "New"+n.Name+"Handler") || hasIdent(file, "New"+n.Name+"Job") {
+			return nil, nil
+		}
+		return insertAfterRegistrations(file, fn, code, offset)
+	})
+}
+
+// registerController adds the controller to the Controllers struct and creates it in Initialize.
+func registerController(n names) error {
+	return patchFile(filepath.Join("app", "http", "controllers", "init.go"), func(file *ast.File, offset func(token.Pos) int, src []byte) ([]insertion, error) {
+		var fields *ast.FieldList
+		ast.Inspect(file, func(node ast.Node) bool {
+			if spec, ok := node.(*ast.TypeSpec); ok && spec.Name.Name == "Controllers" {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					fields = st.Fields
+				}
+			}
+			return fields == nil
+		})
+		if fields == nil {
+			return nil, fmt.Errorf("type Controllers not found")
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				if name.Name == n.Name {
+					return nil, nil
+				}
+			}
+		}
+
+		fn := findFunc(file, "Initialize")
+		if fn == nil {
+			return nil, fmt.Errorf("func Initialize not found")
+		}
+		lit := returnedLiteral(fn)
+		if lit == nil {
+			return nil, fmt.Errorf("Initialize does not return a Controllers literal")
+		}
+		resolve, err := insertBeforeReturn(file, "Initialize", fmt.Sprintf("%sService := services.MustResolve%sService(app)\n", n.Var, n.Name), offset, src)
+		if err != nil {
+			return nil, err
+		}
+
+		field := fmt.Sprintf("%s *%sController\n", n.Name, n.Name)
+		return append(resolve,
+			insertion{offset(fields.Closing), lineBreak(src, offset(fields.Closing)) + field},
+			appendElement(lit, fmt.Sprintf("%s: New%sController(%sService, validator)", n.Name, n.Name, n.Var), offset, src),
+		), nil
+	})
+}
+
+// registerRoutes adds the CRUD routes of the controller to the api group of routes.Register,
+// after the last api route.
+func registerRoutes(n names) error {
+	return patchFile(filepath.Join("app", "http", "routes", "web.go"), func(file *ast.File, offset func(token.Pos) int, src []byte) ([]insertion, error) {
+		if bytes.Contains(src, []byte("ctrl."+n.Name+".")) {
+			return nil, nil
+		}
+		fn := findFunc(file, "Register")
+		if fn == nil {
+			return nil, fmt.Errorf("func Register not found")
+		}
+
+		// The api group is the block following api := v1.Group(...)
+		var block *ast.BlockStmt
+		for i, stmt := range fn.Body.List {
+			assign, ok := stmt.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != 1 || !isIdent(assign.Lhs[0], "api") || i+1 == len(fn.Body.List) {
+				continue
+			}
+			block, _ = fn.Body.List[i+1].(*ast.BlockStmt)
+		}
+		if block == nil {
+			return nil, fmt.Errorf("the api route group block was not found")
+		}
+
+		at := offset(block.Rbrace)
+		for _, stmt := range block.List {
+			if call, ok := routeCall(stmt); ok && isIdent(call.X, "api") {
+				at = offset(stmt.End())
+			}
+		}
+		routes := fmt.Sprintf(`
+
+		// %s routes
+		api.POST("/%s", ctrl.%s.Create)
+		api.GET("/%s", ctrl.%s.List)
+		api.GET("/%s/:id", ctrl.%s.Get)
+		api.PUT("/%s/:id", ctrl.%s.Update)
+		api.DELETE("/%s/:id", ctrl.%s.Delete)`,
+			n.Title, n.Route, n.Name, n.Route, n.Name, n.Route, n.Name, n.Route, n.Name, n.Route, n.Name)
+		if at == offset(block.Rbrace) {
+			routes += "\n"
+		}
+		return []insertion{{at, routes}}, nil
+	})
+}
+
+// afterRegistrations returns an edit inserting code after the registry.Register calls of a
+// function, unless the file already has the binding name.
+func afterRegistrations(fn, binding, code string) editFunc {
+	return func(file *ast.File, offset func(token.Pos) int, src []byte) ([]insertion, error) {
+		if hasString(file, binding) {
+			return nil, nil
+		}
+		return insertAfterRegistrations(file, fn, code, offset)
+	}
+}
+
+// insertAfterRegistrations inserts code after the last registry.Register call of a function.
+func insertAfterRegistrations(file *ast.File, name, code string, offset func(token.Pos) int) ([]insertion, error) {
+	fn := findFunc(file, name)
+	if fn == nil {
+		return nil, fmt.Errorf("func %s not found", name)
+	}
+	var last ast.Stmt
+	for _, stmt := range fn.Body.List {
+		if call, ok := routeCall(stmt); ok && isIdent(call.X, "registry") && call.Sel.Name == "Register" {
+			last = stmt
+		}
+	}
+	if last == nil {
+		return nil, fmt.Errorf("func %s has no registry.Register call", name)
+	}
+	return []insertion{{offset(last.End()), code}}, nil
+}
+
+// insertBeforeReturn inserts code on its own line before the last return statement of a function,
+// above the comment and blank lines preceding it.
+func insertBeforeReturn(file *ast.File, name, code string, offset func(token.Pos) int, src []byte) ([]insertion, error) {
+	fn := findFunc(file, name)
+	if fn == nil {
+		return nil, fmt.Errorf("func %s not found", name)
+	}
+	var ret *ast.ReturnStmt
+	for _, stmt := range fn.Body.List {
+		if r, ok := stmt.(*ast.ReturnStmt); ok {
+			ret = r
+		}
+	}
+	if ret == nil {
+		return nil, fmt.Errorf("func %s has no return statement", name)
+	}
+
+	at := offset(ret.Pos())
+	for _, group := range file.Comments {
+		end := offset(group.End())
+		if end <= at && bytes.Count(src[end:at], []byte("\n")) == 1 && len(bytes.TrimSpace(src[end:at])) == 0 {
+			at = offset(group.Pos())
+		}
+	}
+	// Start of the line, before the blank lines
+	at = bytes.LastIndexByte(src[:at], '\n') + 1
+	for at > 1 && src[at-2] == '\n' {
+		at--
+	}
+	return []insertion{{at, code}}, nil
+}
+
+// appendElement adds an element at the end of a composite literal.
+func appendElement(lit *ast.CompositeLit, element string, offset func(token.Pos) int, src []byte) insertion {
+	at := offset(lit.Rbrace)
+	if n := len(lit.Elts); n > 0 && !bytes.Contains(src[offset(lit.Elts[n-1].End()):at], []byte(",")) {
+		element = ",\n" + element
+	}
+	return insertion{at, lineBreak(src, at) + element + ",\n"}
+}
+
+// lineBreak returns the newline needed for text inserted at offset to start its own line.
+func lineBreak(src []byte, offset int) string {
+	line := src[bytes.LastIndexByte(src[:offset], '\n')+1 : offset]
+	if len(bytes.TrimSpace(line)) > 0 {
+		return "\n"
+	}
+	return ""
+}
+
+// findFunc returns the top-level function with the name.
+func findFunc(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name && fn.Body != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// returnedLiteral returns the composite literal (&T{...} or []T{...}) returned by a function.
+func returnedLiteral(fn *ast.FuncDecl) *ast.CompositeLit {
+	var lit *ast.CompositeLit
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		expr := ret.Results[0]
+		if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			expr = unary.X
+		}
+		if l, ok := expr.(*ast.CompositeLit); ok {
+			lit = l
+		}
+	}
+	return lit
+}
+
+// heading capitalizes every word, e.g. Order Item.
+func heading(words string) string {
+	parts := strings.Fields(words)
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, " ")
+}
+
+// routeCall returns the selector of a statement like api.GET(...).
+func routeCall(stmt ast.Stmt) (*ast.SelectorExpr, bool) {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil, false
+	}
+	call, ok := expr.X.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return sel, ok
+}
+
+// isIdent reports whether expr is the identifier name.
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// hasIdent reports whether the file uses the identifier.
+func hasIdent(file *ast.File, name string) bool {
+	found := false
+	ast.Inspect(file, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// hasString reports whether the file has the string literal, e.g. a container binding name.
+func hasString(file *ast.File, value string) bool {
+	found := false
+	ast.Inspect(file, func(node ast.Node) bool {
+		if lit, ok := node.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if s, err := strconv.Unquote(lit.Value); err == nil && s == value {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
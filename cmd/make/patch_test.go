@@ -0,0 +1,181 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// copyLoader copies a file of the application into the same path under dir
+func copyLoader(t *testing.T, dir, path string) {
+	t.Helper()
+	src, err := os.ReadFile(filepath.Join("..", "..", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, path), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPatchLoaders(t *testing.T) {
+	n := newNames("OrderItem")
+
+	tests := []struct {
+		name     string
+		path     string
+		register func() error
+		// want are the lines the patch must add
+		want []string
+	}{
+		{
+			name:     "model",
+			path:     "app/models/models.go",
+			register: func() error { return registerModel(n) },
+			want:     []string{"&OrderItem{},"},
+		},
+		{
+			name:     "repository",
+			path:     "app/repositories/loader.go",
+			register: func() error { return registerRepository(n) },
+			want:     []string{`repository.NewBaseRepository("orderItemRepository"`, "return NewOrderItemRepository(db), nil"},
+		},
+		{
+			name:     "service",
+			path:     "app/services/loader.go",
+			register: func() error { return registerService(n) },
+			want:     []string{"// Register Order Item Service", `service.NewBaseService("orderItemService"`, "orderItemRepo := repositories.MustResolveOrderItemRepository(app)"},
+		},
+		{
+			name:     "queue handler",
+			path:     "app/jobs/loader.go",
+			register: func() error { return registerJob(n, false) },
+			want:     []string{"registry.Register(NewOrderItemHandler())"},
+		},
+		{
+			name:     "scheduled job",
+			path:     "app/jobs/loader.go",
+			register: func() error { return registerJob(n, true) },
+			want:     []string{"registry.Register(NewOrderItemJob(logger))"},
+		},
+		{
+			name:     "controller",
+			path:     "app/http/controllers/init.go",
+			register: func() error { return registerController(n) },
+			want: []string{
+				"OrderItem *OrderItemController",
+				"orderItemService := services.MustResolveOrderItemService(app)",
+				"OrderItem: NewOrderItemController(orderItemService, validator),",
+			},
+		},
+		{
+			name:     "routes",
+			path:     "app/http/routes/web.go",
+			register: func() error { return registerRoutes(n) },
+			want: []string{
+				"// Order item routes",
+				`api.POST("/order-items", ctrl.OrderItem.Create)`,
+				`api.DELETE("/order-items/:id", ctrl.OrderItem.Delete)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			copyLoader(t, dir, tt.path)
+			original, err := os.ReadFile(filepath.Join(dir, tt.path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(dir)
+
+			if err := tt.register(); err != nil {
+				t.Fatal(err)
+			}
+			patched, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), tt.path, patched, parser.ParseComments); err != nil {
+				t.Fatalf("the patched file does not parse: %v", err)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(string(patched), line) {
+					t.Errorf("missing %q in:\n%s", line, patched)
+				}
+			}
+
+			// Every original line is kept, in order; gofmt may only realign them
+			rest := strings.Join(strings.Fields(string(patched)), " ")
+			for _, line := range strings.Split(string(original), "\n") {
+				line = strings.Join(strings.Fields(line), " ")
+				i := strings.Index(rest, line)
+				if i < 0 {
+					t.Fatalf("original line %q was lost or moved", line)
+				}
+				rest = rest[i+len(line):]
+			}
+
+			// Registering again changes nothing
+			if err := tt.register(); err != nil {
+				t.Fatal(err)
+			}
+			again, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(patched) {
+				t.Errorf("a second run changed the file:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestPatchFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		// src is the content of app/models/models.go
+		src string
+	}{
+		{
+			name: "missing function",
+			src:  "package models\n\nfunc Other() []interface{} { return nil }\n",
+		},
+		{
+			name: "function not returning a literal",
+			src:  "package models\n\nvar all []interface{}\n\nfunc All() []interface{} { return all }\n",
+		},
+		{
+			name: "file that does not parse",
+			src:  "package models\n\nfunc All() []interface{} {\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app", "models", "models.go")
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			t.Chdir(dir)
+
+			if err := registerModel(newNames("Product")); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got, _ := os.ReadFile(path); string(got) != tt.src {
+				t.Errorf("a failed patch changed the file:\n%s", got)
+			}
+		})
+	}
+}
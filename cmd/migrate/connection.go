@@ -0,0 +1,206 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/donnigundala/dg-core/config"
+	dgdb "github.com/donnigundala/dg-database"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// connectionConfig is the database a connection's migrations run on
+type connectionConfig struct {
+	// name is empty for the default connection
+	name     string
+	driver   string
+	host     string
+	port     int
+	database string
+	username string
+	password string
+	schema   string
+	filePath string
+}
+
+// loadConnection reads the default connection (name "") from the db keys, or a named one
+// from db.connections. Replicas are never used: when a connection has a master, its
+// settings take precedence, so migrations always run on the primary.
+func loadConnection(name string) (connectionConfig, error) {
+	prefix := "db"
+	if name != "" {
+		prefix = "db.connections." + name
+		if config.Get(prefix) == nil {
+			return connectionConfig{}, fmt.Errorf("unknown connection %q (see db.connections in config/database.yaml)", name)
+		}
+	}
+
+	scopes := []string{prefix}
+	if config.Get(prefix+".master") != nil {
+		scopes = []string{prefix + ".master", prefix}
+	}
+
+	cfg := connectionConfig{
+		name:     name,
+		driver:   lookup(scopes, "driver"),
+		host:     lookup(scopes, "host"),
+		port:     lookupInt(scopes, "port"),
+		database: lookup(scopes, "name", "database"),
+		username: lookup(scopes, "username"),
+		password: lookup(scopes, "password"),
+		schema:   lookup(scopes, "schema"),
+		filePath: lookup(scopes, "file_path"),
+	}
+	// Named connections default to the driver of the default one
+	if cfg.driver == "" {
+		cfg.driver = config.GetString("db.driver")
+	}
+	if cfg.port == 0 {
+		cfg.port = 5432
+		if cfg.driver == "mysql" {
+			cfg.port = 3306
+		}
+	}
+	return cfg, nil
+}
+
+// connectionNames returns the names of db.connections, sorted.
+func connectionNames() []string {
+	connections, _ := config.Get("db.connections").(map[string]interface{})
+	names := make([]string, 0, len(connections))
+	for name := range connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func connectionLabel(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+// lookup returns the first non-empty value of the keys in the scopes, in order.
+func lookup(scopes []string, keys ...string) string {
+	for _, scope := range scopes {
+		for _, key := range keys {
+			if value := config.GetString(scope + "." + key); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+func lookupInt(scopes []string, key string) int {
+	for _, scope := range scopes {
+		switch v := config.Get(scope + "." + key).(type) {
+		case int:
+			return v
+		case int64:
+			return int(v)
+		case float64:
+			return int(v)
+		case string:
+			if n, err := strconv.Atoi(v); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// connection is an open database of a connectionConfig
+type connection struct {
+	config  connectionConfig
+	sqlDB   *sql.DB
+	manager *dgdb.Manager
+	gorm    *gorm.DB
+}
+
+// openConnection connects to the database; SQLite files are opened directly.
+func openConnection(cfg connectionConfig) (*connection, error) {
+	if cfg.driver == "sqlite" {
+		sqlDB, err := openSQLite(cfg.filePath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open SQLite database: %w", err)
+		}
+		return &connection{config: cfg, sqlDB: sqlDB}, nil
+	}
+
+	dbConfig := dgdb.DefaultConfig().
+		WithDriver(cfg.driver).
+		WithHost(cfg.host).
+		WithPort(cfg.port).
+		WithDatabase(cfg.database).
+		WithCredentials(cfg.username, cfg.password)
+
+	// Add schema if specified (PostgreSQL)
+	if cfg.schema != "" {
+		dbConfig = dbConfig.WithSchema(cfg.schema)
+	}
+
+	manager, err := dgdb.NewManager(dbConfig, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to database: %w", err)
+	}
+	sqlDB, err := manager.SQL()
+	if err != nil {
+		manager.Close()
+		return nil, fmt.Errorf("Failed to get SQL database: %w", err)
+	}
+	return &connection{config: cfg, sqlDB: sqlDB, manager: manager, gorm: manager.DB()}, nil
+}
+
+// dbName is the database name migrate records, the file path for SQLite.
+func (c *connection) dbName() string {
+	if c.config.driver == "sqlite" {
+		return c.config.filePath
+	}
+	return c.config.database
+}
+
+// orm returns GORM on the connection, opening it on the SQLite file when needed.
+func (c *connection) orm() *gorm.DB {
+	if c.gorm == nil {
+		db, err := gorm.Open(gormsqlite.New(gormsqlite.Config{Conn: c.sqlDB}), &gorm.Config{})
+		if err != nil {
+			panic("failed to open SQLite database: " + err.Error())
+		}
+		c.gorm = db
+	}
+	return c.gorm
+}
+
+// Close closes the database.
+func (c *connection) Close() error {
+	if c.manager != nil {
+		return c.manager.Close()
+	}
+	return c.sqlDB.Close()
+}
+
+// openSQLite opens (and creates, if needed) the SQLite database file.
+func openSQLite(path string) (*sql.DB, error) {
+	if path == "" {
+		path = "./storage/database.db"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only supports one writer
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
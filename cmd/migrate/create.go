@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// nonWord matches the characters replaced by underscores in migration names
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// goFile matches the file names of Go migrations, e.g. 000004_backfill_usernames.go
+var goFile = regexp.MustCompile(`^([0-9]+)_[a-z0-9_]+\.go$`)
+
+// goTemplate is the file of a new Go migration
+const goTemplate = `package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+func init() {
+	Register(Migration{
+		Version: %d,
+		Name: %q,%s
+		Up: func(ctx context.Context, tx *sql.Tx) error {
+			// Placeholders differ by driver: $1 on postgres, ? on mysql and sqlite (see Driver)
+			return nil
+		},
+		Down: func(ctx context.Context, tx *sql.Tx) error {
+			return nil
+		},
+	})
+}
+`
+
+// migrationFiles are the up and down files of a new migration in one directory
+type migrationFiles struct {
+	// dialect is the driver of a dialect directory, empty for the top-level directory
+	dialect  string
+	up, down string
+}
+
+// createMigration writes empty up and down files for a new migration of a connection
+// ("" for the default one) under root, in its directory and in each of its dialect
+// directories, so no dialect misses the version.
+func createMigration(root, connection, name string, timestamp bool) error {
+	files, err := planMigration(root, connection, name, timestamp)
+	if err != nil {
+		return err
+	}
+	return writeMigration(root, files, func(migrationFiles) ([]byte, []byte) { return nil, nil })
+}
+
+// planMigration returns the files of a new migration without creating them.
+func planMigration(root, connection, name string, timestamp bool) ([]migrationFiles, error) {
+	name, err := cleanName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := migrationDirs(filepath.Join(root, connection))
+	version, err := newVersion(root, dirs, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []migrationFiles
+	for _, dialect := range append([]string{""}, dialects...) {
+		d, ok := dirs[dialect]
+		if !ok {
+			continue
+		}
+		files = append(files, migrationFiles{
+			dialect: dialect,
+			up:      filepath.Join(d, fmt.Sprintf("%s_%s.up.sql", version, name)),
+			down:    filepath.Join(d, fmt.Sprintf("%s_%s.down.sql", version, name)),
+		})
+	}
+	for _, f := range files {
+		for _, file := range []string{f.up, f.down} {
+			if _, err := os.Stat(file); err == nil {
+				return nil, fmt.Errorf("%s already exists", file)
+			}
+		}
+	}
+	return files, nil
+}
+
+// createGoMigration writes a Go migration of a connection ("" for the default one) to root,
+// the migrations package, with the next version of the connection's migrations.
+func createGoMigration(root, connection, name string, timestamp bool) error {
+	name, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+	version, err := newVersion(root, migrationDirs(filepath.Join(root, connection)), timestamp)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(root, fmt.Sprintf("%s_%s.go", version, name))
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("%s already exists", file)
+	}
+
+	number, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return err
+	}
+	var connectionField string
+	if connection != "" {
+		connectionField = fmt.Sprintf("\n\t\tConnection: %q,", connection)
+	}
+	code, err := format.Source([]byte(fmt.Sprintf(goTemplate, number, name, connectionField)))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, code, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s\n", file)
+	return nil
+}
+
+// goVersion returns the version in the file name of a Go migration.
+func goVersion(name string) (uint, bool) {
+	match := goFile.FindStringSubmatch(name)
+	if match == nil {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(match[1], 10, 64)
+	return uint(version), err == nil
+}
+
+// cleanName turns a migration name into the lowercase words of its file names.
+func cleanName(name string) (string, error) {
+	name = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", fmt.Errorf("invalid migration name")
+	}
+	return name, nil
+}
+
+// migrationDirs returns dir and its dialect directories, keyed by dialect ("" for dir).
+func migrationDirs(dir string) map[string]string {
+	dirs := map[string]string{"": dir}
+	for _, dialect := range dialects {
+		if info, err := os.Stat(filepath.Join(dir, dialect)); err == nil && info.IsDir() {
+			dirs[dialect] = filepath.Join(dir, dialect)
+		}
+	}
+	return dirs
+}
+
+// newVersion returns the version of a new migration: the UTC time, or the number following
+// the highest version in the directories and in the Go migrations of root.
+func newVersion(root string, dirs map[string]string, timestamp bool) (string, error) {
+	if timestamp {
+		return time.Now().UTC().Format("20060102150405"), nil
+	}
+	next, err := nextVersion(append(slices.Collect(maps.Values(dirs)), root))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", next), nil
+}
+
+// writeMigration creates the files with the contents returned for each directory, and
+// adds the directories to the go:embed directive of the migrations package in root.
+func writeMigration(root string, files []migrationFiles, contents func(migrationFiles) (up, down []byte)) error {
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.up), 0o755); err != nil {
+			return err
+		}
+		up, down := contents(f)
+		if err := os.WriteFile(f.up, up, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", f.up)
+		if err := os.WriteFile(f.down, down, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", f.down)
+	}
+	return embedMigrations(root, files)
+}
+
+// embedDirective starts the line listing the embedded migration files in embed.go
+const embedDirective = "//go:embed "
+
+// embedMigrations adds the SQL files of the directories of new migrations to the go:embed
+// directive of root/embed.go, so the binaries embed them. Without embed.go, as for migrations
+// read from another path, it does nothing.
+func embedMigrations(root string, files []migrationFiles) error {
+	file := filepath.Join(root, "embed.go")
+	src, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(src), "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, embedDirective) {
+			continue
+		}
+		patterns := strings.Fields(strings.TrimPrefix(line, embedDirective))
+		added := false
+		for _, f := range files {
+			dir, err := filepath.Rel(root, filepath.Dir(f.up))
+			if err != nil {
+				return err
+			}
+			pattern := filepath.ToSlash(filepath.Join(dir, "*.sql"))
+			if !slices.Contains(patterns, pattern) {
+				patterns = append(patterns, pattern)
+				added = true
+			}
+		}
+		if !added {
+			return nil
+		}
+		lines[i] = embedDirective + strings.Join(patterns, " ")
+		if err := os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Updated %s\n", file)
+		return nil
+	}
+	return fmt.Errorf("%s has no go:embed directive", file)
+}
+
+// nextVersion returns the version following the highest one in any of the directories.
+func nextVersion(dirs []string) (uint, error) {
+	var highest uint
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			if m, err := source.DefaultParse(entry.Name()); err == nil && m.Version > highest {
+				highest = m.Version
+			}
+			if version, ok := goVersion(entry.Name()); ok && version > highest {
+				highest = version
+			}
+		}
+	}
+	return highest + 1, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmbedMigrations(t *testing.T) {
+	root := t.TempDir()
+	embed := filepath.Join(root, "embed.go")
+	src := "package migrations\n\nimport \"embed\"\n\n//go:embed *.sql mysql/*.sql\nvar FS embed.FS\n"
+	if err := os.WriteFile(embed, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := []migrationFiles{
+		{up: filepath.Join(root, "mysql", "000002_a.up.sql")},
+		{up: filepath.Join(root, "analytics", "000001_a.up.sql")},
+		{up: filepath.Join(root, "analytics", "sqlite", "000001_a.up.sql")},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := embedMigrations(root, files); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(embed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "package migrations\n\nimport \"embed\"\n\n//go:embed *.sql mysql/*.sql analytics/*.sql analytics/sqlite/*.sql\nvar FS embed.FS\n"
+		if string(got) != want {
+			t.Fatalf("run %d: got\n%s\nwant\n%s", i+1, got, want)
+		}
+	}
+
+	// Migrations outside the package are left alone
+	if err := embedMigrations(t.TempDir(), files); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"skeleton/app/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// difference is one way a table differs from its model, with the draft statements fixing it
+type difference struct {
+	table   string
+	message string
+	up      []string
+	down    []string
+}
+
+// columnSpec describes a column as declared by a model or found in the database
+type columnSpec struct {
+	name     string
+	sqlType  string
+	nullable bool
+	// full is the type with its NOT NULL and DEFAULT clauses
+	full string
+}
+
+// indexSpec describes an index as declared by a model or found in the database
+type indexSpec struct {
+	name    string
+	columns []string
+	unique  bool
+}
+
+// diffSchema compares the tables of every model in models.All with the database.
+func diffSchema(orm *gorm.DB, driverName string) ([]difference, error) {
+	d := drafter{orm: orm, driver: driverName}
+	migrator := orm.Migrator()
+
+	var diffs []difference
+	for _, model := range models.All() {
+		stmt := &gorm.Statement{DB: orm}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("Failed to parse model %T: %w", model, err)
+		}
+
+		if !migrator.HasTable(model) {
+			diffs = append(diffs, d.missingTable(stmt.Schema))
+			continue
+		}
+
+		columns, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read columns of %s: %w", stmt.Schema.Table, err)
+		}
+		indexes, err := migrator.GetIndexes(model)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read indexes of %s: %w", stmt.Schema.Table, err)
+		}
+
+		diffs = append(diffs, d.compareColumns(stmt.Schema, columns)...)
+		diffs = append(diffs, d.compareIndexes(stmt.Schema, indexes)...)
+	}
+	return diffs, nil
+}
+
+// compareColumns reports missing and extra columns, and type and nullability differences.
+func (d drafter) compareColumns(sch *schema.Schema, columns []gorm.ColumnType) []difference {
+	table := sch.Table
+	live := make(map[string]gorm.ColumnType, len(columns))
+	for _, column := range columns {
+		live[strings.ToLower(column.Name())] = column
+	}
+
+	var diffs []difference
+	for _, field := range migratedFields(sch) {
+		want := d.modelColumn(field)
+		column, ok := live[strings.ToLower(field.DBName)]
+		if !ok {
+			diffs = append(diffs, difference{
+				table:   table,
+				message: fmt.Sprintf("missing column %s (%s)", want.name, want.full),
+				up:      []string{d.addColumn(table, want)},
+				down:    []string{d.dropColumn(table, want.name)},
+			})
+			continue
+		}
+		delete(live, strings.ToLower(field.DBName))
+
+		have := d.databaseColumn(column)
+		var problems []string
+		if typeDiffers(field, column) {
+			problems = append(problems, fmt.Sprintf("type is %s in the database, %s in the model", have.sqlType, want.sqlType))
+		}
+		if have.nullable != want.nullable {
+			problems = append(problems, fmt.Sprintf("%s in the database, %s in the model", nullability(have.nullable), nullability(want.nullable)))
+		}
+		if len(problems) > 0 {
+			diffs = append(diffs, difference{
+				table:   table,
+				message: fmt.Sprintf("column %s: %s", want.name, strings.Join(problems, "; ")),
+				up:      d.alterColumn(table, have, want),
+				down:    d.alterColumn(table, want, have),
+			})
+		}
+	}
+
+	// Extra columns, in table order
+	for _, column := range columns {
+		if _, ok := live[strings.ToLower(column.Name())]; !ok {
+			continue
+		}
+		have := d.databaseColumn(column)
+		diffs = append(diffs, difference{
+			table:   table,
+			message: fmt.Sprintf("extra column %s (%s) is not declared in the model", have.name, have.full),
+			up:      []string{d.dropColumn(table, have.name)},
+			down:    []string{d.addColumn(table, have)},
+		})
+	}
+	return diffs
+}
+
+// compareIndexes reports missing and extra indexes, and uniqueness differences.
+// Indexes are matched on their columns, so differently named but equivalent indexes
+// (e.g. a UNIQUE constraint for a uniqueIndex tag) are not reported.
+func (d drafter) compareIndexes(sch *schema.Schema, indexes []gorm.Index) []difference {
+	table := sch.Table
+	var live []indexSpec
+	for _, index := range indexes {
+		if primary, ok := index.PrimaryKey(); ok && primary {
+			continue
+		}
+		unique, _ := index.Unique()
+		live = append(live, indexSpec{name: index.Name(), columns: index.Columns(), unique: unique})
+	}
+	declared := modelIndexes(sch)
+
+	var diffs []difference
+	for _, want := range declared {
+		var same, other *indexSpec
+		for i := range live {
+			if slices.Equal(live[i].columns, want.columns) {
+				if live[i].unique == want.unique {
+					same = &live[i]
+				} else if other == nil {
+					other = &live[i]
+				}
+			}
+		}
+
+		switch {
+		case same != nil:
+		case other != nil:
+			diffs = append(diffs, difference{
+				table:   table,
+				message: fmt.Sprintf("index %s on (%s) is %s in the database, %s in the model", other.name, strings.Join(want.columns, ", "), uniqueness(other.unique), uniqueness(want.unique)),
+				up:      []string{d.dropIndex(table, other.name), d.createIndex(table, want)},
+				down:    []string{d.dropIndex(table, want.name), d.createIndex(table, *other)},
+			})
+		default:
+			diffs = append(diffs, difference{
+				table:   table,
+				message: fmt.Sprintf("missing %s index %s on (%s)", uniqueness(want.unique), want.name, strings.Join(want.columns, ", ")),
+				up:      []string{d.createIndex(table, want)},
+				down:    []string{d.dropIndex(table, want.name)},
+			})
+		}
+	}
+
+	for _, have := range live {
+		if slices.ContainsFunc(declared, func(want indexSpec) bool { return slices.Equal(want.columns, have.columns) }) {
+			continue
+		}
+		diffs = append(diffs, difference{
+			table:   table,
+			message: fmt.Sprintf("extra %s index %s on (%s) is not declared in the model", uniqueness(have.unique), have.name, strings.Join(have.columns, ", ")),
+			up:      []string{d.dropIndex(table, have.name)},
+			down:    []string{d.createIndex(table, have)},
+		})
+	}
+	return diffs
+}
+
+// migratedFields returns the fields stored in columns.
+func migratedFields(sch *schema.Schema) []*schema.Field {
+	var fields []*schema.Field
+	for _, field := range sch.Fields {
+		if field.DBName != "" && !field.IgnoreMigration {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// modelIndexes returns the indexes declared with index, uniqueIndex and unique tags.
+func modelIndexes(sch *schema.Schema) []indexSpec {
+	var specs []indexSpec
+	for _, index := range sch.ParseIndexes() {
+		spec := indexSpec{name: index.Name, unique: index.Class == "UNIQUE"}
+		for _, option := range index.Fields {
+			if option.Field != nil {
+				spec.columns = append(spec.columns, option.DBName)
+			} else {
+				spec.columns = append(spec.columns, option.Expression)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	for _, field := range migratedFields(sch) {
+		if field.Unique && !field.PrimaryKey {
+			specs = append(specs, indexSpec{name: "uni_" + sch.Table + "_" + field.DBName, columns: []string{field.DBName}, unique: true})
+		}
+	}
+	return specs
+}
+
+// typeDiffers compares the type family of a field and a column, and the length of strings.
+// Widths of the same family (e.g. integer and bigint) are not reported.
+func typeDiffers(field *schema.Field, column gorm.ColumnType) bool {
+	family := columnFamily(column)
+	if family != fieldFamily(field) {
+		return true
+	}
+	if family == "string" && field.Size > 0 {
+		if length, ok := column.Length(); ok && length > 0 && length != int64(field.Size) {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldFamily(field *schema.Field) string {
+	switch field.DataType {
+	case schema.Bool:
+		return "boolean"
+	case schema.Int, schema.Uint:
+		return "integer"
+	case schema.Float:
+		return "float"
+	case schema.String:
+		return "string"
+	case schema.Time:
+		return "time"
+	case schema.Bytes:
+		return "bytes"
+	}
+	return strings.ToLower(string(field.DataType))
+}
+
+func columnFamily(column gorm.ColumnType) string {
+	if columnType, ok := column.ColumnType(); ok && strings.EqualFold(columnType, "tinyint(1)") {
+		return "boolean"
+	}
+
+	name := strings.ToLower(column.DatabaseTypeName())
+	switch {
+	case strings.Contains(name, "bool"):
+		return "boolean"
+	case strings.Contains(name, "int") || strings.Contains(name, "serial"):
+		return "integer"
+	case strings.Contains(name, "char") || strings.Contains(name, "text") || name == "uuid":
+		return "string"
+	case strings.Contains(name, "time") || strings.Contains(name, "date"):
+		return "time"
+	case strings.Contains(name, "float") || strings.Contains(name, "double") || name == "real" || name == "numeric" || name == "decimal":
+		return "float"
+	case strings.Contains(name, "blob") || strings.Contains(name, "binary") || name == "bytea":
+		return "bytes"
+	}
+	return name
+}
+
+func nullability(nullable bool) string {
+	if nullable {
+		return "nullable"
+	}
+	return "NOT NULL"
+}
+
+func uniqueness(unique bool) string {
+	if unique {
+		return "unique"
+	}
+	return "non-unique"
+}
+
+// printDiff compares the models with the database and prints the differences.
+// With a draft name it also writes a migration applying them; it reports whether drift was found.
+func printDiff(t *target, orm *gorm.DB, dir, draft string, timestamp bool) (bool, error) {
+	states, _, _, err := migrationStates(t.m, t.source)
+	if err != nil {
+		return false, err
+	}
+	pending := 0
+	for _, state := range states {
+		if state.status != "applied" {
+			pending++
+		}
+	}
+	if pending > 0 {
+		fmt.Printf("WARNING: %d migrations are not applied; run up first or they show up as drift\n\n", pending)
+	}
+
+	diffs, err := diffSchema(orm, t.driver)
+	if err != nil {
+		return false, err
+	}
+	if len(diffs) == 0 {
+		fmt.Println("No drift: the models match the database")
+		return false, nil
+	}
+
+	var up, down []string
+	table := ""
+	for _, diff := range diffs {
+		if diff.table != table {
+			table = diff.table
+			fmt.Println(table)
+		}
+		fmt.Printf("  - %s\n", diff.message)
+
+		up = append(up, diff.up...)
+		down = append(append([]string{}, diff.down...), down...)
+	}
+	fmt.Printf("\n%d differences found\n", len(diffs))
+
+	if draft == "" {
+		return true, nil
+	}
+
+	files, err := planMigration(dir, "", draft, timestamp)
+	if err != nil {
+		return true, err
+	}
+	// The draft goes to the directory the driver reads; the other dialects get empty files
+	dialect := ""
+	for _, f := range files {
+		if f.dialect == t.driver {
+			dialect = t.driver
+		}
+	}
+	err = writeMigration(dir, files, func(f migrationFiles) ([]byte, []byte) {
+		if f.dialect != dialect {
+			return nil, nil
+		}
+		return render(up), render(down)
+	})
+	if err == nil && len(files) > 1 {
+		fmt.Println("Write the same change for the other dialects in their empty files")
+	}
+	return true, err
+}
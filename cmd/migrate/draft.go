@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// drafter renders the statements of a draft migration in the dialect of the database
+type drafter struct {
+	orm    *gorm.DB
+	driver string
+}
+
+func (d drafter) quote(name string) string {
+	return d.orm.Statement.Quote(name)
+}
+
+// modelColumn returns the column a model field declares.
+func (d drafter) modelColumn(field *schema.Field) columnSpec {
+	expr := d.orm.Migrator().FullDataTypeOf(field)
+	return columnSpec{
+		name:     field.DBName,
+		sqlType:  d.orm.Dialector.DataTypeOf(field),
+		nullable: !field.NotNull && !field.PrimaryKey,
+		full:     d.orm.Dialector.Explain(expr.SQL, expr.Vars...),
+	}
+}
+
+// databaseColumn returns the column as found in the database.
+func (d drafter) databaseColumn(column gorm.ColumnType) columnSpec {
+	sqlType, ok := column.ColumnType()
+	if !ok || sqlType == "" {
+		sqlType = column.DatabaseTypeName()
+		if length, ok := column.Length(); ok && length > 0 {
+			sqlType = fmt.Sprintf("%s(%d)", sqlType, length)
+		}
+	}
+
+	spec := columnSpec{name: column.Name(), sqlType: sqlType, nullable: true}
+	if nullable, ok := column.Nullable(); ok {
+		spec.nullable = nullable
+	}
+
+	spec.full = sqlType
+	if !spec.nullable {
+		spec.full += " NOT NULL"
+	}
+	if value, ok := column.DefaultValue(); ok && value != "" {
+		spec.full += " DEFAULT " + value
+	}
+	return spec
+}
+
+func (d drafter) addColumn(table string, column columnSpec) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", d.quote(table), d.quote(column.name), column.full)
+}
+
+func (d drafter) dropColumn(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.quote(table), d.quote(column))
+}
+
+// alterColumn changes a column from one definition to another.
+func (d drafter) alterColumn(table string, from, to columnSpec) []string {
+	switch d.driver {
+	case "mysql":
+		return []string{fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", d.quote(table), d.quote(to.name), to.full)}
+
+	case "sqlite":
+		return []string{fmt.Sprintf("-- SQLite cannot alter column %s.%s to %s; rebuild the table to change it", table, to.name, to.full)}
+	}
+
+	var statements []string
+	if !strings.EqualFold(from.sqlType, to.sqlType) {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s",
+			d.quote(table), d.quote(to.name), to.sqlType, d.quote(to.name), to.sqlType))
+	}
+	if from.nullable != to.nullable {
+		action := "SET NOT NULL"
+		if to.nullable {
+			action = "DROP NOT NULL"
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s", d.quote(table), d.quote(to.name), action))
+	}
+	return statements
+}
+
+func (d drafter) createIndex(table string, index indexSpec) string {
+	columns := make([]string, len(index.columns))
+	for i, column := range index.columns {
+		columns[i] = d.quote(column)
+	}
+
+	unique := ""
+	if index.unique {
+		unique = "UNIQUE "
+	}
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique, d.quote(index.name), d.quote(table), strings.Join(columns, ", "))
+}
+
+func (d drafter) dropIndex(table, name string) string {
+	if d.driver == "mysql" {
+		return fmt.Sprintf("DROP INDEX %s ON %s", d.quote(name), d.quote(table))
+	}
+	return fmt.Sprintf("DROP INDEX %s", d.quote(name))
+}
+
+// missingTable reports a table that does not exist, drafting it from the model.
+func (d drafter) missingTable(sch *schema.Schema) difference {
+	var definitions []string
+	for _, field := range migratedFields(sch) {
+		definitions = append(definitions, d.quote(field.DBName)+" "+d.modelColumn(field).full)
+	}
+	if len(sch.PrimaryFieldDBNames) > 0 {
+		keys := make([]string, len(sch.PrimaryFieldDBNames))
+		for i, name := range sch.PrimaryFieldDBNames {
+			keys[i] = d.quote(name)
+		}
+		definitions = append(definitions, "PRIMARY KEY ("+strings.Join(keys, ", ")+")")
+	}
+
+	up := []string{fmt.Sprintf("CREATE TABLE %s (\n    %s\n)", d.quote(sch.Table), strings.Join(definitions, ",\n    "))}
+	for _, index := range modelIndexes(sch) {
+		up = append(up, d.createIndex(sch.Table, index))
+	}
+
+	return difference{
+		table:   sch.Table,
+		message: "missing table",
+		up:      up,
+		down:    []string{"DROP TABLE " + d.quote(sch.Table)},
+	}
+}
+
+// render joins the statements of one direction into a migration file.
+func render(statements []string) []byte {
+	var b strings.Builder
+	b.WriteString("-- Draft generated by migrate diff; review it before applying\n\n")
+	for _, statement := range statements {
+		b.WriteString(statement)
+		if !strings.HasPrefix(statement, "--") {
+			b.WriteString(";")
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"skeleton/database/migrations"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// goMarker starts the body the source returns for a Go migration; the database
+// driver recognises it and calls the Go function instead of executing SQL
+const goMarker = "-- migrate:go "
+
+// goSource merges the registered Go migrations into the versions of the SQL files.
+type goSource struct {
+	source.Driver
+	versions   []uint
+	migrations map[uint]migrations.Migration
+}
+
+// newGoSource adds the Go migrations to the SQL source; a version used by both is an error.
+func newGoSource(sqlSource source.Driver, goMigrations []migrations.Migration) (*goSource, error) {
+	s := &goSource{Driver: sqlSource, migrations: map[uint]migrations.Migration{}}
+
+	version, err := sqlSource.First()
+	for err == nil {
+		s.versions = append(s.versions, version)
+		version, err = sqlSource.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, m := range goMigrations {
+		i := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] >= m.Version })
+		if i < len(s.versions) && s.versions[i] == m.Version {
+			return nil, fmt.Errorf("version %d is both a SQL and a Go migration", m.Version)
+		}
+		s.versions = append(s.versions[:i], append([]uint{m.Version}, s.versions[i:]...)...)
+		s.migrations[m.Version] = m
+	}
+	return s, nil
+}
+
+// isGo reports whether the version is a Go migration.
+func (s *goSource) isGo(version uint) bool {
+	_, ok := s.migrations[version]
+	return ok
+}
+
+func (s *goSource) First() (uint, error) {
+	if len(s.versions) == 0 {
+		return 0, &fs.PathError{Op: "first", Path: "migrations", Err: fs.ErrNotExist}
+	}
+	return s.versions[0], nil
+}
+
+func (s *goSource) Prev(version uint) (uint, error) {
+	i := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] >= version })
+	if i == 0 || i == len(s.versions) || s.versions[i] != version {
+		return 0, &fs.PathError{Op: "prev for version " + strconv.FormatUint(uint64(version), 10), Path: "migrations", Err: fs.ErrNotExist}
+	}
+	return s.versions[i-1], nil
+}
+
+func (s *goSource) Next(version uint) (uint, error) {
+	i := sort.Search(len(s.versions), func(i int) bool { return s.versions[i] > version })
+	if i == len(s.versions) {
+		return 0, &fs.PathError{Op: "next for version " + strconv.FormatUint(uint64(version), 10), Path: "migrations", Err: fs.ErrNotExist}
+	}
+	return s.versions[i], nil
+}
+
+func (s *goSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations[version]; ok {
+		return goBody(version, "up"), m.Name, nil
+	}
+	return s.Driver.ReadUp(version)
+}
+
+func (s *goSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	if m, ok := s.migrations[version]; ok {
+		if m.Down == nil {
+			return nil, "", &fs.PathError{Op: "read down for version " + strconv.FormatUint(uint64(version), 10), Path: "migrations", Err: fs.ErrNotExist}
+		}
+		return goBody(version, "down"), m.Name, nil
+	}
+	return s.Driver.ReadDown(version)
+}
+
+// goBody is the migration body standing for one direction of a Go migration.
+func goBody(version uint, direction string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(fmt.Sprintf("%s%d %s", goMarker, version, direction)))
+}
+
+// goDriver runs the Go migrations in a transaction on the target database and
+// passes every SQL migration on to the migrate driver.
+type goDriver struct {
+	database.Driver
+	db         sqlConn
+	driver     string
+	migrations map[uint]migrations.Migration
+}
+
+func (d *goDriver) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(body, []byte(goMarker)) {
+		return d.Driver.Run(bytes.NewReader(body))
+	}
+
+	var version uint
+	var direction string
+	if _, err := fmt.Sscanf(string(body[len(goMarker):]), "%d %s", &version, &direction); err != nil {
+		return fmt.Errorf("invalid Go migration body %q: %w", body, err)
+	}
+	m, ok := d.migrations[version]
+	if !ok {
+		return fmt.Errorf("Go migration %d is not registered", version)
+	}
+	run := m.Up
+	if direction == "down" {
+		run = m.Down
+	}
+
+	ctx := migrations.WithDriver(context.Background(), d.driver)
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("Go migration %d_%s: %w", version, m.Name, err)
+	}
+	if err := run(ctx, tx); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("Go migration %d_%s %s: %w", version, m.Name, direction, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Go migration %d_%s: %w", version, m.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strings"
+	"sync"
+
+	"skeleton/app/models"
+	"skeleton/database/migrations"
+
+	"gorm.io/gorm/schema"
+)
+
+// errUnsafe is returned by lint when a migration may lock or break a live database
+var errUnsafe = errors.New("unsafe migrations found")
+
+// finding is a dangerous statement found in a migration
+type finding struct {
+	line    int
+	rule    string
+	message string
+	sql     string
+}
+
+// statement is one SQL statement of a migration, without its comments
+type statement struct {
+	text string
+	line int
+	// allowed are the rules suppressed by lint:allow comments on the statement
+	allowed map[string]bool
+}
+
+// allowComment matches the annotation suppressing rules, e.g. -- lint:allow rename,column-type-change
+var allowComment = regexp.MustCompile(`lint:allow\s+([a-z-]+(?:\s*,\s*[a-z-]+)*)`)
+
+// ident matches a table or column name, quoted or not, with an optional schema
+const ident = "(?:\"[^\"]+\"|`[^`]+`|\\w+)(?:\\.(?:\"[^\"]+\"|`[^`]+`|\\w+))*"
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMPORARY\s+|TEMP\s+|UNLOGGED\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(` + ident + `)`)
+	createIndexPattern = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+|FULLTEXT\s+|SPATIAL\s+)?INDEX\s+(CONCURRENTLY\s+)?.*?\bON\s+(?:ONLY\s+)?(` + ident + `)`)
+	alterTablePattern  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(` + ident + `)\s+(.*)$`)
+	renameTablePattern = regexp.MustCompile(`(?is)^RENAME\s+TABLE\s+(` + ident + `)`)
+	lockNonePattern    = regexp.MustCompile(`(?i)\bLOCK\s*=\s*NONE\b`)
+
+	addConstraintAction = regexp.MustCompile(`(?is)^ADD\s+(?:CONSTRAINT|PRIMARY|FOREIGN|CHECK)\b`)
+	addIndexAction      = regexp.MustCompile(`(?is)^ADD\s+(?:UNIQUE|INDEX|KEY|FULLTEXT|SPATIAL)\b`)
+	addColumnAction     = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(` + ident + `)\s+(.*)$`)
+	alterTypeAction     = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?(` + ident + `)\s+(?:SET\s+DATA\s+)?TYPE\b`)
+	modifyAction        = regexp.MustCompile(`(?is)^MODIFY\s+(?:COLUMN\s+)?(` + ident + `)`)
+	changeAction        = regexp.MustCompile(`(?is)^CHANGE\s+(?:COLUMN\s+)?(` + ident + `)\s+(` + ident + `)`)
+	renameTableAction   = regexp.MustCompile(`(?is)^RENAME\s+(?:TO|AS)\s+`)
+	renameOtherAction   = regexp.MustCompile(`(?is)^RENAME\s+(?:INDEX|KEY|CONSTRAINT)\b`)
+	renameColumnAction  = regexp.MustCompile(`(?is)^RENAME\s+(?:COLUMN\s+)?(` + ident + `)\s+TO\s+`)
+	dropOtherAction     = regexp.MustCompile(`(?is)^DROP\s+(?:CONSTRAINT|INDEX|KEY|PRIMARY|FOREIGN|CHECK|DEFAULT)\b`)
+	dropColumnAction    = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?(` + ident + `)`)
+	notNullPattern      = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	defaultPattern      = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+)
+
+// linter checks the statements of migrations in version order.
+type linter struct {
+	driver string
+	// columns are the model columns by table, naming the model using them
+	columns map[string]map[string]string
+	// created are the tables created by the migrations linted so far: they hold
+	// no rows and no running code uses them yet, so locking them is harmless
+	created map[string]bool
+}
+
+// lintPending checks the migrations not applied to the target yet.
+func lintPending(t *target) error {
+	states, _, _, err := migrationStates(t.m, t.source)
+	if err != nil {
+		return err
+	}
+	var versions []uint
+	for _, state := range states {
+		if state.status != "applied" {
+			versions = append(versions, state.version)
+		}
+	}
+	if len(versions) == 0 {
+		fmt.Println("No pending migrations to check")
+		return nil
+	}
+	return lintMigrations(t.source, t.driver, versions)
+}
+
+// lintAll checks every migration of a connection, without connecting to its database.
+func lintAll(fsys fs.FS, connection, driverName string) error {
+	sqlSource, err := newSource(fsys, driverName)
+	if err != nil {
+		return fmt.Errorf("Failed to read migrations: %w", err)
+	}
+	src, err := newGoSource(sqlSource, migrations.Registered(connection))
+	if err != nil {
+		return fmt.Errorf("Failed to read migrations: %w", err)
+	}
+	return lintMigrations(src, driverName, src.versions)
+}
+
+// lintMigrations checks the up files of the SQL migrations with the versions (Go migrations
+// are skipped) and prints what it finds; it returns errUnsafe when it finds anything.
+func lintMigrations(src *goSource, driverName string, versions []uint) error {
+	if driverName != "postgres" && driverName != "mysql" {
+		fmt.Printf("Skipped: lint checks PostgreSQL and MySQL migrations (driver: %s)\n", driverName)
+		return nil
+	}
+
+	columns, err := modelColumns()
+	if err != nil {
+		return err
+	}
+	l := &linter{driver: driverName, columns: columns, created: map[string]bool{}}
+
+	total, linted := 0, 0
+	for _, version := range versions {
+		if src.isGo(version) {
+			continue
+		}
+		r, identifier, err := src.ReadUp(version)
+		if err != nil {
+			continue
+		}
+		body, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("Failed to read migration %d: %w", version, err)
+		}
+		linted++
+
+		findings := l.lint(string(body))
+		if len(findings) == 0 {
+			continue
+		}
+		fmt.Printf("%d %s\n", version, identifier)
+		for _, f := range findings {
+			fmt.Printf("  line %d: %s: %s\n    %s\n", f.line, f.rule, f.message, f.sql)
+		}
+		fmt.Println()
+		total += len(findings)
+	}
+
+	if total == 0 {
+		fmt.Printf("No problems found in %d migrations\n", linted)
+		return nil
+	}
+	fmt.Printf("%d problems found in %d migrations\n", total, linted)
+	fmt.Println(`Put "-- lint:allow <rule>" above a statement known to be safe, e.g. on a small table`)
+	return errUnsafe
+}
+
+// lint returns the findings in the statements of a migration that lint:allow does not suppress.
+func (l *linter) lint(sql string) []finding {
+	var findings []finding
+	for _, stmt := range splitStatements(sql) {
+		for _, f := range l.statement(stmt.text) {
+			if stmt.allowed[f.rule] {
+				continue
+			}
+			f.line = stmt.line
+			f.sql = summarize(stmt.text)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// statement returns the findings in one statement.
+func (l *linter) statement(text string) []finding {
+	if m := createTablePattern.FindStringSubmatch(text); m != nil {
+		l.created[tableName(m[1])] = true
+		return nil
+	}
+
+	if m := createIndexPattern.FindStringSubmatch(text); m != nil {
+		table := tableName(m[2])
+		if l.created[table] {
+			return nil
+		}
+		if l.driver == "postgres" && m[1] == "" {
+			return []finding{{rule: "index-not-concurrent", message: fmt.Sprintf("CREATE INDEX on %s without CONCURRENTLY blocks writes while it builds; use CREATE INDEX CONCURRENTLY, alone in its migration (it can't run in a transaction)", table)}}
+		}
+		if l.driver == "mysql" && !lockNonePattern.MatchString(text) {
+			return []finding{{rule: "index-not-concurrent", message: fmt.Sprintf("index on %s without LOCK=NONE may block writes while it builds; add ALGORITHM=INPLACE LOCK=NONE", table)}}
+		}
+		return nil
+	}
+
+	if m := renameTablePattern.FindStringSubmatch(text); m != nil {
+		return []finding{renamed("table " + tableName(m[1]))}
+	}
+
+	m := alterTablePattern.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	table := tableName(m[1])
+	var findings []finding
+	for _, action := range splitTopLevel(m[2]) {
+		if f, ok := l.action(table, action, text); ok {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// action checks one action of an ALTER TABLE statement on table.
+func (l *linter) action(table, action, text string) (finding, bool) {
+	// Dropping a column the models use breaks the code even on a new table
+	if !dropOtherAction.MatchString(action) {
+		if m := dropColumnAction.FindStringSubmatch(action); m != nil {
+			column := tableName(m[1])
+			if model, ok := l.columns[table][column]; ok {
+				return finding{rule: "drop-referenced-column", message: fmt.Sprintf("column %s.%s is still used by model %s; remove the field and deploy before dropping the column", table, column, model)}, true
+			}
+			return finding{}, false
+		}
+	}
+	if l.created[table] {
+		return finding{}, false
+	}
+
+	switch {
+	case addConstraintAction.MatchString(action):
+	case addIndexAction.MatchString(action):
+		if l.driver == "mysql" && !lockNonePattern.MatchString(text) {
+			return finding{rule: "index-not-concurrent", message: fmt.Sprintf("index on %s without LOCK=NONE may block writes while it builds; add ALGORITHM=INPLACE, LOCK=NONE", table)}, true
+		}
+	case addColumnAction.MatchString(action):
+		m := addColumnAction.FindStringSubmatch(action)
+		if notNullPattern.MatchString(m[2]) && !defaultPattern.MatchString(m[2]) {
+			return finding{rule: "not-null-without-default", message: fmt.Sprintf("NOT NULL column %s.%s without a DEFAULT fails on existing rows; add a DEFAULT, or add it nullable, backfill, then set NOT NULL", table, tableName(m[1]))}, true
+		}
+	case alterTypeAction.MatchString(action):
+		return typeChanged(table, alterTypeAction.FindStringSubmatch(action)[1]), true
+	case modifyAction.MatchString(action):
+		return typeChanged(table, modifyAction.FindStringSubmatch(action)[1]), true
+	case changeAction.MatchString(action):
+		m := changeAction.FindStringSubmatch(action)
+		if tableName(m[1]) != tableName(m[2]) {
+			return renamed(fmt.Sprintf("column %s.%s", table, tableName(m[1]))), true
+		}
+		return typeChanged(table, m[1]), true
+	case renameTableAction.MatchString(action):
+		return renamed("table " + table), true
+	case renameOtherAction.MatchString(action):
+	case renameColumnAction.MatchString(action):
+		return renamed(fmt.Sprintf("column %s.%s", table, tableName(renameColumnAction.FindStringSubmatch(action)[1]))), true
+	}
+	return finding{}, false
+}
+
+// typeChanged is the finding for a column type change.
+func typeChanged(table, column string) finding {
+	return finding{rule: "column-type-change", message: fmt.Sprintf("changing the type of %s.%s rewrites the table under an exclusive lock; add a new column, backfill it, then switch to it", table, tableName(column))}
+}
+
+// renamed is the finding for a renamed table or column.
+func renamed(what string) finding {
+	return finding{rule: "rename", message: fmt.Sprintf("renaming %s breaks the code still running with the old name; add the new name, deploy, then drop the old one", what)}
+}
+
+// modelColumns returns the columns of every model in models.All by table, naming the model using them.
+func modelColumns() (map[string]map[string]string, error) {
+	cache := &sync.Map{}
+	columns := map[string]map[string]string{}
+	for _, model := range models.All() {
+		sch, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse model %T: %w", model, err)
+		}
+		table := strings.ToLower(sch.Table)
+		if columns[table] == nil {
+			columns[table] = map[string]string{}
+		}
+		for _, field := range migratedFields(sch) {
+			columns[table][strings.ToLower(field.DBName)] = sch.Name
+		}
+	}
+	return columns, nil
+}
+
+// tableName returns the unquoted, lowercase name of a table or column, without its schema.
+func tableName(name string) string {
+	parts := identPart.FindAllString(name, -1)
+	if len(parts) == 0 {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(strings.Trim(parts[len(parts)-1], "\"`"))
+}
+
+// identPart matches the parts of a qualified name
+var identPart = regexp.MustCompile("\"[^\"]+\"|`[^`]+`|\\w+")
+
+// summarize returns the statement on one line, shortened for display.
+func summarize(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) > 100 {
+		text = text[:97] + "..."
+	}
+	return text
+}
+
+// splitStatements splits a migration into statements at the semicolons outside of strings,
+// quoted names and comments. lint:allow comments apply to the statement they are in or above,
+// or to the previous one when they follow it on the same line.
+func splitStatements(sql string) []statement {
+	var statements []statement
+	var text strings.Builder
+	current := statement{allowed: map[string]bool{}}
+	line, endLine := 1, 0
+
+	allow := func(comment string, commentLine int) {
+		m := allowComment.FindStringSubmatch(comment)
+		if m == nil {
+			return
+		}
+		target := current.allowed
+		if strings.TrimSpace(text.String()) == "" && commentLine == endLine && len(statements) > 0 {
+			target = statements[len(statements)-1].allowed
+		}
+		for _, rule := range strings.Split(m[1], ",") {
+			target[strings.TrimSpace(rule)] = true
+		}
+	}
+	flush := func() {
+		if t := strings.TrimSpace(text.String()); t != "" {
+			current.text = t
+			statements = append(statements, current)
+			current = statement{allowed: map[string]bool{}}
+		}
+		text.Reset()
+		endLine = line
+	}
+	write := func(s string) {
+		if current.line == 0 && strings.TrimSpace(s[:1]) != "" {
+			current.line = line
+		}
+		text.WriteString(s)
+		line += strings.Count(s, "\n")
+	}
+
+	for i := 0; i < len(sql); {
+		rest := sql[i:]
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			allow(rest[:end], line)
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			allow(rest[:end], line)
+			line += strings.Count(rest[:end], "\n")
+			text.WriteString(" ")
+			i += end
+		case rest[0] == '\'' || rest[0] == '"' || rest[0] == '`':
+			end := quotedEnd(rest, rest[0:1])
+			write(rest[:end])
+			i += end
+		case rest[0] == '$' && dollarTag.MatchString(rest):
+			tag := dollarTag.FindString(rest)
+			end := strings.Index(rest[len(tag):], tag)
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2 * len(tag)
+			}
+			write(rest[:end])
+			i += end
+		case rest[0] == ';':
+			flush()
+			i++
+		default:
+			write(rest[:1])
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+// dollarTag matches the opening of a PostgreSQL dollar-quoted string
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// quotedEnd returns the length of the quoted string at the start of s; doubled quotes stay inside it.
+func quotedEnd(s, quote string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i:i+1] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1:i+2] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(s)
+}
+
+// splitTopLevel splits the actions of an ALTER TABLE statement at the commas outside of
+// parentheses and quotes.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '\'', '"', '`':
+			i += quotedEnd(s[i:], s[i:i+1]) - 1
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
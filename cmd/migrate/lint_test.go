@@ -0,0 +1,155 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		want      []string
+		wantLines []int
+	}{
+		{
+			name:      "statements on their lines",
+			sql:       "CREATE TABLE a (id INT);\n\nALTER TABLE a ADD b INT;\n",
+			want:      []string{"CREATE TABLE a (id INT)", "ALTER TABLE a ADD b INT"},
+			wantLines: []int{1, 3},
+		},
+		{
+			name:      "semicolons in quoted strings",
+			sql:       "INSERT INTO a VALUES ('x;y', \"p;q\", `r;s`);\nSELECT 'it''s;';",
+			want:      []string{"INSERT INTO a VALUES ('x;y', \"p;q\", `r;s`)", "SELECT 'it''s;'"},
+			wantLines: []int{1, 2},
+		},
+		{
+			name:      "semicolons in comments",
+			sql:       "-- drop; later\nSELECT 1; /* a;\nb */ SELECT 2;",
+			want:      []string{"SELECT 1", "SELECT 2"},
+			wantLines: []int{2, 3},
+		},
+		{
+			name: "dollar-quoted bodies",
+			sql: "CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n" +
+				"DO $body$ BEGIN PERFORM 1; END $body$;",
+			want: []string{
+				"CREATE FUNCTION f() RETURNS trigger AS $$\nBEGIN\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+				"DO $body$ BEGIN PERFORM 1; END $body$",
+			},
+			wantLines: []int{1, 6},
+		},
+		{
+			name:      "unterminated quote runs to the end",
+			sql:       "SELECT 'a; b",
+			want:      []string{"SELECT 'a; b"},
+			wantLines: []int{1},
+		},
+		{
+			name: "only comments and blank statements",
+			sql:  "-- nothing\n;;\n/* here */",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements := splitStatements(tt.sql)
+
+			var got []string
+			var lines []int
+			for _, stmt := range statements {
+				got = append(got, stmt.text)
+				lines = append(lines, stmt.line)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got statements %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("got lines %v, want %v", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestSplitStatementsAllow(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		// want are the allowed rules of each statement
+		want []map[string]bool
+	}{
+		{
+			name: "comment above the statement",
+			sql:  "-- lint:allow rename, column-type-change\nALTER TABLE a RENAME b TO c;\nALTER TABLE a DROP d;",
+			want: []map[string]bool{{"rename": true, "column-type-change": true}, {}},
+		},
+		{
+			name: "comment after the statement on its last line",
+			sql:  "ALTER TABLE a RENAME b TO c; -- lint:allow rename\nALTER TABLE a DROP d;",
+			want: []map[string]bool{{"rename": true}, {}},
+		},
+		{
+			name: "block comment inside the statement",
+			sql:  "ALTER TABLE a /* lint:allow drop-column */ DROP d;",
+			want: []map[string]bool{{"drop-column": true}},
+		},
+		{
+			name: "annotation in a string is not a comment",
+			sql:  "INSERT INTO notes VALUES ('-- lint:allow rename');",
+			want: []map[string]bool{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements := splitStatements(tt.sql)
+			if len(statements) != len(tt.want) {
+				t.Fatalf("got %d statements, want %d", len(statements), len(tt.want))
+			}
+			for i, stmt := range statements {
+				if !reflect.DeepEqual(stmt.allowed, tt.want[i]) {
+					t.Errorf("statement %d: got allowed %v, want %v", i, stmt.allowed, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTopLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions string
+		want    []string
+	}{
+		{name: "single action", actions: "ADD COLUMN a INT", want: []string{"ADD COLUMN a INT"}},
+		{
+			name:    "several actions",
+			actions: "ADD a INT, DROP COLUMN b ,RENAME c TO d",
+			want:    []string{"ADD a INT", "DROP COLUMN b", "RENAME c TO d"},
+		},
+		{
+			name:    "commas in parentheses",
+			actions: "ADD a DECIMAL(10, 2), ADD CONSTRAINT u UNIQUE (b, c)",
+			want:    []string{"ADD a DECIMAL(10, 2)", "ADD CONSTRAINT u UNIQUE (b, c)"},
+		},
+		{
+			name:    "commas in quotes",
+			actions: "ADD a TEXT DEFAULT 'x, y', ADD \"b,c\" INT, ADD `d,e` INT",
+			want:    []string{"ADD a TEXT DEFAULT 'x, y'", "ADD \"b,c\" INT", "ADD `d,e` INT"},
+		},
+		{
+			name:    "parentheses in quotes",
+			actions: "ADD a TEXT DEFAULT '(', ADD b INT",
+			want:    []string{"ADD a TEXT DEFAULT '('", "ADD b INT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitTopLevel(tt.actions); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// locks reports whether the command changes the database and so runs under the migration lock
+func locks(command string) bool {
+	switch command {
+	case "status", "version", "diff", "lint":
+		return false
+	}
+	return true
+}
+
+// lockKey names the migration lock of a connection's database (and schema)
+func lockKey(cfg connectionConfig) string {
+	return fmt.Sprintf("migrate:%s:%s", cfg.database, cfg.schema)
+}
+
+// acquireLock takes a database-wide advisory lock for the whole command, so concurrent deploys
+// migrate one at a time: the others wait up to timeout, then find nothing left to run.
+// golang-migrate only locks each step, which leaves e.g. fresh -seed racing with a second run.
+// SQLite files are local to one host and are not locked.
+func acquireLock(ctx context.Context, db *sql.DB, driverName, key string, timeout time.Duration) (func(), error) {
+	if driverName != "postgres" && driverName != "mysql" {
+		return func() {}, nil
+	}
+
+	// Advisory locks belong to the session, so the lock is taken and released on one connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		acquired, err := tryLock(ctx, conn, driverName, key)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			conn.Close()
+			return nil, fmt.Errorf("another migration still holds %s after %s", key, timeout)
+		}
+		if !waiting {
+			fmt.Println("Waiting for another migration to finish...")
+			waiting = true
+		}
+		time.Sleep(time.Second)
+	}
+
+	return func() {
+		if err := unlock(ctx, conn, driverName, key); err != nil {
+			fmt.Printf("Warning: Failed to release the migration lock: %v\n", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+func tryLock(ctx context.Context, conn *sql.Conn, driverName, key string) (bool, error) {
+	var acquired bool
+	var err error
+	if driverName == "postgres" {
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockID(key)).Scan(&acquired)
+	} else {
+		// GET_LOCK returns 1 when acquired, 0 on timeout
+		var result sql.NullInt64
+		err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", mysqlLockName(key)).Scan(&result)
+		acquired = result.Valid && result.Int64 == 1
+	}
+	return acquired, err
+}
+
+func unlock(ctx context.Context, conn *sql.Conn, driverName, key string) error {
+	if driverName == "postgres" {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockID(key))
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", mysqlLockName(key))
+	return err
+}
+
+// lockID maps the key to the 64-bit key of a PostgreSQL advisory lock
+func lockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// mysqlLockName fits the key in the 64 characters MySQL allows for lock names
+func mysqlLockName(key string) string {
+	if len(key) <= 64 {
+		return key
+	}
+	return fmt.Sprintf("migrate:%x", uint64(lockID(key)))
+}
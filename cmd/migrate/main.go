@@ -1,131 +1,377 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	"skeleton/app/repositories"
+	"skeleton/app/support/tenancy"
+	"skeleton/database/migrations"
 
 	"github.com/donnigundala/dg-core/config"
-	dgdb "github.com/donnigundala/dg-database"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 )
 
+const usage = `Usage: go run ./cmd/migrate <command> [flags] [args]
+
+Commands:
+  up               Apply all pending migrations (-steps to apply only N)
+  down             Roll back the last migration (-steps N, -all for every migration)
+  goto <version>   Migrate up or down to a version
+  force <version>  Set the version without running migrations, clearing a dirty state
+  fresh            Drop every table, then apply all migrations
+  refresh          Roll back every migration, then apply all migrations
+  reset            Roll back every migration
+  status           List every SQL and Go migration with its applied, pending or dirty state
+  version          Print the current version
+  seed             Run every seeder, or the seeders named with -class
+  lint             Check the pending migrations for operations unsafe on a live database (-all for every migration)
+  diff             Compare the models with the database (-draft <name> writes a migration for the drift)
+  create <name>    Create the up/down files of a new migration (-timestamp for timestamp versions,
+                   -go for a Go migration in the migrations package)
+
+Flags (before the arguments):
+  -config           Configuration directory (default: config)
+  -path             Read migrations from this directory instead of the embedded ones
+  -connection       Run on a connection of db.connections instead of the default one
+  -all-connections  Run on the default connection, then on every named connection
+  -tenants          Run in every active tenant schema
+  -tenant           Run in a single tenant schema (slug)
+  -force            Allow fresh, refresh, reset and seed when app.env is production
+  -seed             Run the seeders afterwards (up, fresh, refresh)
+  -lock-timeout     How long to wait for a migration running elsewhere (default: 5m)
+`
+
+// operation is a parsed migration command
+type operation struct {
+	command string
+	steps   int
+	all     bool
+	version uint
+	seed    bool
+	classes []string
+	// draft and timestamp name the migration diff writes
+	draft     string
+	timestamp bool
+}
+
+// options are the flags shared by every connection a command runs on
+type options struct {
+	path        string
+	tenants     bool
+	tenant      string
+	lockTimeout time.Duration
+}
+
+// errDrift is returned by diff when the models and the database differ
+var errDrift = errors.New("schema drift found")
+
 func main() {
-	// Parse flags
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.Usage = func() { fmt.Print(usage) }
 	var (
-		direction = flag.String("direction", "up", "Migration direction: up, down, version")
-		steps     = flag.Int("steps", 0, "Number of steps to migrate (for down)")
-		version   = flag.Uint("version", 0, "Migrate to specific version")
-		configDir = flag.String("config", "config", "Configuration directory")
+		configDir      = flags.String("config", "config", "Configuration directory")
+		path           = flags.String("path", "", "Read migrations from this directory instead of the embedded ones")
+		connection     = flags.String("connection", "", "Run on a connection of db.connections instead of the default one")
+		allConnections = flags.Bool("all-connections", false, "Run on the default connection, then on every connection of db.connections")
+		tenants        = flags.Bool("tenants", false, "Run the tenant migrations in every active tenant schema")
+		tenant         = flags.String("tenant", "", "Run the tenant migrations in a single tenant schema (slug)")
+		steps          = flags.Int("steps", 0, "Number of migrations to apply or roll back (up, down)")
+		all            = flags.Bool("all", false, "Roll back every migration (down), check every migration (lint)")
+		timestamp      = flags.Bool("timestamp", false, "Use a timestamp instead of the next sequence number as version (create, diff)")
+		goMigration    = flags.Bool("go", false, "Create a Go migration instead of SQL files (create)")
+		force          = flags.Bool("force", false, "Allow destructive commands and seeding in production")
+		seeding        = flags.Bool("seed", false, "Run the seeders after migrating (up, fresh, refresh)")
+		class          = flags.String("class", "", "Comma-separated seeders to run, e.g. UserSeeder (seed)")
+		draft          = flags.String("draft", "", "Write a draft migration with this name for the drift (diff)")
+		lockTimeout    = flags.Duration("lock-timeout", 5*time.Minute, "How long to wait for a migration running elsewhere")
 	)
-	flag.Parse()
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		log.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	op := operation{
+		command:   command,
+		steps:     *steps,
+		all:       *all,
+		seed:      *seeding || command == "seed",
+		draft:     *draft,
+		timestamp: *timestamp,
+	}
+	if *class != "" {
+		op.classes = strings.Split(*class, ",")
+	}
+	switch command {
+	case "create":
+		// Creating files needs neither configuration nor a database
+		if flags.NArg() != 1 {
+			log.Fatal("Usage: go run ./cmd/migrate create [-go] [-timestamp] [-path dir] [-connection name] <name>")
+		}
+		create := createMigration
+		if *goMigration {
+			create = createGoMigration
+		}
+		if err := create(migrationsDir(*path), *connection, flags.Arg(0), *timestamp); err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		return
+
+	case "goto", "force":
+		if flags.NArg() != 1 {
+			log.Fatalf("Usage: go run ./cmd/migrate %s <version>", command)
+		}
+		version, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid version %q: %v", flags.Arg(0), err)
+		}
+		op.version = uint(version)
+
+	case "up", "down", "fresh", "refresh", "reset", "status", "version", "seed", "diff", "lint":
+
+	default:
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	// Seeding after reset would write to the tables it just dropped
+	if *seeding && command != "up" && command != "fresh" && command != "refresh" {
+		log.Fatalf("-seed runs after up, fresh and refresh only, not %s", command)
+	}
 
 	// Load configuration
 	if err := config.LoadWithPaths(*configDir); err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Build database configuration
-	port := 5432 // default
-	if p := config.Get("db.port"); p != nil {
-		if portInt, ok := p.(int); ok {
-			port = portInt
-		}
+	if (destructive(command) || op.seed) && config.GetString("app.env") == "production" && !*force {
+		log.Fatalf("Refusing to run %s in production; add -force to run it anyway", command)
+	}
+
+	names := []string{*connection}
+	if *allConnections {
+		names = append([]string{""}, connectionNames()...)
+	}
+	if (*tenants || *tenant != "") && (*connection != "" || *allConnections) {
+		log.Fatal("Tenant schemas live on the default connection; drop -connection and -all-connections")
 	}
 
-	dbConfig := dgdb.DefaultConfig().
-		WithDriver(config.GetString("db.driver")).
-		WithHost(config.GetString("db.host")).
-		WithPort(port).
-		WithDatabase(config.GetString("db.name")).
-		WithCredentials(
-			config.GetString("db.username"),
-			config.GetString("db.password"),
-		)
+	opts := options{path: *path, tenants: *tenants, tenant: *tenant, lockTimeout: *lockTimeout}
+	for _, name := range names {
+		if len(names) > 1 {
+			fmt.Printf("== Connection %s\n", connectionLabel(name))
+		}
 
-	// Add schema if specified (PostgreSQL)
-	if schema := config.GetString("db.schema"); schema != "" {
-		dbConfig = dbConfig.WithSchema(schema)
+		err := migrateConnection(name, op, opts)
+		if errors.Is(err, errDrift) || errors.Is(err, errUnsafe) {
+			// Fail on drift (unless a draft was requested) and unsafe migrations, e.g. in CI
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
+}
 
-	// Create database manager
-	manager, err := dgdb.NewManager(dbConfig, nil)
+// migrateConnection runs the operation on one connection ("" for the default one),
+// holding the connection's migration lock for commands that change it.
+func migrateConnection(name string, op operation, opts options) error {
+	cfg, err := loadConnection(name)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		return err
 	}
-	defer manager.Close()
 
-	// Get SQL database
-	sqlDB, err := manager.SQL()
+	fsys, err := migrationsFS(opts.path, name)
 	if err != nil {
-		log.Fatalf("Failed to get SQL database: %v", err)
+		return err
+	}
+	if fsys == nil {
+		if len(migrations.Registered(name)) == 0 {
+			fmt.Printf("No migrations for connection %s\n", name)
+			return nil
+		}
+		// The connection only has Go migrations
+		fsys = fstest.MapFS{}
 	}
 
-	// Create database driver based on driver type
-	var driver database.Driver
-	switch dbConfig.Driver {
-	case "postgres":
-		driver, err = postgres.WithInstance(sqlDB, &postgres.Config{})
-	case "mysql":
-		driver, err = mysql.WithInstance(sqlDB, &mysql.Config{})
-	default:
-		log.Fatalf("Unsupported database driver: %s (supported: postgres, mysql)", dbConfig.Driver)
+	if op.command == "lint" && op.all {
+		// Checking every migration needs the files only
+		return lintAll(fsys, name, cfg.driver)
 	}
 
+	conn, err := openConnection(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create database driver: %v", err)
+		return err
 	}
+	defer conn.Close()
 
-	// Create migrator
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://database/migrations",
-		dbConfig.Name,
-		driver,
-	)
-	if err != nil {
-		log.Fatalf("Failed to create migrator: %v", err)
+	if locks(op.command) {
+		release, err := acquireLock(context.Background(), conn.sqlDB, cfg.driver, lockKey(cfg), opts.lockTimeout)
+		if err != nil {
+			return fmt.Errorf("Failed to acquire the migration lock: %w", err)
+		}
+		defer release()
 	}
 
-	// Run migration based on direction
-	switch *direction {
-	case "up":
-		if *version > 0 {
-			err = m.Migrate(*version)
-		} else {
-			err = m.Up()
+	if opts.tenants || opts.tenant != "" {
+		if op.command == "diff" || op.command == "lint" {
+			return fmt.Errorf("%s checks the shared schema only; run it without -tenants", op.command)
 		}
-		if err != nil && err != migrate.ErrNoChange {
-			log.Fatalf("Migration failed: %v", err)
+		if cfg.driver != "postgres" {
+			return fmt.Errorf("Tenant migrations require PostgreSQL schemas (driver: %s)", cfg.driver)
 		}
-		if err == migrate.ErrNoChange {
-			fmt.Println("No migrations to run")
-		} else {
-			fmt.Println("Migrations completed successfully")
+
+		var tenancyConfig tenancy.Config
+		if err := config.Inject("tenancy", &tenancyConfig); err != nil {
+			return fmt.Errorf("Failed to load tenancy configuration: %w", err)
+		}
+		registry := tenancy.NewRegistry(tenancyConfig, repositories.NewTenantRepository(conn.orm()))
+
+		tenantFS, err := tenantMigrationsFS(registry.Config().MigrationsPath, opts.path)
+		if err != nil {
+			return err
+		}
+
+		return migrateTenants(registry, conn.sqlDB, conn.orm(), conn.dbName(), tenantFS, opts.tenant, op)
+	}
+
+	open := func() (*target, error) {
+		driver, err := newDriver(cfg, conn.sqlDB)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create database driver: %w", err)
+		}
+		return newTarget(fsys, name, cfg.driver, conn.dbName(), driver, conn.sqlDB)
+	}
+
+	switch op.command {
+	case "seed":
+	case "lint":
+		t, err := open()
+		if err != nil {
+			return err
+		}
+		return lintPending(t)
+	case "diff":
+		if name != "" {
+			fmt.Println("Skipped: diff compares the models with the default connection only")
+			return nil
+		}
+		t, err := open()
+		if err != nil {
+			return err
+		}
+		drift, err := printDiff(t, conn.orm(), migrationsDir(opts.path), op.draft, op.timestamp)
+		if err != nil {
+			return err
+		}
+		if drift && op.draft == "" {
+			return errDrift
+		}
+		return nil
+	default:
+		if err := run(open, op); err != nil {
+			return err
+		}
+	}
+
+	if op.seed {
+		if name != "" {
+			fmt.Println("Skipped seeding: seeders write to the default connection")
+			return nil
+		}
+		return seed(context.Background(), conn.orm(), op.classes)
+	}
+	return nil
+}
+
+// migrationsDir returns the directory new migration files are written to.
+func migrationsDir(path string) string {
+	if path == "" {
+		return "database/migrations"
+	}
+	return path
+}
+
+// newDriver creates the migrate driver for a connection.
+func newDriver(cfg connectionConfig, sqlDB *sql.DB) (database.Driver, error) {
+	switch cfg.driver {
+	case "postgres":
+		return postgres.WithInstance(sqlDB, &postgres.Config{SchemaName: cfg.schema})
+	case "mysql":
+		return mysql.WithInstance(sqlDB, &mysql.Config{})
+	case "sqlite":
+		return sqlite3.WithInstance(sqlDB, &sqlite3.Config{})
+	}
+	return nil, fmt.Errorf("unsupported database driver: %s (supported: postgres, mysql, sqlite)", cfg.driver)
+}
+
+// run opens the target and executes the operation, then prints the resulting version.
+func run(open opener, op operation) error {
+	t, err := open()
+	if err != nil {
+		return err
+	}
+	m := t.m
+
+	switch op.command {
+	case "up":
+		if err := up(m, t.source, op.steps); err != nil {
+			return err
 		}
 
 	case "down":
-		if *steps > 0 {
-			err = m.Steps(-*steps)
-		} else {
-			err = m.Down()
+		if err := down(m, op.steps, op.all); err != nil {
+			return err
 		}
+
+	case "goto":
+		err = m.Migrate(op.version)
 		if err != nil && err != migrate.ErrNoChange {
-			log.Fatalf("Rollback failed: %v", err)
+			return fmt.Errorf("Migration to version %d failed: %w", op.version, dirtyHint(err))
 		}
 		if err == migrate.ErrNoChange {
-			fmt.Println("No migrations to rollback")
+			fmt.Printf("Already at version %d\n", op.version)
 		} else {
-			fmt.Println("Rollback completed successfully")
+			fmt.Printf("Migrated to version %d\n", op.version)
+		}
+
+	case "force":
+		if err := m.Force(int(op.version)); err != nil {
+			return fmt.Errorf("Failed to force version %d: %w", op.version, err)
+		}
+		fmt.Printf("Forced version %d; the dirty state is cleared\n", op.version)
+
+	case "fresh", "refresh", "reset":
+		if m, err = rebuild(open, t, op.command); err != nil {
+			return err
 		}
 
+	case "status":
+		return printStatus(m, t.source)
+
 	case "version":
 		version, dirty, err := m.Version()
 		if err != nil && err != migrate.ErrNilVersion {
-			log.Fatalf("Failed to get version: %v", err)
+			return fmt.Errorf("Failed to get version: %w", err)
 		}
 		if err == migrate.ErrNilVersion {
 			fmt.Println("No migrations applied yet")
@@ -135,10 +381,7 @@ func main() {
 				fmt.Println("WARNING: Database is in dirty state!")
 			}
 		}
-		return
-
-	default:
-		log.Fatalf("Invalid direction: %s (use: up, down, version)", *direction)
+		return nil
 	}
 
 	// Show current version
@@ -151,4 +394,87 @@ func main() {
 			fmt.Println("WARNING: Database is in dirty state!")
 		}
 	}
+	return nil
+}
+
+// up applies the pending migrations, or only the next steps of them.
+func up(m *migrate.Migrate, src *goSource, steps int) error {
+	var err error
+	if steps > 0 {
+		// Steps reports an empty plan as os.ErrNotExist, like a missing migration
+		// file, so check for pending migrations first
+		var pending bool
+		if pending, err = hasPending(m, src); err != nil {
+			return fmt.Errorf("Migration failed: %w", err)
+		}
+		if pending {
+			err = m.Steps(steps)
+		} else {
+			err = migrate.ErrNoChange
+		}
+	} else {
+		err = m.Up()
+	}
+	if err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("Migration failed: %w", dirtyHint(err))
+	}
+	if err != nil {
+		fmt.Println("No migrations to run")
+	} else {
+		fmt.Println("Migrations completed successfully")
+	}
+	return nil
+}
+
+// down rolls back the last migration, the last steps of them, or all of them.
+func down(m *migrate.Migrate, steps int, all bool) error {
+	// Steps reports rolling back from no version as os.ErrNotExist, like a missing
+	// migration file, so check for an applied version first
+	_, _, err := m.Version()
+	switch {
+	case err == migrate.ErrNilVersion:
+		err = migrate.ErrNoChange
+	case err != nil:
+		return fmt.Errorf("Rollback failed: %w", err)
+	case all:
+		err = m.Down()
+	case steps > 0:
+		err = m.Steps(-steps)
+	default:
+		err = m.Steps(-1)
+	}
+	if err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("Rollback failed: %w", dirtyHint(err))
+	}
+	if err != nil {
+		fmt.Println("No migrations to rollback")
+	} else {
+		fmt.Println("Rollback completed successfully")
+	}
+	return nil
+}
+
+// hasPending reports whether a migration of the source follows the applied version.
+func hasPending(m *migrate.Migrate, src *goSource) (bool, error) {
+	version, _, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return len(src.versions) > 0, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	i := sort.Search(len(src.versions), func(i int) bool { return src.versions[i] >= version })
+	if i == len(src.versions) || src.versions[i] != version {
+		return false, fmt.Errorf("the applied version %d has no migration file", version)
+	}
+	return i+1 < len(src.versions), nil
+}
+
+// dirtyHint explains how to recover when a previous migration left the database dirty.
+func dirtyHint(err error) error {
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return fmt.Errorf("database is dirty at version %d; fix it by hand, then run: go run ./cmd/migrate force <version>", dirty.Version)
+	}
+	return err
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"testing/fstest"
+
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// testMigrations are two SQLite migrations
+var testMigrations = fstest.MapFS{
+	"000001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+	"000001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+	"000002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);")},
+	"000002_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+}
+
+// openTestTarget opens a target over the migrations on db
+func openTestTarget(t *testing.T, db *sql.DB, fsys fstest.MapFS) *target {
+	t.Helper()
+	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := newTarget(fsys, "", "sqlite", "sqlite3", driver, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return target
+}
+
+func TestUpDown(t *testing.T) {
+	// withoutB lacks the file of version 2, once applied
+	withoutB := fstest.MapFS{}
+	for name, file := range testMigrations {
+		if name[:6] == "000001" {
+			withoutB[name] = file
+		}
+	}
+
+	tests := []struct {
+		name string
+		// applied is the number of migrations applied first
+		applied int
+		// fsys are the migrations seen by the command, testMigrations when nil
+		fsys    fstest.MapFS
+		run     func(t *target) error
+		wantErr bool
+	}{
+		{name: "up steps with nothing pending", applied: 2, run: func(t *target) error { return up(t.m, t.source, 1) }},
+		{name: "up steps", run: func(t *target) error { return up(t.m, t.source, 1) }},
+		{name: "up with nothing pending", applied: 2, run: func(t *target) error { return up(t.m, t.source, 0) }},
+		{name: "down with nothing applied", run: func(t *target) error { return down(t.m, 1, false) }},
+		{name: "down all with nothing applied", run: func(t *target) error { return down(t.m, 0, true) }},
+		{name: "down", applied: 2, run: func(t *target) error { return down(t.m, 1, false) }},
+		{
+			name:    "up steps with the applied migration missing",
+			applied: 2,
+			fsys:    withoutB,
+			run:     func(t *target) error { return up(t.m, t.source, 1) },
+			wantErr: true,
+		},
+		{
+			name:    "down with the applied migration missing",
+			applied: 2,
+			fsys:    withoutB,
+			run:     func(t *target) error { return down(t.m, 1, false) },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := sql.Open("sqlite3", "file::memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Every connection to :memory: is a new database
+			db.SetMaxOpenConns(1)
+			defer db.Close()
+
+			if tt.applied > 0 {
+				if err := openTestTarget(t, db, testMigrations).m.Steps(tt.applied); err != nil {
+					t.Fatal(err)
+				}
+			}
+			fsys := tt.fsys
+			if fsys == nil {
+				fsys = testMigrations
+			}
+
+			err = tt.run(openTestTarget(t, db, fsys))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
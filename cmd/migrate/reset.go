@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// destructive reports whether the command drops data
+func destructive(command string) bool {
+	switch command {
+	case "fresh", "refresh", "reset":
+		return true
+	}
+	return false
+}
+
+// rebuild runs fresh, refresh or reset on the target after printing what it drops.
+// It returns the migrator to read the resulting version from.
+func rebuild(open opener, t *target, command string) (*migrate.Migrate, error) {
+	m := t.m
+
+	switch command {
+	case "fresh":
+		ctx := context.Background()
+		tables, err := tableNames(ctx, t.db, t.driver)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to list tables: %w", err)
+		}
+		printSummary(fmt.Sprintf("Dropping %d tables:", len(tables)), tables)
+
+		if err := dropTables(ctx, t, tables); err != nil {
+			return nil, fmt.Errorf("Drop failed: %w", err)
+		}
+
+		// The migrate version table is dropped with the rest; a new migrator recreates it
+		if t, err = open(); err != nil {
+			return nil, err
+		}
+		m = t.m
+
+	case "refresh", "reset":
+		states, _, _, err := migrationStates(m, t.source)
+		if err != nil {
+			return nil, err
+		}
+		var applied []string
+		for i := len(states) - 1; i >= 0; i-- {
+			if states[i].status != "pending" {
+				applied = append(applied, fmt.Sprintf("%d_%s", states[i].version, states[i].name))
+			}
+		}
+		printSummary(fmt.Sprintf("Rolling back %d migrations:", len(applied)), applied)
+
+		if err := down(m, 0, true); err != nil {
+			return nil, err
+		}
+		if command == "reset" {
+			return m, nil
+		}
+	}
+
+	return m, up(m, t.source, 0)
+}
+
+// printSummary prints a heading followed by one line per item
+func printSummary(heading string, items []string) {
+	fmt.Println(heading)
+	for _, item := range items {
+		fmt.Printf("  - %s\n", item)
+	}
+}
+
+// tableNames lists the tables fresh drops: every table of the current schema or database
+func tableNames(ctx context.Context, db sqlConn, driverName string) ([]string, error) {
+	var query string
+	switch driverName {
+	case "postgres":
+		query = `SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name`
+	case "mysql":
+		query = `SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name`
+	case "sqlite":
+		query = `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driverName)
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// dropTables drops the tables listed by tableNames
+// SQLite is dropped here because migrate's driver also tries to drop its internal sqlite_sequence table
+func dropTables(ctx context.Context, t *target, tables []string) error {
+	if t.driver != "sqlite" {
+		return t.m.Drop()
+	}
+	for _, table := range tables {
+		if _, err := t.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, table)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
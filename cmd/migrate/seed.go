@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"skeleton/app/database/seeders"
+
+	"gorm.io/gorm"
+)
+
+// seed runs the seeders named in classes, or all of them, each in its own transaction.
+func seed(ctx context.Context, db *gorm.DB, classes []string) error {
+	selected, err := seeders.LoadAll().Seeders(classes...)
+	if err != nil {
+		return err
+	}
+
+	for _, seeder := range selected {
+		fmt.Printf("Seeding %s\n", seeder.Name())
+		if err := seeders.Run(ctx, db, seeder); err != nil {
+			return fmt.Errorf("Seeder %s failed: %w", seeder.Name(), err)
+		}
+	}
+	fmt.Println("Seeding completed successfully")
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"skeleton/database/migrations"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// dialects are the drivers that may have their own migration directory
+var dialects = []string{"postgres", "mysql", "sqlite"}
+
+// migrationsFS returns the migrations of a connection ("" for the default one), read from
+// path or, when path is empty, from the migrations embedded in the binary.
+// Named connections keep theirs in a directory named after them; it returns nil when there is none.
+func migrationsFS(path, connection string) (fs.FS, error) {
+	var fsys fs.FS = migrations.FS
+	if path != "" {
+		fsys = os.DirFS(path)
+	}
+	if connection == "" {
+		return fsys, nil
+	}
+	if info, err := fs.Stat(fsys, connection); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+	return fs.Sub(fsys, connection)
+}
+
+// newSource reads the migrations for the driver.
+func newSource(fsys fs.FS, driverName string) (source.Driver, error) {
+	return iofs.New(fsys, dialectDir(fsys, driverName))
+}
+
+// dialectDir returns the driver's own migration directory (e.g. sqlite/) when
+// its SQL dialect needs one, and the top-level directory otherwise.
+func dialectDir(fsys fs.FS, driverName string) string {
+	if info, err := fs.Stat(fsys, driverName); err == nil && info.IsDir() {
+		return driverName
+	}
+	return "."
+}
+
+// sqlConn runs statements on a database or a pinned connection
+type sqlConn interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// target is a database with the migrations to run against it
+type target struct {
+	m      *migrate.Migrate
+	source *goSource
+	db     sqlConn
+	driver string
+}
+
+// opener opens a target, again after fresh drops the migrate version table
+type opener func() (*target, error)
+
+// newTarget creates the migrator for a database driver and the SQL and Go migrations of a connection.
+func newTarget(fsys fs.FS, connection, driverName, dbName string, driver database.Driver, db sqlConn) (*target, error) {
+	sqlSource, err := newSource(fsys, driverName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read migrations: %w", err)
+	}
+	src, err := newGoSource(sqlSource, migrations.Registered(connection))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read migrations: %w", err)
+	}
+	driver = &goDriver{Driver: driver, db: db, driver: driverName, migrations: src.migrations}
+	m, err := migrate.NewWithInstance("iofs", src, dbName, driver)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create migrator: %w", err)
+	}
+	return &target{m: m, source: src, db: db, driver: driverName}, nil
+}
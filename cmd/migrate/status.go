@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// migrationState is a migration file and its state in the database
+type migrationState struct {
+	version uint
+	name    string
+	// kind is sql or go
+	kind   string
+	status string
+}
+
+// printStatus lists every migration in the source with its state in the database.
+func printStatus(m *migrate.Migrate, src *goSource) error {
+	states, current, dirty, err := migrationStates(m, src)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tTYPE\tSTATUS")
+
+	counts := map[string]int{}
+	found := false
+	for _, state := range states {
+		counts[state.status]++
+		if state.version == current {
+			found = true
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", state.version, state.name, state.kind, state.status)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d applied, %d dirty, %d pending\n", counts["applied"], counts["dirty"], counts["pending"])
+	if current > 0 && !found {
+		fmt.Printf("WARNING: Current version %d has no migration file!\n", current)
+	}
+	if dirty {
+		fmt.Printf("WARNING: Database is dirty at version %d; fix it by hand, then run: go run ./cmd/migrate force <version>\n", current)
+	}
+	return nil
+}
+
+// migrationStates returns every migration in the source with its state, and the database version.
+// The database only records the current version, so every migration up to it counts as applied.
+func migrationStates(m *migrate.Migrate, src *goSource) ([]migrationState, uint, bool, error) {
+	current, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return nil, 0, false, fmt.Errorf("Failed to get version: %w", err)
+	}
+	hasVersion := err == nil
+
+	var states []migrationState
+	version, err := src.First()
+	for err == nil {
+		status := "pending"
+		switch {
+		case hasVersion && version == current && dirty:
+			status = "dirty"
+		case hasVersion && version <= current:
+			status = "applied"
+		}
+		kind := "sql"
+		if src.isGo(version) {
+			kind = "go"
+		}
+		states = append(states, migrationState{version: version, name: migrationName(src, version), kind: kind, status: status})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, 0, false, fmt.Errorf("Failed to read migrations: %w", err)
+	}
+	return states, current, dirty, nil
+}
+
+// migrationName returns the description of a migration from its up file, or its down file.
+func migrationName(src source.Driver, version uint) string {
+	if r, identifier, err := src.ReadUp(version); err == nil {
+		r.Close()
+		return identifier
+	}
+	if r, identifier, err := src.ReadDown(version); err == nil {
+		r.Close()
+		return identifier
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"skeleton/app/models"
+	"skeleton/app/support/tenancy"
+
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"gorm.io/gorm"
+)
+
+// tenantMigrations is the directory of the migrations applied to tenant schemas, and the
+// connection their Go migrations register with. The shared tables (tenants, api_keys)
+// are not in it, so tenant schemas get only the tables scoped to a tenant.
+const tenantMigrations = "tenant"
+
+// tenantMigrationsFS returns the tenant migrations: the tenant/ directory of -path (path)
+// when set, else tenancy.migrations_path (configured), else the embedded tenant/ directory.
+func tenantMigrationsFS(configured, path string) (fs.FS, error) {
+	if path == "" && configured != "" {
+		return os.DirFS(configured), nil
+	}
+	fsys, err := migrationsFS(path, tenantMigrations)
+	if err != nil {
+		return nil, err
+	}
+	if fsys == nil {
+		return nil, fmt.Errorf("No tenant migrations: create %s/ in the migrations directory or set tenancy.migrations_path", tenantMigrations)
+	}
+	return fsys, nil
+}
+
+// migrateTenants runs the tenant migrations in the schema of one tenant (slug) or of every active tenant.
+// It stops at the first failing tenant so the remaining schemas stay on the previous version.
+// Seeders run with the tenant in their context, so models are created in the tenant schema.
+func migrateTenants(registry *tenancy.Registry, sqlDB *sql.DB, db *gorm.DB, dbName string, fsys fs.FS, slug string, op operation) error {
+	ctx := context.Background()
+	if op.seed {
+		if err := db.Use(tenancy.NewSchemaPlugin(registry.Config().SharedTables)); err != nil {
+			return fmt.Errorf("Failed to register tenant schema plugin: %w", err)
+		}
+	}
+
+	var tenantsToMigrate []*models.Tenant
+	var err error
+	if slug != "" {
+		var t *models.Tenant
+		t, err = registry.FindBySlug(ctx, slug)
+		tenantsToMigrate = []*models.Tenant{t}
+	} else {
+		tenantsToMigrate, err = registry.All(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to load tenants: %w", err)
+	}
+	if len(tenantsToMigrate) == 0 {
+		fmt.Println("No tenants to migrate")
+		return nil
+	}
+
+	for _, t := range tenantsToMigrate {
+		fmt.Printf("== Tenant %s (schema %s)\n", t.Slug, t.Schema)
+		if err := migrateTenant(ctx, t, sqlDB, db, dbName, fsys, op); err != nil {
+			return fmt.Errorf("Tenant %s: %w", t.Slug, err)
+		}
+	}
+	return nil
+}
+
+// migrateTenant runs the tenant migrations, and the seeders when asked, in the schema of one tenant.
+func migrateTenant(ctx context.Context, t *models.Tenant, sqlDB *sql.DB, db *gorm.DB, dbName string, fsys fs.FS, op operation) error {
+	if !tenancy.ValidSchema(t.Schema) {
+		return fmt.Errorf("Invalid schema name %q", t.Schema)
+	}
+
+	if op.command != "seed" {
+		// Pin a connection with the tenant schema on its search path, so unqualified
+		// statements in the migrations create the tenant's tables
+		conn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to open connection: %w", err)
+		}
+		defer conn.Close()
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(`SET search_path TO "%s"`, t.Schema)); err != nil {
+			return fmt.Errorf("Failed to select schema: %w", err)
+		}
+
+		open := func() (*target, error) {
+			driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{SchemaName: t.Schema})
+			if err != nil {
+				return nil, fmt.Errorf("Failed to create database driver: %w", err)
+			}
+			return newTarget(fsys, tenantMigrations, "postgres", dbName, driver, conn)
+		}
+		if err := run(open, op); err != nil {
+			return err
+		}
+	}
+
+	if op.seed {
+		return seed(tenancy.WithTenant(ctx, t), db, op.classes)
+	}
+	return nil
+}
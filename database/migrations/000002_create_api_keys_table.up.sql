@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) UNIQUE NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE api_keys IS 'API keys for machine clients';
+COMMENT ON COLUMN api_keys.prefix IS 'Public key identifier shown in listings';
+COMMENT ON COLUMN api_keys.key_hash IS 'SHA-256 hash of the key secret';
+COMMENT ON COLUMN api_keys.scopes IS 'Space-separated list of granted scopes';
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id SERIAL PRIMARY KEY,
+    slug VARCHAR(63) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    domain VARCHAR(255) UNIQUE NULL,
+    schema VARCHAR(63) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE tenants IS 'Tenant registry; each tenant''s data lives in its own schema';
+COMMENT ON COLUMN tenants.slug IS 'Tenant identifier used in subdomains, the X-Tenant header and JWT claims';
+COMMENT ON COLUMN tenants.domain IS 'Optional custom domain resolving to the tenant';
+COMMENT ON COLUMN tenants.schema IS 'PostgreSQL schema holding the tenant tables';
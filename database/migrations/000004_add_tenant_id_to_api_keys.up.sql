@@ -0,0 +1,3 @@
+ALTER TABLE api_keys ADD COLUMN tenant_id INTEGER NULL REFERENCES tenants(id);
+
+COMMENT ON COLUMN api_keys.tenant_id IS 'Tenant the key is bound to; NULL keys only reach the shared schema';
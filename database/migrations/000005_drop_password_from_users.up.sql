@@ -0,0 +1,3 @@
+-- Neither the User model nor the API sets a password; the NOT NULL column made
+-- every insert fail
+ALTER TABLE users DROP COLUMN password;
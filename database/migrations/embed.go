@@ -0,0 +1,18 @@
+// Package migrations embeds the SQL migrations so the binaries running them
+// do not need the files on disk.
+package migrations
+
+import "embed"
+
+// FS holds the migrations. The top-level files are written for PostgreSQL;
+// drivers with a different SQL dialect have a full set in a subdirectory
+// named after the driver (mysql/, sqlite/). Named connections of
+// db.connections keep their migrations in a subdirectory named after the
+// connection, with the same layout.
+//
+// Only the SQL files are embedded, not the Go sources or the README. A new
+// directory, such as one for a named connection, must be listed below;
+// migrate create adds it when it writes the first migration there.
+//
+//go:embed *.sql mysql/*.sql sqlite/*.sql tenant/*.sql
+var FS embed.FS
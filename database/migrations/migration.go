@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// Func runs one direction of a Go migration inside tx.
+type Func func(ctx context.Context, tx *sql.Tx) error
+
+// Migration is a migration written in Go, for changes plain SQL can't express
+// (backfilling, re-hashing, re-encoding JSON). It shares the version sequence and
+// the version table with the SQL files, and runs in version order between them.
+//
+// Migrations work on *sql.Tx rather than the models: a migration must keep
+// producing the same result after the models have changed.
+type Migration struct {
+	// Version orders the migration among the SQL files; it must match the file name
+	Version uint
+	// Name describes the migration, like the SQL file names
+	Name string
+	// Connection is the db.connections entry the migration belongs to, empty for the default one
+	// and "tenant" for the migrations of tenant schemas
+	Connection string
+	// Up applies the migration
+	Up Func
+	// Down reverts the migration; without it, rolling back only lowers the version
+	Down Func
+}
+
+// registered holds the Go migrations by connection and version
+var registered = map[string]map[uint]Migration{}
+
+// Register adds a Go migration. It is called from the init function of the
+// migration file and panics on a version used twice, like a duplicate SQL file.
+func Register(m Migration) {
+	if m.Version == 0 || m.Up == nil {
+		panic(fmt.Sprintf("migrations: migration %q needs a version and an Up function", m.Name))
+	}
+	if registered[m.Connection] == nil {
+		registered[m.Connection] = map[uint]Migration{}
+	}
+	if _, exists := registered[m.Connection][m.Version]; exists {
+		panic(fmt.Sprintf("migrations: version %d is registered twice", m.Version))
+	}
+	registered[m.Connection][m.Version] = m
+}
+
+// Registered returns the Go migrations of a connection ("" for the default one) by version.
+func Registered(connection string) []Migration {
+	list := make([]Migration, 0, len(registered[connection]))
+	for _, m := range registered[connection] {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+type driverKey struct{}
+
+// WithDriver returns a context carrying the database driver a migration runs on.
+func WithDriver(ctx context.Context, driver string) context.Context {
+	return context.WithValue(ctx, driverKey{}, driver)
+}
+
+// Driver returns the database driver (postgres, mysql, sqlite) a migration runs on,
+// for the statements whose syntax differs, such as placeholders ($1 or ?).
+func Driver(ctx context.Context) string {
+	driver, _ := ctx.Value(driverKey{}).(string)
+	return driver
+}
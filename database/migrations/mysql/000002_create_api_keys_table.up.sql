@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) UNIQUE NOT NULL COMMENT 'Public key identifier shown in listings',
+    key_hash VARCHAR(64) NOT NULL COMMENT 'SHA-256 hash of the key secret',
+    scopes VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Space-separated list of granted scopes',
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) COMMENT = 'API keys for machine clients';
@@ -0,0 +1,11 @@
+-- Tenant schemas are PostgreSQL-only; the registry table keeps versions aligned across drivers
+CREATE TABLE IF NOT EXISTS tenants (
+    id INT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    slug VARCHAR(63) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    domain VARCHAR(255) UNIQUE NULL,
+    `schema` VARCHAR(63) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) COMMENT = 'Tenant registry';
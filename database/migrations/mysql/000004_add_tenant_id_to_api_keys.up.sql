@@ -0,0 +1,3 @@
+ALTER TABLE api_keys
+    ADD COLUMN tenant_id INT UNSIGNED NULL COMMENT 'Tenant the key is bound to; NULL keys only reach the shared schema',
+    ADD CONSTRAINT fk_api_keys_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
@@ -0,0 +1 @@
+ALTER TABLE api_keys ADD COLUMN tenant_id INTEGER NULL REFERENCES tenants(id);